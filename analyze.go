@@ -0,0 +1,130 @@
+package flake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MachineCount is the count of IDs of a machine-id
+type MachineCount struct {
+	MachineId byte `json:"machineId"`
+	Count     int  `json:"count"`
+}
+
+// Analysis describes the distribution of existing IDs over the machine-ids
+// and the time, e.g. to detect a fleet of generators sharing machine-id 0 in
+// production data.
+type Analysis struct {
+	// IDs is the count of analyzed IDs, Invalid the count of IDs Inspect
+	// failed on
+	IDs     int `json:"ids"`
+	Invalid int `json:"invalid"`
+	// Machines are the counts of the machine-ids in descending order
+	Machines []MachineCount `json:"machines"`
+	// MachineShare is the share of the IDs of the most frequent machine-id,
+	// close to 1 for a fleet on a single machine-id
+	MachineShare float64 `json:"machineShare"`
+	// First and Last are the times of the earliest and latest interval,
+	// Coverage the time span from the start of the first to the end of the
+	// last interval.
+	First    time.Time     `json:"first"`
+	Last     time.Time     `json:"last"`
+	Coverage time.Duration `json:"coverage"`
+	// Intervals is the count of intervals with IDs of any machine-id
+	Intervals int `json:"intervals"`
+	// MeanRate is the count of IDs per second over the coverage, PeakRate the
+	// rate of the interval with the most IDs of a single machine-id.
+	MeanRate float64 `json:"meanRate"`
+	PeakRate float64 `json:"peakRate"`
+}
+
+// Analyzer accumulates IDs of a generator for an Analysis.
+type Analyzer struct {
+	flaker    Flaker
+	ids       int
+	invalid   int
+	machines  map[byte]int
+	intervals map[int64]int
+	peaks     map[[2]int64]int // IDs per interval and machine-id
+	peak      int
+	first     int64
+	last      int64
+}
+
+// NewAnalyzer returns an Analyzer decomposing the IDs with the mode, epoch
+// start and layout of the flaker
+func NewAnalyzer(flaker Flaker) *Analyzer {
+	return &Analyzer{
+		flaker:    flaker,
+		machines:  make(map[byte]int),
+		intervals: make(map[int64]int),
+		peaks:     make(map[[2]int64]int),
+	}
+}
+
+// Add adds the ID to the analysis. It returns the error of Inspect for
+// invalid IDs, which are counted as such.
+func (a *Analyzer) Add(f Flake) error {
+	a.ids++
+	info, err := a.flaker.Inspect(f)
+	if err != nil {
+		a.invalid++
+		return err
+	}
+	a.machines[info.MachineId]++
+	a.intervals[info.Interval]++
+	key := [2]int64{info.Interval, int64(info.MachineId)}
+	a.peaks[key]++
+	a.peak = max(a.peak, a.peaks[key])
+	if len(a.intervals) == 1 || info.Interval < a.first {
+		a.first = info.Interval
+	}
+	if len(a.intervals) == 1 || info.Interval > a.last {
+		a.last = info.Interval
+	}
+	return nil
+}
+
+// Analysis returns the analysis of the added IDs
+func (a *Analyzer) Analysis() Analysis {
+	r := Analysis{IDs: a.ids, Invalid: a.invalid, Intervals: len(a.intervals)}
+	for machineId, count := range a.machines {
+		r.Machines = append(r.Machines, MachineCount{MachineId: machineId, Count: count})
+	}
+	sort.Slice(r.Machines, func(i, j int) bool {
+		if r.Machines[i].Count != r.Machines[j].Count {
+			return r.Machines[i].Count > r.Machines[j].Count
+		}
+		return r.Machines[i].MachineId < r.Machines[j].MachineId
+	})
+	if valid := a.ids - a.invalid; valid > 0 {
+		l := a.flaker.Layout()
+		start := a.flaker.EpochStart().UnixNano()
+		r.MachineShare = float64(r.Machines[0].Count) / float64(valid)
+		r.First = time.Unix(0, start+a.first<<l.ResolutionBits)
+		r.Last = time.Unix(0, start+a.last<<l.ResolutionBits)
+		r.Coverage = time.Duration(a.last-a.first+1) * l.IntervalLength()
+		r.MeanRate = float64(valid) / r.Coverage.Seconds()
+		r.PeakRate = float64(a.peak) / l.IntervalLength().Seconds()
+	}
+	return r
+}
+
+// String returns a summary of the analysis
+func (r Analysis) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%d IDs, %d invalid\n", r.IDs, r.Invalid)
+	if r.IDs == r.Invalid {
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+	fmt.Fprintf(b, "time %s to %s, %s in %d intervals with IDs\n",
+		r.First.UTC().Format(time.RFC3339), r.Last.UTC().Format(time.RFC3339), r.Coverage.Round(time.Second), r.Intervals)
+	fmt.Fprintf(b, "rate mean %.3g IDs/s, peak %.3g IDs/s of a machine-id\n", r.MeanRate, r.PeakRate)
+	fmt.Fprintf(b, "%d machine-ids, %.1f%% of the IDs on machine-id %d", len(r.Machines), 100*r.MachineShare, r.Machines[0].MachineId)
+	for _, m := range r.Machines {
+		fmt.Fprintf(b, "\n  machine-id %3d: %d", m.MachineId, m.Count)
+	}
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package flake
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzer(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	a := NewAnalyzer(WithClock(clock))
+	for machineId, count := range map[byte]int{0: 900, 7: 100} {
+		g, _ := New(SetClock(clock), SetMachineId(machineId))
+		for i := 0; i < count; i++ {
+			a.Add(g.Next())
+		}
+	}
+	clock.now = clock.now.Add(time.Minute)
+	g, _ := New(SetClock(clock), SetMachineId(7))
+	a.Add(g.Next())
+	if err := a.Add(Nil); !errors.Is(err, ErrInvalidFlake) {
+		t.Errorf("Expected ErrInvalidFlake of Nil but got %v", err)
+	}
+
+	r := a.Analysis()
+	if r.IDs != 1002 || r.Invalid != 1 || r.Intervals != 2 {
+		t.Errorf("Expected 1002 IDs, 1 invalid in 2 intervals but got %+v", r)
+	}
+	if len(r.Machines) != 2 || r.Machines[0] != (MachineCount{0, 900}) || r.Machines[1] != (MachineCount{7, 101}) {
+		t.Errorf("Unexpected machine-id counts %v", r.Machines)
+	}
+	if r.MachineShare < 0.89 || r.MachineShare > 0.9 {
+		t.Errorf("Expected a machine-id share of 0.9 but got %g", r.MachineShare)
+	}
+	if d := r.Last.Sub(r.First); d < time.Minute-2*time.Second || d > time.Minute+2*time.Second {
+		t.Errorf("Expected about a minute between %s and %s", r.First, r.Last)
+	}
+	if r.Coverage < time.Minute || r.Coverage > time.Minute+2*time.Second {
+		t.Errorf("Expected a coverage of about a minute but got %s", r.Coverage)
+	}
+	if expected := 900 / DefaultLayout.IntervalLength().Seconds(); r.PeakRate != expected {
+		t.Errorf("Expected a peak rate of %g but got %g", expected, r.PeakRate)
+	}
+	if r.MeanRate < 15 || r.MeanRate > 17 {
+		t.Errorf("Expected a mean rate of about 16.7 IDs/s but got %g", r.MeanRate)
+	}
+	if s := r.String(); !strings.Contains(s, "2 machine-ids, 89.9% of the IDs on machine-id 0") {
+		t.Errorf("Unexpected summary %q", s)
+	}
+
+	if r := NewAnalyzer(Default).Analysis(); r.IDs != 0 || r.Machines != nil || r.String() != "0 IDs, 0 invalid" {
+		t.Errorf("Expected an empty analysis but got %+v", r)
+	}
+}
@@ -0,0 +1,114 @@
+package flake
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// AuditReport quantifies how guessable the IDs of a configuration are at a
+// given rate, e.g. for the sign-off of a security review. It assumes an
+// attacker knowing the layout and one ID, which reveals the interval and the
+// machine-id unless the time is hidden, and guessing other IDs of the same
+// interval and machine-id.
+type AuditReport struct {
+	Layout Layout
+	Mode   Mode
+	// Rate is the audited count of IDs per second and machine-id
+	Rate float64
+	// IDsPerInterval is the count of IDs issued within an interval
+	IDsPerInterval float64
+	// Borrowing reports a rate exhausting the sequence, so the generator
+	// issues IDs of future intervals.
+	Borrowing bool
+	// MinRandomBits is the count of random bits of the least protected ID of
+	// an interval, zero once the counter uses all bits of the sequence.
+	MinRandomBits int
+	// MeanRandomBits is the mean count of random bits per ID
+	MeanRandomBits float64
+	// GuessChance is the chance of a single guess of an ID to hit an issued
+	// one. As the counters are sequential the guess of a counter surely
+	// issued in the interval only has to hit its random bits.
+	GuessChance float64
+	// EffectiveBits is the effective entropy per ID, -log2(GuessChance)
+	EffectiveBits float64
+	// TimestampBits is the count of bits revealing the generation time with
+	// the resolution of TimestampResolution, zero in ModeRandom. Anybody can
+	// read them in ModeRaw and in ModeShuffled with the public transposition,
+	// keyed shuffles and the encryption reveal them to the key owner only.
+	TimestampBits       int
+	TimestampResolution time.Duration
+}
+
+// auditPhase is a phase of the sequence of counters with random bits each
+type auditPhase struct {
+	counters, randomBits int
+}
+
+// Audit returns the AuditReport of the layout and mode of the config at the
+// rate of IDs per second and machine-id. The returned errors wrap
+// ErrInvalidConfig.
+func Audit(cfg Config, rate float64) (AuditReport, error) {
+	l := DefaultLayout
+	if cfg.Layout != nil {
+		l = *cfg.Layout
+	}
+	if err := l.Validate(); err != nil {
+		return AuditReport{}, err
+	} else if cfg.Mode < ModeShuffled || cfg.Mode > ModeRandom {
+		return AuditReport{}, fmt.Errorf("%w: %s mode", ErrInvalidConfig, cfg.Mode)
+	} else if !(rate > 0) || math.IsInf(rate, 1) {
+		return AuditReport{}, fmt.Errorf("%w: rate %g", ErrInvalidConfig, rate)
+	}
+
+	r := AuditReport{Layout: l, Mode: cfg.Mode, Rate: rate, IDsPerInterval: rate * l.IntervalLength().Seconds()}
+	if cfg.Mode == ModeRandom {
+		r.MinRandomBits, r.MeanRandomBits = 63, 63
+		r.GuessChance = r.IDsPerInterval / (1 << 63)
+		r.EffectiveBits = -math.Log2(r.GuessChance)
+		return r, nil
+	}
+	r.TimestampBits = l.IntervalBits
+	r.TimestampResolution = l.IntervalLength()
+
+	phases := []auditPhase{{l.SequenceCapacity(), l.RandomBits - l.KindBits}}
+	if l.RandomBits == 0 {
+		small, large := 1<<(l.SequenceBits-18), 1<<(l.SequenceBits-10)
+		phases = []auditPhase{{small, 16}, {large, 8}, {l.SequenceCapacity() - small - large, 0}}
+	}
+	remaining, sum := r.IDsPerInterval, 0.0
+	for _, p := range phases {
+		issued := math.Min(remaining, float64(p.counters))
+		if issued <= 0 {
+			break
+		}
+		r.MinRandomBits = p.randomBits
+		sum += issued * float64(p.randomBits)
+		remaining -= issued
+	}
+	r.Borrowing = remaining > 0
+	r.MeanRandomBits = sum / math.Min(r.IDsPerInterval, float64(l.SequenceCapacity()))
+	r.GuessChance = math.Ldexp(1, -r.MinRandomBits)
+	r.EffectiveBits = float64(r.MinRandomBits)
+	return r, nil
+}
+
+// String returns a summary of the report
+func (r AuditReport) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "layout %d/%d/%d bits (interval/sequence/machine-id) with %d random bits, %s mode\n",
+		r.Layout.IntervalBits, r.Layout.SequenceBits, r.Layout.MachineIdBits, r.Layout.RandomBits, r.Mode)
+	fmt.Fprintf(b, "rate %.6g IDs/s, %.6g IDs per interval", r.Rate, r.IDsPerInterval)
+	if r.Borrowing {
+		b.WriteString(", exhausting the sequence")
+	}
+	fmt.Fprintf(b, "\nrandom bits per ID: min %d, mean %.2f\n", r.MinRandomBits, r.MeanRandomBits)
+	fmt.Fprintf(b, "guess chance %.3g, %.2f effective bits per ID\n", r.GuessChance, r.EffectiveBits)
+	if r.TimestampBits == 0 {
+		b.WriteString("timestamp: none")
+	} else {
+		fmt.Fprintf(b, "timestamp: %d bits of %s resolution, public unless keyed", r.TimestampBits, r.TimestampResolution)
+	}
+	return b.String()
+}
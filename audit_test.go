@@ -0,0 +1,52 @@
+package flake
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	// 10 IDs per interval keep 16 random bits of 32 small counters
+	r, err := Audit(Config{}, 10/IntervalLength.Seconds())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if r.MinRandomBits != 16 || r.MeanRandomBits != 16 || r.Borrowing || r.TimestampBits != 32 {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	if expected := 1.0 / (1 << 16); r.GuessChance != expected || r.EffectiveBits != 16 {
+		t.Errorf("Expected a guess chance of %g but got %g", expected, r.GuessChance)
+	}
+
+	// 32 + 8192 + 1000 IDs per interval reach the counter-only phase
+	r, _ = Audit(Config{}, 9224/IntervalLength.Seconds())
+	if r.MinRandomBits != 0 || r.GuessChance != 1 || r.EffectiveBits != 0 || math.Abs(r.MeanRandomBits-(32*16+8192*8)/9224.0) > 1e-9 {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	if r, _ := Audit(Config{}, 2*Throughput); !r.Borrowing {
+		t.Errorf("Expected the sequence to be exhausted")
+	}
+
+	r, _ = Audit(Config{Layout: &HighEntropyLayout}, 1000)
+	if r.MinRandomBits != 18 || r.MeanRandomBits != 18 || !r.Borrowing || r.EffectiveBits < 18 {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	r, _ = Audit(Config{Mode: ModeRandom}, 1000)
+	if r.MinRandomBits != 63 || r.TimestampBits != 0 || r.EffectiveBits < 50 {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	if s := r.String(); !strings.Contains(s, "random mode") || !strings.Contains(s, "timestamp: none") {
+		t.Errorf("Unexpected summary %q", s)
+	}
+
+	for _, cfg := range []Config{{Mode: Mode(9)}, {Layout: &Layout{}}} {
+		if _, err := Audit(cfg, 1); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for %+v but got %v", cfg, err)
+		}
+	}
+	if _, err := Audit(Config{}, 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for a zero rate but got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Package avroflake maps flakes to Avro values of the flake logical type, a
+// long or a fixed of 8 bytes, in the native Go form of
+// github.com/linkedin/goavro:
+//
+//	{"name": "id", "type": {"type": "long", "logicalType": "flake"}}
+//
+// Readers unaware of the logical type read the underlying long or fixed.
+package avroflake
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"go-flake"
+)
+
+// LogicalType is the name of the Avro logical type of flakes
+const LogicalType = "flake"
+
+// Schemas of the flake logical type
+const (
+	// LongSchema annotates a long, the compact zig-zag varint encoding
+	LongSchema = `{"type":"long","logicalType":"flake"}`
+	// FixedSchema annotates a named fixed of 8 big endian bytes, which sorts
+	// bytewise like the flakes
+	FixedSchema = `{"type":"fixed","name":"Flake","namespace":"goflake","size":8,"logicalType":"flake"}`
+)
+
+// FixedName is the full name of the fixed of FixedSchema, the key of the
+// native value of a union
+const FixedName = "goflake.Flake"
+
+// Long returns the native value of the flake for LongSchema
+func Long(f flake.Flake) int64 {
+	return int64(f)
+}
+
+// Fixed returns the native value of the flake for FixedSchema
+func Fixed(f flake.Flake) []byte {
+	return f.Bytes()
+}
+
+// FromNative returns the flake of a native value decoded with LongSchema or
+// FixedSchema. The value may be wrapped into a union, a null results in
+// flake.Nil.
+func FromNative(value any) (flake.Flake, error) {
+	switch v := value.(type) {
+	case nil:
+		return flake.Nil, nil
+	case int64:
+		return flake.Flake(v), nil
+	case []byte:
+		if len(v) == 8 {
+			return flake.Flake(binary.BigEndian.Uint64(v)), nil
+		}
+	case map[string]any:
+		if len(v) == 1 {
+			for name, value := range v {
+				if name == "long" || name == FixedName {
+					return FromNative(value)
+				}
+			}
+		}
+	}
+	return flake.Nil, fmt.Errorf("avroflake: cannot convert %T to flake", value)
+}
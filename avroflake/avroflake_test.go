@@ -0,0 +1,58 @@
+package avroflake
+
+import (
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"go-flake"
+)
+
+const recordSchema = `{
+	"type": "record",
+	"name": "Event",
+	"fields": [
+		{"name": "id", "type": ` + LongSchema + `},
+		{"name": "key", "type": ` + FixedSchema + `},
+		{"name": "parent", "type": ["null", "long", "goflake.Flake"], "default": null}
+	]
+}`
+
+func TestRoundTrip(t *testing.T) {
+	codec, err := goavro.NewCodec(recordSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, key, parent := flake.Next(), flake.Next(), flake.Next()
+	for _, native := range []any{nil, goavro.Union("long", Long(parent)), goavro.Union(FixedName, Fixed(parent))} {
+		b, err := codec.BinaryFromNative(nil, map[string]any{"id": Long(id), "key": Fixed(key), "parent": native})
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, _, err := codec.NativeFromBinary(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record := decoded.(map[string]any)
+		if f, err := FromNative(record["id"]); err != nil || f != id {
+			t.Errorf("Expected id %d but got %d: %v", id, f, err)
+		}
+		if f, err := FromNative(record["key"]); err != nil || f != key {
+			t.Errorf("Expected key %d but got %d: %v", key, f, err)
+		}
+		expected := parent
+		if native == nil {
+			expected = flake.Nil
+		}
+		if f, err := FromNative(record["parent"]); err != nil || f != expected {
+			t.Errorf("Expected parent %d but got %d: %v", expected, f, err)
+		}
+	}
+}
+
+func TestFromNative(t *testing.T) {
+	for _, invalid := range []any{"abc", int32(1), make([]byte, 7), map[string]any{"string": "abc"}} {
+		if _, err := FromNative(invalid); err == nil {
+			t.Errorf("Expected an error converting %#v", invalid)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+module go-flake/avroflake
+
+go 1.22
+
+require (
+	github.com/linkedin/goavro/v2 v2.12.0
+	go-flake v0.0.0
+)
+
+require github.com/golang/snappy v0.0.1 // indirect
+
+replace go-flake => ../
@@ -0,0 +1,44 @@
+package flake
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinForTime returns the smallest raw ID of any machine-id the flaker's layout
+// and epoch allow in the interval of the time. Together with MaxForTime it
+// bounds time ranges of raw IDs, e.g. for "WHERE id BETWEEN ? AND ?" on
+// tables without a timestamp column. Shuffled IDs have to be stored raw for
+// such queries. It fails with ErrEpochOverflow for times outside of the epoch
+// and with ErrInvalidConfig in ModeRandom.
+func MinForTime(flaker Flaker, t time.Time) (Flake, error) {
+	l := flaker.Layout()
+	interval, err := intervalOf(flaker, &l, t)
+	if err != nil {
+		return 0, err
+	}
+	return Flake(interval << (l.SequenceBits + l.MachineIdBits)), nil
+}
+
+// MaxForTime returns the largest raw ID of any machine-id the flaker's layout
+// and epoch allow in the interval of the time like MinForTime.
+func MaxForTime(flaker Flaker, t time.Time) (Flake, error) {
+	l := flaker.Layout()
+	interval, err := intervalOf(flaker, &l, t)
+	if err != nil {
+		return 0, err
+	}
+	return Flake((interval+1)<<(l.SequenceBits+l.MachineIdBits) - 1), nil
+}
+
+// intervalOf returns the interval of the time within the epoch of the flaker
+func intervalOf(flaker Flaker, l *Layout, t time.Time) (int64, error) {
+	if mode := flaker.Mode(); mode == ModeRandom {
+		return 0, fmt.Errorf("%w: no time in %s mode", ErrInvalidConfig, mode)
+	}
+	elapsed := t.UnixNano() - flaker.EpochStart().UnixNano()
+	if elapsed < 0 || elapsed>>l.ResolutionBits >= 1<<l.IntervalBits {
+		return 0, ErrEpochOverflow
+	}
+	return elapsed >> l.ResolutionBits, nil
+}
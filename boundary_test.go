@@ -0,0 +1,49 @@
+package flake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMinMaxForTime(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f, err := New(SetClock(clock), SetMode(ModeRaw), SetMachineId(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := f.NextN(1000)
+	min, err := MinForTime(f, clock.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	max, err := MaxForTime(f, clock.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		if id < min || id > max {
+			t.Fatalf("Expected %d between %d and %d", id, min, max)
+		}
+	}
+	first, _ := f.Inspect(ids[0])
+	if info, _ := f.Inspect(min); info.Sequence != 0 || info.MachineId != 0 || info.Interval != first.Interval {
+		t.Errorf("Expected the first ID of interval %d but got %+v", first.Interval, info)
+	}
+	if next, _ := MinForTime(f, clock.now.Add(DefaultLayout.IntervalLength())); next != max+1 {
+		t.Errorf("Expected the next interval to start at %d but got %d", max+1, next)
+	}
+	if prev, _ := MaxForTime(f, clock.now.Add(-DefaultLayout.IntervalLength())); prev != min-1 {
+		t.Errorf("Expected the previous interval to end at %d but got %d", min-1, prev)
+	}
+
+	if _, err := MinForTime(f, time.Unix(0, 0)); !errors.Is(err, ErrEpochOverflow) {
+		t.Errorf("Expected an epoch overflow before the epoch but got %v", err)
+	}
+	if _, err := MaxForTime(WithMode(ModeRandom), clock.now); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected no time in random mode but got %v", err)
+	}
+	if min, err := MinForTime(Default, time.Now()); err != nil || min > Raw.Next() {
+		t.Errorf("Expected the minimum of now before a new raw ID but got %d, %v", min, err)
+	}
+}
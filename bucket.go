@@ -0,0 +1,50 @@
+package flake
+
+import "time"
+
+// Layouts of time buckets for BucketKey in UTC
+const (
+	// DayBucket is the key of a day, e.g. 2024-01-31
+	DayBucket = "2006-01-02"
+	// MonthBucket is the key of a month, e.g. 2024-01
+	MonthBucket = "2006-01"
+	// HivePathBucket is a Hive-style partition path of an hour, e.g.
+	// dt=2024-01-31/hour=13
+	HivePathBucket = "dt=2006-01-02/hour=15"
+)
+
+// BucketKey returns the creation time of the ID of the flaker formatted in UTC
+// with the time layout, e.g. DayBucket, to derive partition keys of time
+// partitioned tables from IDs. The time is decoded according to the epoch,
+// the layout and the mode of the flaker. It's the start of the interval of
+// the ID, so IDs of an interval spanning a bucket boundary fall into the
+// earlier bucket.
+func BucketKey(flaker Flaker, f Flake, layout string) (string, error) {
+	info, err := flaker.Inspect(f)
+	if err != nil {
+		return "", err
+	}
+	return info.Time.UTC().Format(layout), nil
+}
+
+// DayKey returns the day of the creation time of the ID in UTC, e.g.
+// 2024-01-31
+func DayKey(flaker Flaker, f Flake) (string, error) {
+	return BucketKey(flaker, f, DayBucket)
+}
+
+// MonthKey returns the month of the creation time of the ID in UTC, e.g.
+// 2024-01
+func MonthKey(flaker Flaker, f Flake) (string, error) {
+	return BucketKey(flaker, f, MonthBucket)
+}
+
+// UnixHour returns the hours since 1/1/1970 UTC of the creation time of the
+// ID, e.g. for ClickHouse partitions by toStartOfHour
+func UnixHour(flaker Flaker, f Flake) (int64, error) {
+	info, err := flaker.Inspect(f)
+	if err != nil {
+		return 0, err
+	}
+	return info.Time.Unix() / int64(time.Hour/time.Second), nil
+}
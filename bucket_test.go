@@ -0,0 +1,36 @@
+package flake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketKey(t *testing.T) {
+	now := time.Date(2024, 1, 31, 13, 30, 0, 0, time.UTC)
+	for _, mode := range []Mode{ModeShuffled, ModeRaw} {
+		f, err := New(SetClock(&manualClock{now: now}), SetMode(mode), SetMachineId(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := f.Next()
+		if day, err := DayKey(f, id); err != nil || day != "2024-01-31" {
+			t.Errorf("Expected the day of %s ID %d but got %q, %v", mode, id, day, err)
+		}
+		if month, err := MonthKey(f, id); err != nil || month != "2024-01" {
+			t.Errorf("Expected the month of %s ID %d but got %q, %v", mode, id, month, err)
+		}
+		if path, err := BucketKey(f, id, HivePathBucket); err != nil || path != "dt=2024-01-31/hour=13" {
+			t.Errorf("Expected the Hive path of %s ID %d but got %q, %v", mode, id, path, err)
+		}
+		if hour, err := UnixHour(f, id); err != nil || hour != now.Unix()/3600 {
+			t.Errorf("Expected hour %d of %s ID %d but got %d, %v", now.Unix()/3600, mode, id, hour, err)
+		}
+		// Another epoch decodes another time
+		if day, _ := DayKey(f.WithEpochStart(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)), id); day == "2024-01-31" {
+			t.Errorf("Expected another day of another epoch")
+		}
+	}
+	if _, err := DayKey(Default, Nil); err == nil {
+		t.Errorf("Expected no day of Nil")
+	}
+}
@@ -0,0 +1,84 @@
+// Package cborflake encodes flakes as tagged CBOR integers with
+// github.com/fxamacker/cbor.
+//
+//	enc, _ := cborflake.EncMode()
+//	b, _ := enc.Marshal(user)
+package cborflake
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"go-flake"
+)
+
+// Tag is the CBOR tag number of flakes, taken from the first come first served
+// range of the IANA registry. The tag encodes in 3 bytes.
+const Tag uint64 = 61870
+
+// TagSet returns the tag of flake.Flake. The tag is written on
+// encoding and optional on decoding, so untagged integers decode as well.
+func TagSet() cbor.TagSet {
+	tags := cbor.NewTagSet()
+	opts := cbor.TagOptions{EncTag: cbor.EncTagRequired, DecTag: cbor.DecTagOptional}
+	if err := tags.Add(opts, reflect.TypeOf(flake.Nil), Tag); err != nil {
+		panic(err)
+	}
+	return tags
+}
+
+// EncMode returns the encoding mode of the options tagging flakes
+func EncMode(opts ...cbor.EncOptions) (cbor.EncMode, error) {
+	var o cbor.EncOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return o.EncModeWithTags(TagSet())
+}
+
+// DecMode returns the decoding mode of the options accepting tagged flakes
+func DecMode(opts ...cbor.DecOptions) (cbor.DecMode, error) {
+	var o cbor.DecOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return o.DecModeWithTags(TagSet())
+}
+
+// decMode decodes the content of String
+var decMode, _ = DecMode()
+
+// String is a flake encoded as CBOR text string in the base64 format for peers
+// unaware of the tag. It decodes text strings of any format detected by
+// flake.Decode as well as tagged and untagged integers.
+type String flake.Flake
+
+// Flake returns the String as Flake
+func (s String) Flake() flake.Flake {
+	return flake.Flake(s)
+}
+
+// MarshalCBOR encodes the flake as text string
+func (s String) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(flake.Flake(s).Base64())
+}
+
+// UnmarshalCBOR decodes a text string or an integer
+func (s *String) UnmarshalCBOR(b []byte) error {
+	var text string
+	if err := cbor.Unmarshal(b, &text); err == nil {
+		f, err := flake.Decode(text)
+		if err != nil {
+			return err
+		}
+		*s = String(f)
+		return nil
+	}
+	var f flake.Flake
+	if err := decMode.Unmarshal(b, &f); err != nil {
+		return fmt.Errorf("cborflake: cannot decode String: %w", err)
+	}
+	*s = String(f)
+	return nil
+}
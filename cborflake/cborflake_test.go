@@ -0,0 +1,73 @@
+package cborflake
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"go-flake"
+)
+
+func TestTag(t *testing.T) {
+	enc, err := EncMode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := DecMode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := enc.Marshal(flake.Flake(1))
+	if expected := []byte{0xd9, 0xf1, 0xae, 0x01}; err != nil || !bytes.Equal(b, expected) {
+		t.Errorf("Expected %x but got %x: %v", expected, b, err)
+	}
+
+	type record struct {
+		ID   flake.Flake
+		Refs []flake.Flake
+	}
+	r := record{ID: flake.Next(), Refs: []flake.Flake{flake.Next()}}
+	if b, err = enc.Marshal(r); err != nil {
+		t.Fatal(err)
+	}
+	var decoded record
+	if err := dec.Unmarshal(b, &decoded); err != nil || decoded.ID != r.ID || decoded.Refs[0] != r.Refs[0] {
+		t.Errorf("Expected %v but got %v: %v", r, decoded, err)
+	}
+	var f flake.Flake
+	if err := dec.Unmarshal([]byte{0x01}, &f); err != nil || f != 1 {
+		t.Errorf("Expected an untagged integer to decode but got %d: %v", f, err)
+	}
+	if err := dec.Unmarshal([]byte{0xd9, 0xf1, 0xaf, 0x01}, &f); err == nil {
+		t.Errorf("Expected an error of another tag")
+	}
+}
+
+func TestString(t *testing.T) {
+	f := flake.Next()
+	b, err := cbor.Marshal(String(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text string
+	if err := cbor.Unmarshal(b, &text); err != nil || text != f.Base64() {
+		t.Errorf("Expected text %s but got %s: %v", f.Base64(), text, err)
+	}
+
+	enc, _ := EncMode()
+	tagged, _ := enc.Marshal(f)
+	hex, _ := cbor.Marshal(f.Hex())
+	for _, b := range [][]byte{b, tagged, hex} {
+		var s String
+		if err := cbor.Unmarshal(b, &s); err != nil || s.Flake() != f {
+			t.Errorf("Expected %d of %x but got %d: %v", f, b, s, err)
+		}
+	}
+	invalid, _ := cbor.Marshal("invalid")
+	for _, b := range [][]byte{invalid, {0xf5}} {
+		var s String
+		if err := cbor.Unmarshal(b, &s); err == nil {
+			t.Errorf("Expected an error decoding %x", b)
+		}
+	}
+}
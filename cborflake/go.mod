@@ -0,0 +1,12 @@
+module go-flake/cborflake
+
+go 1.22
+
+require (
+	github.com/fxamacker/cbor/v2 v2.9.1
+	go-flake v0.0.0
+)
+
+require github.com/x448/float16 v0.8.4 // indirect
+
+replace go-flake => ../
@@ -0,0 +1,97 @@
+package flake
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Clock is the source of time of a Flaker.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock reading the system time with monotonic readings.
+var SystemClock = Clock(systemClock{})
+
+type systemClock struct{}
+
+// Now returns time.Now()
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ClockPolicy defines how a Flaker reacts when the clock moves backwards
+// behind the last issued interval.
+type ClockPolicy int
+
+const (
+	// ClockBorrow continues the sequence of the last issued interval until
+	// the clock catches up again. This is the default policy.
+	ClockBorrow ClockPolicy = iota
+	// ClockWait blocks until the clock catches up with the last issued
+	// interval.
+	ClockWait
+	// ClockError lets NextErr() fail with ErrClockRegression. Next() falls
+	// back to ClockBorrow since it never fails.
+	ClockError
+)
+
+// ErrClockRegression is returned by NextErr() with the ClockError policy when
+// the clock moved backwards behind the last issued interval.
+var ErrClockRegression = errors.New("clock moved backwards")
+
+// ErrClockSkew is returned when the clock is skewed more than tolerated
+// against a ClockReference.
+var ErrClockSkew = errors.New("clock skew exceeds limit")
+
+// ClockReference returns the skew of the specified clock compared to a
+// trusted time source. A positive skew means the clock is ahead.
+type ClockReference func(clock Clock) (skew time.Duration, err error)
+
+// TimeReference returns a ClockReference comparing against the time returned
+// by now, e.g. the time of a database server.
+func TimeReference(now func() (time.Time, error)) ClockReference {
+	return func(clock Clock) (time.Duration, error) {
+		reference, err := now()
+		if err != nil {
+			return 0, err
+		}
+		return clock.Now().Sub(reference), nil
+	}
+}
+
+// String returns the name of the policy
+func (p ClockPolicy) String() string {
+	switch p {
+	case ClockBorrow:
+		return "borrow"
+	case ClockWait:
+		return "wait"
+	case ClockError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the name of the clock policy
+func (p ClockPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText sets the clock policy of the given name
+func (p *ClockPolicy) UnmarshalText(text []byte) error {
+	return unmarshalName(p, text, ClockError, "clock policy")
+}
+
+// sleep waits for the duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
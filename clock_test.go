@@ -0,0 +1,143 @@
+package flake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// regress moves the last issued interval of f ahead of the clock, which the
+// generator sees as a clock regression. The clock will catch up after the
+// given duration.
+func regress(f Flaker, catchUp time.Duration) *flaker {
+	g := f.(*flaker)
+	n := int64(catchUp>>ignoredTimeBits) + 1
+	g.epochStart = time.Now().UnixNano() - n<<ignoredTimeBits + int64(catchUp)
+	g.state = pack(n, 0)
+	return g
+}
+
+func TestClockBorrow(t *testing.T) {
+	var lags []time.Duration
+	g := regress(WithClockPolicy(ClockBorrow, func(lag time.Duration) {
+		lags = append(lags, lag)
+	}), time.Minute)
+	m := make(map[Flake]int)
+	generate(t, g, m, 1000)
+	if len(lags) != 1000 {
+		t.Errorf("Expected 1000 regression callbacks but got %d", len(lags))
+	}
+	if len(lags) > 0 && (lags[0] <= 0 || lags[0] > time.Minute) {
+		t.Errorf("Unexpected regression lag %s", lags[0])
+	}
+	if _, err := g.NextErr(); err != nil {
+		t.Errorf("Expected no error with borrow policy but got %v", err)
+	}
+}
+
+func TestClockError(t *testing.T) {
+	g := regress(WithClockPolicy(ClockError, nil), time.Minute)
+	if _, err := g.NextErr(); err != ErrClockRegression {
+		t.Errorf("Expected ErrClockRegression but got %v", err)
+	}
+	if id := g.Next(); id == 0 {
+		t.Errorf("Expected Next() to fall back to borrowing")
+	}
+}
+
+func TestClockWait(t *testing.T) {
+	g := regress(WithClockPolicy(ClockWait, nil), 50*time.Millisecond)
+	start := time.Now()
+	if _, err := g.NextErr(); err != nil {
+		t.Errorf("Expected no error with wait policy but got %v", err)
+	}
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Errorf("Expected to wait for the clock but returned after %s", waited)
+	}
+}
+
+func TestMonotonicNow(t *testing.T) {
+	g := Default.(*flaker)
+	prev := g.now()
+	for i := 0; i < 1000; i++ {
+		now := g.now()
+		if now < prev {
+			t.Errorf("Time moved backwards from %d to %d", prev, now)
+			return
+		}
+		prev = now
+	}
+	if skew := time.Duration(time.Now().UnixNano() - g.now()); skew > time.Second || skew < -time.Second {
+		t.Errorf("Unexpected skew of %s to the wall clock", skew)
+	}
+}
+
+// manualClock is a Clock which only moves when told to.
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func TestWithClock(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f := WithClock(clock).WithClockPolicy(ClockError, nil)
+	g := f.(*flaker)
+
+	id, err := f.NextErr()
+	if err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+	raw, _ := g.next(context.Background(), false, 0)
+	expected := (clock.now.UnixNano() - g.epochStart) >> ignoredTimeBits
+	if interval := raw >> (sequenceBits + machineIdBits); interval != expected {
+		t.Errorf("Expected interval %d of the clock but got %d", expected, interval)
+	}
+
+	clock.now = clock.now.Add(-time.Hour)
+	if _, err := f.NextErr(); err != ErrClockRegression {
+		t.Errorf("Expected ErrClockRegression after turning back the clock but got %v", err)
+	}
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	if next, err := f.NextErr(); err != nil || next == id {
+		t.Errorf("Expected a new ID after the clock caught up but got %d: %v", next, err)
+	}
+}
+
+func TestWithClockCheck(t *testing.T) {
+	reference := TimeReference(func() (time.Time, error) {
+		return time.Now().Add(time.Hour), nil
+	})
+	if _, err := WithClockCheck(reference, time.Minute, nil); !errors.Is(err, ErrClockSkew) {
+		t.Errorf("Expected ErrClockSkew but got %v", err)
+	}
+	var skew time.Duration
+	if _, err := WithClockCheck(reference, time.Minute, func(s time.Duration) { skew = s }); err != nil {
+		t.Errorf("Expected no error with a skew callback but got %v", err)
+	}
+	if skew > -59*time.Minute {
+		t.Errorf("Expected a skew of about -1h but got %s", skew)
+	}
+	if _, err := WithClockCheck(reference, 2*time.Hour, nil); err != nil {
+		t.Errorf("Expected a tolerated skew but got %v", err)
+	}
+}
+
+func TestClockWaitContext(t *testing.T) {
+	g := regress(WithClockPolicy(ClockWait, nil), time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := g.NextContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded while waiting for the clock but got %v", err)
+	}
+	if _, err := NextContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded for a done context but got %v", err)
+	}
+	if _, err := NextContext(context.Background()); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go-flake"
+)
+
+// cmdAnalyze reports the distribution of the IDs of files or stdin over the
+// machine-ids and the time
+func cmdAnalyze(args []string, s *stdio) error {
+	fs := newFlagSet("analyze", s)
+	asJSON := fs.Bool("json", false, "print the analysis as JSON, same as -output json")
+	out := outputFlag(fs)
+	var g generator
+	g.flags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: flake analyze [flags] [file...]\n\nReads newline-delimited IDs of any encoding from stdin without file arguments.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	flaker, err := g.flaker()
+	if err != nil {
+		return err
+	}
+
+	analyzer := flake.NewAnalyzer(flaker)
+	unparsable := 0
+	add := func(r io.Reader) error {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			input := strings.TrimSpace(scanner.Text())
+			if input == "" {
+				continue
+			}
+			if f, err := parseID(input); err != nil {
+				unparsable++
+			} else {
+				analyzer.Add(f)
+			}
+		}
+		return scanner.Err()
+	}
+	if fs.NArg() == 0 {
+		if err := add(s.in); err != nil {
+			return err
+		}
+	}
+	for _, name := range fs.Args() {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = add(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	report := analyzer.Analysis()
+	report.IDs += unparsable
+	report.Invalid += unparsable
+	if *asJSON {
+		*out = outputJSON
+	}
+	return writeReport(s.out, *out, report, func() error {
+		_, err := fmt.Fprintln(s.out, report)
+		return err
+	})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-flake"
+)
+
+func TestAnalyze(t *testing.T) {
+	var ids []string
+	for _, g := range []flake.Flaker{flake.WithMachineId(0), flake.WithMachineId(0), flake.WithMachineId(3)} {
+		for i := 0; i < 10; i++ {
+			ids = append(ids, g.Next().Encode(flake.FormatBase64))
+		}
+	}
+	input := strings.Join(append(ids, "not an id"), "\n")
+	stdout, stderr, code := runArgs(input, "analyze")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 but got %d: %s", code, stderr)
+	}
+	for _, expected := range []string{"31 IDs, 1 invalid", "2 machine-ids, 66.7% of the IDs on machine-id 0", "machine-id   3: 10"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Expected %q in the analysis %q", expected, stdout)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "ids.txt")
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code = runArgs("", "analyze", "-json", path)
+	var report flake.Analysis
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || code != 0 {
+		t.Fatalf("Expected a JSON analysis but got %q: %v %s", stdout, err, stderr)
+	}
+	if report.IDs != 31 || report.Invalid != 1 || len(report.Machines) != 2 {
+		t.Errorf("Unexpected analysis %+v", report)
+	}
+	if _, _, code := runArgs("", "analyze", filepath.Join(t.TempDir(), "missing")); code != 1 {
+		t.Errorf("Expected exit code 1 of a missing file but got %d", code)
+	}
+}
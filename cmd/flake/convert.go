@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-flake"
+)
+
+// cmdConvert adds the time, machine-id or encodings of an ID column to the
+// records of a CSV or JSONL file
+func cmdConvert(args []string, s *stdio) error {
+	fs := newFlagSet("convert", s)
+	column := fs.String("column", "id", "name of the ID column")
+	input := fs.String("input", "", "input format: csv or jsonl, by the file extension or csv by default")
+	add := fs.String("add", "time,machine", "comma separated added columns: time, machine, sequence, interval or an encoding")
+	out := outputFlag(fs)
+	var g generator
+	g.flags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: flake convert [flags] [file]\n\nReads the records from stdin without file argument. The added columns are named\nafter the ID column, e.g. id_time and id_machine.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("expected a single file but got %d", fs.NArg())
+	}
+	fields, err := parseFields(*add)
+	if err != nil {
+		return err
+	}
+	flaker, err := g.flaker()
+	if err != nil {
+		return err
+	}
+
+	r := s.in
+	if fs.NArg() == 1 {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+		if *input == "" && strings.EqualFold(filepath.Ext(fs.Arg(0)), ".jsonl") {
+			*input = "jsonl"
+		}
+	}
+	c := &converter{flaker: flaker, column: *column, fields: fields, output: *out, err: s.err}
+	switch *input {
+	case "", "csv":
+		err = c.csv(r, s.out)
+	case "jsonl":
+		if out.tabular() {
+			return fmt.Errorf("no %s output of JSONL records", *out)
+		}
+		err = c.jsonl(r, s.out)
+	default:
+		return fmt.Errorf("unknown input format %q", *input)
+	}
+	if err == nil && c.failed > 0 {
+		err = fmt.Errorf("%d invalid IDs", c.failed)
+	}
+	return err
+}
+
+// parseFields parses the comma separated added columns
+func parseFields(s string) ([]string, error) {
+	fields := strings.Split(s, ",")
+	for _, field := range fields {
+		switch field {
+		case "time", "machine", "sequence", "interval":
+		default:
+			var format flake.Format
+			if format.UnmarshalText([]byte(field)) != nil || format == flake.FormatBytes {
+				return nil, fmt.Errorf("unknown column %q", field)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// converter adds the fields of the IDs of a column to records
+type converter struct {
+	flaker flake.Flaker
+	column string
+	fields []string
+	output output // text keeps the input format
+	err    io.Writer
+	failed int
+}
+
+// values returns the added values of the ID or nil for an invalid ID, which is
+// reported
+func (c *converter) values(line int, id string) []string {
+	record, err := inspectID(c.flaker, id)
+	if err != nil {
+		fmt.Fprintf(c.err, "line %d: %q: %v\n", line, id, err)
+		c.failed++
+		return nil
+	}
+	values := make([]string, len(c.fields))
+	for i, field := range c.fields {
+		switch field {
+		case "time", "machine", "sequence", "interval":
+			if record.Time.IsZero() {
+				continue // random mode
+			}
+		}
+		switch field {
+		case "time":
+			values[i] = record.Time.UTC().Format(time.RFC3339Nano)
+		case "machine":
+			values[i] = strconv.Itoa(int(record.MachineId))
+		case "sequence":
+			values[i] = strconv.FormatInt(record.Sequence, 10)
+		case "interval":
+			values[i] = strconv.FormatInt(record.Interval, 10)
+		default:
+			values[i] = record.Encodings[field]
+		}
+	}
+	return values
+}
+
+// csv converts CSV records with a header row to CSV, JSON lines of the
+// header fields or tab separated values
+func (c *converter) csv(r io.Reader, w io.Writer) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	writer := csv.NewWriter(w)
+	if c.output.tabular() {
+		writer.Comma = '\t'
+	}
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("reading the header: %w", err)
+	}
+	index := -1
+	for i, name := range header {
+		if name == c.column {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("no column %q in the header", c.column)
+	}
+	for _, field := range c.fields {
+		header = append(header, c.column+"_"+field)
+	}
+	if c.output != outputJSON && c.output != outputRaw {
+		writer.Write(header)
+	}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		var values []string
+		if index < len(record) && record[index] != "" {
+			values = c.values(line, record[index])
+		}
+		if values == nil {
+			values = make([]string, len(c.fields))
+		}
+		record = append(record, values...)
+		if c.output == outputJSON {
+			writer.Flush()
+			if err := writeObject(w, header, record); err != nil {
+				return err
+			}
+		} else {
+			writer.Write(record)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeObject writes a JSON line of the values named by the header in the
+// order of the header. Missing values are null.
+func writeObject(w io.Writer, header, values []string) error {
+	b := []byte{'{'}
+	for i, name := range header {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		key, _ := json.Marshal(name)
+		b = append(append(b, key...), ':')
+		if i < len(values) {
+			value, _ := json.Marshal(values[i])
+			b = append(b, value...)
+		} else {
+			b = append(b, "null"...)
+		}
+	}
+	_, err := w.Write(append(b, '}', '\n'))
+	return err
+}
+
+// jsonl converts JSON objects, one per line. The added fields are appended to
+// the objects, which are kept as they are otherwise.
+func (c *converter) jsonl(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	out := bufio.NewWriter(w)
+	for line := 1; scanner.Scan(); line++ {
+		object := bytes.TrimSpace(scanner.Bytes())
+		if len(object) == 0 {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(object, &fields); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		var values []string
+		if raw := fields[c.column]; len(raw) > 0 && string(raw) != "null" {
+			id := string(raw)
+			if raw[0] == '"' {
+				json.Unmarshal(raw, &id)
+			}
+			values = c.values(line, id)
+		}
+		out.Write(object[:len(object)-1])
+		for i, value := range values {
+			if len(fields) > 0 || i > 0 {
+				out.WriteByte(',')
+			}
+			name, _ := json.Marshal(c.column + "_" + c.fields[i])
+			out.Write(name)
+			out.WriteByte(':')
+			if value == "" {
+				out.WriteString("null")
+			} else if field := c.fields[i]; field == "machine" || field == "sequence" || field == "interval" {
+				out.WriteString(value)
+			} else {
+				text, _ := json.Marshal(value)
+				out.Write(text)
+			}
+		}
+		out.WriteString("}\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
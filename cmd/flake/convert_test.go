@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-flake"
+)
+
+func TestConvertCSV(t *testing.T) {
+	g := flake.WithMachineId(9)
+	id := g.Next()
+	info, _ := g.Inspect(id)
+	input := "name,id\nfoo," + id.Encode(flake.FormatDecimal) + "\nbar,invalid\nbaz,\n"
+	stdout, stderr, code := runArgs(input, "convert", "-machine", "9", "-add", "time,machine,base64")
+	if code != 1 || !strings.Contains(stderr, `line 3: "invalid"`) || !strings.Contains(stderr, "1 invalid IDs") {
+		t.Errorf("Expected exit code 1 of the invalid ID but got %d: %s", code, stderr)
+	}
+	expected := "name,id,id_time,id_machine,id_base64\n" +
+		"foo," + id.Encode(flake.FormatDecimal) + "," + info.Time.UTC().Format(time.RFC3339Nano) + ",9," + id.Encode(flake.FormatBase64) + "\n" +
+		"bar,invalid,,,\nbaz,,,,\n"
+	if stdout != expected {
+		t.Errorf("Expected %q but got %q", expected, stdout)
+	}
+
+	if _, stderr, code := runArgs(input, "convert", "-column", "key"); code != 1 || !strings.Contains(stderr, `no column "key"`) {
+		t.Errorf("Expected a missing column but got %d: %s", code, stderr)
+	}
+	if _, stderr, code := runArgs(input, "convert", "-add", "bytes"); code != 1 || !strings.Contains(stderr, `unknown column "bytes"`) {
+		t.Errorf("Expected an unknown column but got %d: %s", code, stderr)
+	}
+}
+
+func TestConvertJSONL(t *testing.T) {
+	g := flake.RawWithMachineId(3)
+	id := g.Next()
+	input := `{"id":` + id.Encode(flake.FormatDecimal) + `,"n":1}` + "\n" + `{"id":"` + id.Encode(flake.FormatHex) + `"}` + "\n{}\n"
+	path := filepath.Join(t.TempDir(), "export.jsonl")
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code := runArgs("", "convert", "-mode", "raw", "-add", "machine,sequence,decimal", path)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 but got %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], `{"id":`+id.Encode(flake.FormatDecimal)+`,"n":1,"id_machine":3,`) || lines[2] != "{}" {
+		t.Fatalf("Unexpected output %q", stdout)
+	}
+	var record struct {
+		Machine *int   `json:"id_machine"`
+		Decimal string `json:"id_decimal"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil || record.Machine == nil || *record.Machine != 3 || record.Decimal != id.Encode(flake.FormatDecimal) {
+		t.Errorf("Unexpected record %q: %v", lines[1], err)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// cmdGen emits typed ID wrappers of the entities for go:generate
+func cmdGen(args []string, s *stdio) error {
+	fs := newFlagSet("gen", s)
+	pkg := fs.String("package", os.Getenv("GOPACKAGE"), "package of the generated file, $GOPACKAGE by default")
+	output := fs.String("o", "", "output file, stdout by default")
+	flaker := fs.String("flaker", "flake.Default", "generator of the constructors, e.g. a package variable")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: flake gen [flags] entity[=prefix]...\n\nEmits an <entity>ID type per entity encoded as prefix, underscore and base58 flake,\ne.g. usr_2CvBM5Hs1Aa. The prefix is the lower case entity by default.\n\n\t//go:generate flake gen -o ids_gen.go -flaker IDs User=usr Order=ord")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("no entities")
+	} else if !token.IsIdentifier(*pkg) {
+		return fmt.Errorf("invalid package %q", *pkg)
+	}
+	entities, err := parseEntities(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	src, err := genIDs(*pkg, *flaker, entities)
+	if err != nil {
+		return err
+	} else if *output == "" {
+		_, err = s.out.Write(src)
+		return err
+	}
+	return os.WriteFile(*output, src, 0o644)
+}
+
+// entity is a typed ID of the generated file
+type entity struct {
+	Name   string
+	Prefix string
+}
+
+// parseEntities parses the entity[=prefix] arguments
+func parseEntities(args []string) ([]entity, error) {
+	entities := make([]entity, 0, len(args))
+	seen := make(map[string]string)
+	for _, arg := range args {
+		name, prefix, ok := strings.Cut(arg, "=")
+		if !ok {
+			prefix = strings.ToLower(name)
+		}
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return nil, fmt.Errorf("invalid entity %q", name)
+		} else if prefix == "" || strings.IndexFunc(prefix, func(r rune) bool {
+			return !unicode.IsLower(r) && !unicode.IsDigit(r) || r > unicode.MaxASCII
+		}) >= 0 {
+			return nil, fmt.Errorf("invalid prefix %q of %s, expected lower case letters and digits", prefix, name)
+		} else if other, ok := seen[prefix]; ok {
+			return nil, fmt.Errorf("prefix %q of both %s and %s", prefix, other, name)
+		}
+		seen[prefix] = name
+		entities = append(entities, entity{Name: name, Prefix: prefix + "_"})
+	}
+	return entities, nil
+}
+
+// genIDs returns the formatted source of the typed IDs
+func genIDs(pkg, flaker string, entities []entity) ([]byte, error) {
+	var buf bytes.Buffer
+	err := genTemplate.Execute(&buf, struct {
+		Package  string
+		Flaker   string
+		Entities []entity
+	}{pkg, flaker, entities})
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid flaker %q: %w", flaker, err)
+	}
+	return src, nil
+}
+
+var genTemplate = template.Must(template.New("gen").Parse(`// Code generated by flake gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"go-flake"
+)
+{{range .Entities}}
+// {{.Name}}ID identifies a {{.Name}}. It's text and JSON encoded as {{printf "%q" .Prefix}}
+// followed by the base58 flake and stored as 64 bit integer column.
+type {{.Name}}ID flake.Flake
+
+// {{.Name}}IDPrefix prefixes the encoded {{.Name}}IDs
+const {{.Name}}IDPrefix = {{printf "%q" .Prefix}}
+
+// New{{.Name}}ID returns a new {{.Name}}ID of {{$.Flaker}}
+func New{{.Name}}ID() {{.Name}}ID {
+	return {{.Name}}ID({{$.Flaker}}.Next())
+}
+
+// Parse{{.Name}}ID parses a {{.Name}}ID from its String representation
+func Parse{{.Name}}ID(s string) ({{.Name}}ID, error) {
+	if !strings.HasPrefix(s, {{.Name}}IDPrefix) {
+		return 0, fmt.Errorf("parsing {{.Name}}ID %q: expected prefix %q", s, {{.Name}}IDPrefix)
+	}
+	f, err := flake.DecodeBase58(s[len({{.Name}}IDPrefix):])
+	if err != nil {
+		return 0, fmt.Errorf("parsing {{.Name}}ID: %w", err)
+	}
+	return {{.Name}}ID(f), nil
+}
+
+// Flake returns the ID as untyped flake
+func (id {{.Name}}ID) Flake() flake.Flake {
+	return flake.Flake(id)
+}
+
+// IsZero reports whether the ID is flake.Nil
+func (id {{.Name}}ID) IsZero() bool {
+	return flake.Flake(id) == flake.Nil
+}
+
+// String returns the prefix and the base58 flake
+func (id {{.Name}}ID) String() string {
+	return {{.Name}}IDPrefix + flake.Flake(id).Base58()
+}
+
+// MarshalText returns the prefix and the base58 flake, the zero ID is empty
+func (id {{.Name}}ID) MarshalText() ([]byte, error) {
+	if id.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText parses the ID like Parse{{.Name}}ID, empty text is the zero ID
+func (id *{{.Name}}ID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*id = 0
+		return nil
+	}
+	parsed, err := Parse{{.Name}}ID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// Scan reads the ID from an integer or encoded column, NULL is the zero ID
+func (id *{{.Name}}ID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*id = 0
+	case int64:
+		*id = {{.Name}}ID(v)
+	case string:
+		return id.UnmarshalText([]byte(v))
+	case []byte:
+		return id.UnmarshalText(v)
+	default:
+		return fmt.Errorf("scanning {{.Name}}ID from %T", src)
+	}
+	return nil
+}
+
+// Value returns the ID as int64 column value
+func (id {{.Name}}ID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+{{end}}`))
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGen(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ids_gen.go")
+	if _, stderr, code := runArgs("", "gen", "-package", "shop", "-o", file, "-flaker", "IDs", "User=usr", "LineItem"); code != 0 {
+		t.Fatalf("Expected exit code 0 but got %d: %s", code, stderr)
+	}
+	src, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"// Code generated by flake gen. DO NOT EDIT.",
+		`const UserIDPrefix = "usr_"`,
+		`const LineItemIDPrefix = "lineitem_"`,
+		"return UserID(IDs.Next())",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("Expected %s in the generated source", expected)
+		}
+	}
+
+	// Type check the generated code together with the generator variable
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, 2)
+	for name, src := range map[string]string{
+		"ids_gen.go": string(src),
+		"ids.go":     "package shop\n\nimport \"go-flake\"\n\nvar IDs = flake.Default\n",
+	} {
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatalf("Parsing %s failed: %v", name, err)
+		}
+		files = append(files, f)
+	}
+	imp := importer.ForCompiler(fset, "source", nil)
+	conf := types.Config{Importer: imp}
+	pkg, err := conf.Check("shop", fset, files, nil)
+	if err != nil {
+		t.Fatalf("Type checking the generated code failed: %v", err)
+	}
+	for _, name := range []string{"UserID", "NewUserID", "ParseUserID", "LineItemID", "ParseLineItemID"} {
+		if pkg.Scope().Lookup(name) == nil {
+			t.Errorf("Expected %s in the generated package", name)
+		}
+	}
+	userID := pkg.Scope().Lookup("UserID").Type()
+	for _, iface := range []string{"encoding.TextMarshaler", "fmt.Stringer", "database/sql/driver.Valuer"} {
+		if !types.Implements(userID, lookupInterface(t, imp, iface)) {
+			t.Errorf("Expected UserID to implement %s", iface)
+		}
+	}
+	for _, iface := range []string{"encoding.TextUnmarshaler", "database/sql.Scanner"} {
+		if !types.Implements(types.NewPointer(userID), lookupInterface(t, imp, iface)) {
+			t.Errorf("Expected *UserID to implement %s", iface)
+		}
+	}
+}
+
+func lookupInterface(t *testing.T, imp types.Importer, name string) *types.Interface {
+	i := strings.LastIndexByte(name, '.')
+	pkg, err := imp.Import(name[:i])
+	if err != nil {
+		t.Fatalf("Importing %s failed: %v", name[:i], err)
+	}
+	return pkg.Scope().Lookup(name[i+1:]).Type().Underlying().(*types.Interface)
+}
+
+func TestGenErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-package", "shop"},
+		{"-package", "", "User"},
+		{"-package", "shop", "user"},
+		{"-package", "shop", "User=Usr"},
+		{"-package", "shop", "User=u_r"},
+		{"-package", "shop", "User=id", "Order=id"},
+		{"-package", "shop", "-flaker", "IDs(", "User"},
+	} {
+		if _, _, code := runArgs("", append([]string{"gen"}, args...)...); code != 1 {
+			t.Errorf("Expected exit code 1 with %v but got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-flake"
+)
+
+// generator holds the flags configuring the Flaker of a command
+type generator struct {
+	machine int
+	epoch   string
+	mode    flake.Mode
+	layout  layoutValue
+}
+
+// flags registers the generator flags mirroring the options of flake.New
+func (g *generator) flags(fs *flag.FlagSet) {
+	fs.IntVar(&g.machine, "machine", -1, "machine-id, derived from the private IPv4 address by default")
+	fs.StringVar(&g.epoch, "epoch", "", "epoch start as RFC 3339 time or date, e.g. 2020-01-01")
+	fs.TextVar(&g.mode, "mode", flake.ModeShuffled, "mode: shuffled, raw or random")
+	fs.Var(&g.layout, "layout", "layout: default, high-entropy or interval/sequence/machine/resolution[/random] bits")
+}
+
+// options returns the options of the flags
+func (g *generator) options() ([]flake.Option, error) {
+	opts := []flake.Option{flake.SetMode(g.mode)}
+	if g.machine >= 0 {
+		if g.machine > 255 {
+			return nil, fmt.Errorf("invalid machine-id %d", g.machine)
+		}
+		opts = append(opts, flake.SetMachineId(byte(g.machine)))
+	}
+	if g.epoch != "" {
+		start, err := parseTime(g.epoch)
+		if err != nil {
+			return nil, fmt.Errorf("invalid epoch %q", g.epoch)
+		}
+		opts = append(opts, flake.SetEpochStart(start))
+	}
+	if g.layout.set {
+		opts = append(opts, flake.SetLayout(g.layout.Layout))
+	}
+	return opts, nil
+}
+
+// flaker returns the Flaker of the flags
+func (g *generator) flaker() (flake.Flaker, error) {
+	opts, err := g.options()
+	if err != nil {
+		return nil, err
+	}
+	return flake.New(opts...)
+}
+
+// parseTime parses an RFC 3339 time or date
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", s)
+	}
+	return t, err
+}
+
+// layoutValue is the flag.Value of a layout
+type layoutValue struct {
+	flake.Layout
+	set bool
+}
+
+// String returns the bit widths of the layout
+func (v *layoutValue) String() string {
+	if !v.set {
+		return "default"
+	}
+	l := v.Layout
+	s := fmt.Sprintf("%d/%d/%d/%d", l.IntervalBits, l.SequenceBits, l.MachineIdBits, l.ResolutionBits)
+	if l.RandomBits > 0 {
+		s += "/" + strconv.Itoa(l.RandomBits)
+	}
+	return s
+}
+
+// Set parses a preset name or the bit widths
+func (v *layoutValue) Set(s string) error {
+	switch s {
+	case "default":
+		v.Layout = flake.DefaultLayout
+	case "high-entropy":
+		v.Layout = flake.HighEntropyLayout
+	default:
+		parts := strings.Split(s, "/")
+		if len(parts) != 4 && len(parts) != 5 {
+			return fmt.Errorf("expected 4 or 5 bit widths separated by slashes")
+		}
+		widths := make([]int, 5)
+		for i, part := range parts {
+			width, err := strconv.Atoi(part)
+			if err != nil {
+				return fmt.Errorf("invalid bit width %q", part)
+			}
+			widths[i] = width
+		}
+		v.Layout = flake.Layout{
+			IntervalBits:   widths[0],
+			SequenceBits:   widths[1],
+			MachineIdBits:  widths[2],
+			ResolutionBits: widths[3],
+			RandomBits:     widths[4],
+		}
+	}
+	v.set = true
+	return v.Layout.Validate()
+}
@@ -0,0 +1,24 @@
+module go-flake/cmd/flake
+
+go 1.23.0
+
+require (
+	go-flake v0.0.0
+	go-flake/grpcflake v0.0.0
+	google.golang.org/grpc v1.73.0
+)
+
+require (
+	go-flake/flakepb v0.0.0 // indirect
+	golang.org/x/net v0.38.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
+	google.golang.org/protobuf v1.36.12 // indirect
+)
+
+replace (
+	go-flake => ../..
+	go-flake/flakepb => ../../flakepb
+	go-flake/grpcflake => ../../grpcflake
+)
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-flake"
+)
+
+// formats are the printable encodings
+var formats = []flake.Format{flake.FormatDecimal, flake.FormatHex, flake.FormatBase32, flake.FormatBase64, flake.FormatBase58}
+
+// inspection is the record of an inspected ID
+type inspection struct {
+	Input string `json:"input"`
+	flake.Info
+	Encodings map[string]string `json:"encodings"`
+}
+
+// cmdInspect decomposes the IDs of the arguments or of the lines of stdin
+func cmdInspect(args []string, s *stdio) error {
+	fs := newFlagSet("inspect", s)
+	asJSON := fs.Bool("json", false, "print JSON lines, same as -output json")
+	out := outputFlag(fs)
+	var g generator
+	g.flags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: flake inspect [flags] [id...]\n\nReads newline-delimited IDs of any encoding from stdin without id arguments.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	flaker, err := g.flaker()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		*out = outputJSON
+	}
+
+	w := bufio.NewWriter(s.out)
+	defer w.Flush()
+	records := newRecordWriter(w, *out, inspectionHeader()...)
+	failed := 0
+	inspect := func(input string) {
+		record, err := inspectID(flaker, input)
+		if err != nil {
+			fmt.Fprintf(s.err, "%q: %v\n", input, err)
+			failed++
+		} else if *out == outputText {
+			printInspection(w, record)
+		} else {
+			records.write(record, record.row())
+		}
+	}
+	if fs.NArg() > 0 {
+		for _, input := range fs.Args() {
+			inspect(input)
+		}
+	} else {
+		scanner := bufio.NewScanner(s.in)
+		for scanner.Scan() {
+			if input := strings.TrimSpace(scanner.Text()); input != "" {
+				inspect(input)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d invalid IDs", failed)
+	}
+	return nil
+}
+
+// parseID decodes an ID like flake.Flake.Set: digits as decimal number, 0x
+// prefixed hex numbers and any other format detected by flake.Decode
+func parseID(s string) (flake.Flake, error) {
+	var f flake.Flake
+	err := f.Set(s)
+	return f, err
+}
+
+// inspectID returns the inspection of the input
+func inspectID(flaker flake.Flaker, input string) (record inspection, err error) {
+	f, err := parseID(input)
+	if err != nil {
+		return record, err
+	}
+	return newInspection(flaker, input, f)
+}
+
+// newInspection returns the inspection of the flake. Flakes of ModeRandom
+// have no fields.
+func newInspection(flaker flake.Flaker, input string, f flake.Flake) (record inspection, err error) {
+	if record.Info, err = flaker.Inspect(f); err != nil && !errors.Is(err, flake.ErrInvalidConfig) {
+		return record, err
+	}
+	record.Input, record.Flake = input, f
+	record.Encodings = make(map[string]string, len(formats))
+	for _, format := range formats {
+		record.Encodings[format.String()] = f.Encode(format)
+	}
+	return record, nil
+}
+
+// inspectionHeader returns the tsv columns of an inspection
+func inspectionHeader() []string {
+	header := []string{"input", "flake", "raw", "time", "interval", "sequence", "machineId"}
+	for _, format := range formats {
+		header = append(header, format.String())
+	}
+	return header
+}
+
+// row returns the tsv values of the inspection, the fields are empty in
+// ModeRandom
+func (record inspection) row() []string {
+	row := []string{record.Input, strconv.FormatInt(int64(record.Flake), 10), "", "", "", "", ""}
+	if !record.Time.IsZero() {
+		row[2] = strconv.FormatInt(int64(record.Raw), 10)
+		row[3] = record.Time.UTC().Format(time.RFC3339Nano)
+		row[4] = strconv.FormatInt(record.Interval, 10)
+		row[5] = strconv.FormatInt(record.Sequence, 10)
+		row[6] = strconv.Itoa(int(record.MachineId))
+	}
+	for _, format := range formats {
+		row = append(row, record.Encodings[format.String()])
+	}
+	return row
+}
+
+// printInspection prints the record as block of aligned fields
+func printInspection(w io.Writer, record inspection) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "input\t%s\n", record.Input)
+	if !record.Time.IsZero() {
+		fmt.Fprintf(tw, "time\t%s\n", record.Time.UTC().Format(time.RFC3339Nano))
+		fmt.Fprintf(tw, "machine\t%d\n", record.MachineId)
+		fmt.Fprintf(tw, "sequence\t%d\n", record.Sequence)
+		fmt.Fprintf(tw, "interval\t%d\n", record.Interval)
+		fmt.Fprintf(tw, "raw\t%d\n", record.Raw)
+	}
+	for _, format := range formats {
+		fmt.Fprintf(tw, "%s\t%s\n", format, record.Encodings[format.String()])
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go-flake"
+)
+
+func TestInspect(t *testing.T) {
+	g := flake.RawWithMachineId(42)
+	at := time.Now().Add(-time.Hour)
+	id, _ := g.GenerateAt(at, 777)
+	input := id.Hex() + "\n\n" + id.Base32() + "\n" + id.Decimal() + "\n"
+
+	stdout, stderr, code := runArgs(input, "inspect", "-mode", "raw", "-json")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 but got %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 JSON lines but got %q", stdout)
+	}
+	for _, line := range lines {
+		var record inspection
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record.Flake != id || record.MachineId != 42 || record.Sequence != 777 || record.Encodings["base64"] != id.Base64() {
+			t.Errorf("Unexpected record %s", line)
+		}
+		if d := at.Sub(record.Time); d < 0 || d > 2*time.Second {
+			t.Errorf("Expected the time %s but got %s", at, record.Time)
+		}
+	}
+
+	info, _ := g.Inspect(id)
+	stdout, _, _ = runArgs("", "inspect", "-mode", "raw", id.Base64())
+	for _, expected := range []string{"machine   42\n", "sequence  777\n", "hex       " + id.Hex() + "\n", "time      " + info.Time.UTC().Format(time.RFC3339Nano) + "\n"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Expected %q in the output %q", expected, stdout)
+		}
+	}
+
+	_, stderr, code = runArgs("invalid\n"+id.Hex()+"\n", "inspect")
+	if code != 1 || !strings.Contains(stderr, `"invalid"`) || !strings.Contains(stderr, "1 invalid IDs") {
+		t.Errorf("Expected exit code 1 of an invalid ID but got %d: %s", code, stderr)
+	}
+}
+
+func TestParseID(t *testing.T) {
+	for input, expected := range map[string]flake.Flake{
+		"12345678901":        12345678901,
+		"1234567890123456":   1234567890123456,
+		"0x00000000000000ff": 255,
+		"00000000000000ff":   255,
+	} {
+		if f, err := parseID(input); err != nil || f != expected {
+			t.Errorf("Expected %d of %s but got %d: %v", expected, input, f, err)
+		}
+	}
+	if _, err := parseID("ffffffffffffffff"); err == nil {
+		t.Error("Expected a negative hex ID to fail")
+	}
+}
@@ -0,0 +1,85 @@
+// Command flake generates and inspects flake IDs.
+//
+//	flake new -n 1000 -enc base32 -machine 42
+//
+// Run flake help for the list of commands.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// stdio are the streams of a command
+type stdio struct {
+	in       io.Reader
+	out, err io.Writer
+}
+
+// command is a subcommand of the CLI
+type command struct {
+	summary string
+	run     func(args []string, s *stdio) error
+}
+
+// commands are the subcommands by name
+var commands = map[string]command{
+	"analyze":  {"report the distribution of IDs over machine-ids and time", cmdAnalyze},
+	"new":      {"generate new IDs", cmdNew},
+	"gen":      {"emit typed ID wrappers of entities for go:generate", cmdGen},
+	"convert":  {"add the time, machine-id or encodings of an ID column to CSV or JSONL records", cmdConvert},
+	"inspect":  {"decompose IDs into their fields", cmdInspect},
+	"serve":    {"run an HTTP and gRPC ID service", cmdServe},
+	"simulate": {"simulate the sequence consumption of a load profile", cmdSimulate},
+	"stress":   {"check the uniqueness, order and randomness of concurrently generated IDs", cmdStress},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &stdio{in: os.Stdin, out: os.Stdout, err: os.Stderr}))
+}
+
+// run runs the command of the arguments and returns the exit code
+func run(args []string, s *stdio) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(s.err)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(s.err, "flake: unknown command %q\n", args[0])
+		usage(s.err)
+		return 2
+	}
+	if err := cmd.run(args[1:], s); errors.Is(err, flag.ErrHelp) {
+		return 2
+	} else if err != nil {
+		fmt.Fprintf(s.err, "flake %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// usage prints the list of commands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: flake <command> [flags]\n\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w, "\nRun flake <command> -h for the flags of a command.")
+}
+
+// newFlagSet returns the flag set of the command writing to the error stream
+func newFlagSet(name string, s *stdio) *flag.FlagSet {
+	fs := flag.NewFlagSet("flake "+name, flag.ContinueOnError)
+	fs.SetOutput(s.err)
+	return fs
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// runArgs runs the CLI with the input and returns the output streams and the
+// exit code
+func runArgs(input string, args ...string) (stdout, stderr string, code int) {
+	var out, err bytes.Buffer
+	code = run(args, &stdio{in: strings.NewReader(input), out: &out, err: &err})
+	return out.String(), err.String(), code
+}
+
+func TestRun(t *testing.T) {
+	if _, stderr, code := runArgs("", "help"); code != 2 || !strings.Contains(stderr, "new") {
+		t.Errorf("Expected the usage with exit code 2 but got %d: %s", code, stderr)
+	}
+	if _, stderr, code := runArgs("", "unknown"); code != 2 || !strings.Contains(stderr, `unknown command "unknown"`) {
+		t.Errorf("Expected an unknown command with exit code 2 but got %d: %s", code, stderr)
+	}
+	if _, _, code := runArgs("", "new", "-h"); code != 2 {
+		t.Errorf("Expected exit code 2 of -h but got %d", code)
+	}
+	if _, stderr, code := runArgs("", "new", "-n", "0"); code != 1 || !strings.Contains(stderr, "flake new: invalid count 0") {
+		t.Errorf("Expected exit code 1 of an error but got %d: %s", code, stderr)
+	}
+}
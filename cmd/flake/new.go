@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+
+	"go-flake"
+)
+
+// cmdNew generates new IDs, one per line
+func cmdNew(args []string, s *stdio) error {
+	fs := newFlagSet("new", s)
+	n := fs.Int("n", 1, "count of IDs")
+	format := flake.FormatDecimal
+	fs.TextVar(&format, "enc", flake.FormatDecimal, "encoding: decimal, hex, base32, base64 or base58")
+	out := outputFlag(fs)
+	vectors := fs.Bool("vectors", false, "print the canonical test vectors of the generator as JSON instead of new IDs")
+	var g generator
+	g.flags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *vectors {
+		flaker, err := g.flaker()
+		if err != nil {
+			return err
+		}
+		set := flake.Vectors(flaker)
+		return writeReport(s.out, *out, set, func() error {
+			encoder := json.NewEncoder(s.out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(set)
+		})
+	}
+	if *n < 1 {
+		return fmt.Errorf("invalid count %d", *n)
+	} else if format == flake.FormatBytes {
+		return fmt.Errorf("bytes encoding not printable")
+	}
+	flaker, err := g.flaker()
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(s.out)
+	records := newRecordWriter(w, *out, inspectionHeader()...)
+	ids := make([]flake.Flake, 0, 4096)
+	for left := *n; left > 0; left -= len(ids) {
+		ids = flaker.AppendNext(ids[:0], min(left, cap(ids)))
+		for _, id := range ids {
+			if *out == outputText {
+				w.WriteString(id.Encode(format))
+				w.WriteByte('\n')
+			} else if record, err := newInspection(flaker, id.Encode(format), id); err != nil {
+				return err
+			} else {
+				records.write(record, record.row())
+			}
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go-flake"
+)
+
+func TestNew(t *testing.T) {
+	stdout, stderr, code := runArgs("", "new", "-n", "5000", "-enc", "base32", "-machine", "42", "-mode", "raw", "-epoch", "2020-01-01")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 but got %d: %s", code, stderr)
+	}
+	lines := strings.Fields(stdout)
+	if len(lines) != 5000 {
+		t.Fatalf("Expected 5000 IDs but got %d", len(lines))
+	}
+	g := flake.RawWithMachineId(42).WithEpochStart(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		id, err := flake.DecodeBase32(line)
+		if err != nil || g.Validate(id, 42) != nil || seen[line] {
+			t.Fatalf("Expected unique valid IDs but got %q: %v", line, g.Validate(id, 42))
+		}
+		seen[line] = true
+	}
+	if lines[0] >= lines[len(lines)-1] {
+		t.Errorf("Expected sorted raw IDs but got %s before %s", lines[0], lines[len(lines)-1])
+	}
+
+	stdout, _, _ = runArgs("", "new", "-layout", "high-entropy", "-machine", "3")
+	id, err := flake.DecodeDecimal(strings.TrimSpace(stdout))
+	if g, _ := flake.New(flake.SetLayout(flake.HighEntropyLayout), flake.SetMachineId(3)); err != nil || g.Validate(id, 3) != nil {
+		t.Errorf("Expected a valid decimal ID of the layout but got %q: %v", stdout, err)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-machine", "256"},
+		{"-epoch", "yesterday"},
+		{"-mode", "sorted"},
+		{"-enc", "bytes"},
+		{"-layout", "32/23/8"},
+		{"-layout", "32/23/9/30"},
+	} {
+		if _, _, code := runArgs("", append([]string{"new"}, args...)...); code == 0 {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
+
+func TestNewVectors(t *testing.T) {
+	stdout, stderr, code := runArgs("", "new", "-vectors", "-mode", "raw", "-epoch", "2020-01-01")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 but got %d: %s", code, stderr)
+	}
+	var set flake.VectorSet
+	if err := json.Unmarshal([]byte(stdout), &set); err != nil {
+		t.Fatalf("Expected JSON test vectors but got %q: %v", stdout, err)
+	}
+	g := flake.RawWithEpochStart(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := flake.Verify(g, set); err != nil || len(set.Vectors) == 0 {
+		t.Errorf("Expected verified test vectors but got %d: %v", len(set.Vectors), err)
+	}
+}
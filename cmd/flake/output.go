@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// output is the format of the results of a command: text for humans, json
+// for JSON lines, tsv for tab separated values with a header row and raw for
+// tab separated values without header
+type output string
+
+// Output formats
+const (
+	outputText output = "text"
+	outputJSON output = "json"
+	outputTSV  output = "tsv"
+	outputRaw  output = "raw"
+)
+
+// outputFlag registers the -output flag of a command
+func outputFlag(fs *flag.FlagSet) *output {
+	o := outputText
+	fs.Var(&o, "output", "output format: text, json (JSON lines), tsv (tab separated values) or raw (tsv without header)")
+	return &o
+}
+
+// String returns the name of the output format
+func (o *output) String() string {
+	return string(*o)
+}
+
+// Set sets the output format of the name
+func (o *output) Set(s string) error {
+	switch output(s) {
+	case outputText, outputJSON, outputTSV, outputRaw:
+		*o = output(s)
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q", s)
+}
+
+// tabular reports the tab separated values of records
+func (o output) tabular() bool {
+	return o == outputTSV || o == outputRaw
+}
+
+// recordWriter writes records of a stable schema as JSON lines or tab
+// separated values
+type recordWriter struct {
+	output  output
+	w       io.Writer
+	header  []string
+	encoder *json.Encoder
+}
+
+// newRecordWriter returns a recordWriter of the output format and the tsv
+// header
+func newRecordWriter(w io.Writer, o output, header ...string) *recordWriter {
+	return &recordWriter{output: o, w: w, header: header, encoder: json.NewEncoder(w)}
+}
+
+// write writes the record as JSON or its row as tab separated values
+func (r *recordWriter) write(record any, row []string) error {
+	if r.output == outputJSON {
+		return r.encoder.Encode(record)
+	}
+	if r.header != nil {
+		if r.output == outputTSV {
+			if err := writeRow(r.w, r.header); err != nil {
+				return err
+			}
+		}
+		r.header = nil
+	}
+	return writeRow(r.w, row)
+}
+
+// writeRow writes tab separated values, tabs and line breaks of the values are
+// replaced by spaces
+func writeRow(w io.Writer, values []string) error {
+	clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	for i, value := range values {
+		values[i] = clean.Replace(value)
+	}
+	_, err := io.WriteString(w, strings.Join(values, "\t")+"\n")
+	return err
+}
+
+// writeReport writes the report as JSON or as field and value rows of its
+// flattened JSON fields like machines.0.count, or calls text
+func writeReport(w io.Writer, o output, report any, text func() error) error {
+	switch o {
+	case outputText:
+		return text()
+	case outputJSON:
+		return json.NewEncoder(w).Encode(report)
+	}
+	b, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	records := newRecordWriter(w, o, "field", "value")
+	return flatten(decoder, "", func(field, value string) error {
+		return records.write(nil, []string{field, value})
+	})
+}
+
+// flatten calls emit with the dotted path and the value of each scalar of the
+// next JSON value of the decoder in document order
+func flatten(decoder *json.Decoder, path string, emit func(field, value string) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			if err := flatten(decoder, join(key.(string)), emit); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	case json.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			if err := flatten(decoder, join(strconv.Itoa(i)), emit); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	case nil:
+		return emit(path, "")
+	}
+	return emit(path, fmt.Sprint(token))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-flake"
+)
+
+func TestOutput(t *testing.T) {
+	stdout, stderr, code := runArgs("", "new", "-n", "3", "-machine", "5", "-output", "tsv")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if code != 0 || len(lines) != 4 || lines[0] != strings.Join(inspectionHeader(), "\t") {
+		t.Fatalf("Expected a header and 3 rows but got %d: %q %s", code, stdout, stderr)
+	}
+	row := strings.Split(lines[1], "\t")
+	if len(row) != len(inspectionHeader()) || row[6] != "5" || row[0] != row[7] {
+		t.Errorf("Unexpected row %q", lines[1])
+	}
+
+	id := flake.WithMachineId(5).Next().Encode(flake.FormatBase64)
+	stdout, _, _ = runArgs("", "inspect", "-output", "raw", id)
+	if fields := strings.Split(strings.TrimSpace(stdout), "\t"); len(fields) != len(inspectionHeader()) || fields[0] != id {
+		t.Errorf("Expected a row without header but got %q", stdout)
+	}
+	stdout, _, _ = runArgs("", "inspect", "-output", "json", id)
+	var record inspection
+	if err := json.Unmarshal([]byte(stdout), &record); err != nil || record.Input != id || record.MachineId != 5 {
+		t.Errorf("Expected a JSON record but got %q: %v", stdout, err)
+	}
+
+	stdout, _, _ = runArgs(id, "analyze", "-output", "tsv")
+	if !strings.HasPrefix(stdout, "field\tvalue\nids\t1\ninvalid\t0\n") || !strings.Contains(stdout, "machines.0.machineId\t5\n") {
+		t.Errorf("Expected flattened fields of the analysis but got %q", stdout)
+	}
+	stdout, _, _ = runArgs("", "simulate", "-rate", "10", "-output", "json")
+	var report flake.SimulationReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || report.Borrowing || report.Layout != flake.DefaultLayout {
+		t.Errorf("Expected a JSON simulation report but got %q: %v", stdout, err)
+	}
+	stdout, _, _ = runArgs("", "stress", "-n", "1000", "-output", "raw")
+	if !strings.HasPrefix(stdout, "ids\t1000\n") || !strings.Contains(stdout, "passed\ttrue\n") {
+		t.Errorf("Expected the stress report without header but got %q", stdout)
+	}
+
+	input := "id,name\n" + id + ",x\n"
+	stdout, _, _ = runArgs(input, "convert", "-add", "machine", "-output", "json")
+	if expected := `{"id":"` + id + `","name":"x","id_machine":"5"}` + "\n"; stdout != expected {
+		t.Errorf("Expected %q but got %q", expected, stdout)
+	}
+	stdout, _, _ = runArgs(input, "convert", "-add", "machine", "-output", "tsv")
+	if expected := "id\tname\tid_machine\n" + id + "\tx\t5\n"; stdout != expected {
+		t.Errorf("Expected %q but got %q", expected, stdout)
+	}
+	if _, stderr, code := runArgs("", "convert", "-input", "jsonl", "-output", "tsv"); code != 1 || !strings.Contains(stderr, "no tsv output") {
+		t.Errorf("Expected an error of tsv output of JSONL but got %d: %s", code, stderr)
+	}
+	if _, stderr, code := runArgs("", "new", "-output", "xml"); code != 1 || !strings.Contains(stderr, `unknown output format "xml"`) {
+		t.Errorf("Expected an unknown output format but got %d: %s", code, stderr)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-flake"
+	"go-flake/grpcflake"
+	"go-flake/httpflake"
+	"google.golang.org/grpc"
+)
+
+// Environment variables of the listen addresses read by serve
+const (
+	envHTTPAddr = "FLAKE_HTTP_ADDR"
+	envGRPCAddr = "FLAKE_GRPC_ADDR"
+)
+
+// serveContext returns the context of serve, which is done on SIGINT or
+// SIGTERM to shut down gracefully
+var serveContext = func() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// cmdServe runs an ID service over HTTP and gRPC until SIGINT or SIGTERM
+func cmdServe(args []string, s *stdio) error {
+	fs := newFlagSet("serve", s)
+	httpAddr := fs.String("http", envOr(envHTTPAddr, ":8080"), "listen address of the HTTP service at /ids, empty to disable, $"+envHTTPAddr)
+	grpcAddr := fs.String("grpc", envOr(envGRPCAddr, ":9090"), "listen address of the gRPC IDService, empty to disable, $"+envGRPCAddr)
+	configFile := fs.String("config", "", "JSON config file of the generator instead of the FLAKE_* environment variables")
+	stateFile := fs.String("state", "", "file persisting the generator state across restarts unless set by the config")
+	maxBatch := fs.Int("max-batch", httpflake.DefaultMaxBatch, "largest count of IDs per request")
+	format := flake.FormatDecimal
+	fs.TextVar(&format, "enc", flake.FormatDecimal, "default encoding of the HTTP service")
+	out := outputFlag(fs)
+	timeout := fs.Duration("shutdown-timeout", 10*time.Second, "time to complete running requests on shutdown")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: flake serve [flags]\n\nThe generator is configured by the -config file or the FLAKE_* environment variables\nof flake.NewFromEnv. It fails to start if flake.Preflight reports a problem.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if *httpAddr == "" && *grpcAddr == "" {
+		return errors.New("neither HTTP nor gRPC address")
+	}
+
+	var opts []flake.Option
+	if *stateFile != "" {
+		opts = append(opts, flake.SetStateStore(flake.FileStore(*stateFile)))
+	}
+	var flaker flake.Flaker
+	var err error
+	if *configFile != "" {
+		var cfg flake.Config
+		if cfg, err = flake.LoadConfig(*configFile); err == nil {
+			flaker, err = flake.NewFromConfig(cfg, opts...)
+		}
+	} else {
+		flaker, err = flake.NewFromEnv(opts...)
+	}
+	if p, ok := flaker.(flake.Preflighter); ok && err == nil {
+		err = p.Preflight()
+	}
+	if err != nil {
+		return err
+	}
+
+	events := newRecordWriter(s.err, *out, "event", "protocol", "address")
+	event := func(e serveEvent) {
+		if *out != outputText {
+			events.write(e, []string{e.Event, e.Protocol, e.Address})
+		} else if e.Address != "" {
+			fmt.Fprintf(s.err, "%s %s on %s\n", e.Event, e.Protocol, e.Address)
+		} else {
+			fmt.Fprintln(s.err, e.Event)
+		}
+	}
+
+	ctx, stop := serveContext()
+	defer stop()
+	errs := make(chan error, 2)
+	var shutdown []func(context.Context)
+	if *httpAddr != "" {
+		listener, err := net.Listen("tcp", *httpAddr)
+		if err != nil {
+			return err
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/ids", &httpflake.Handler{Flaker: flaker, Format: format, MaxBatch: *maxBatch})
+		server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+		shutdown = append(shutdown, func(ctx context.Context) { server.Shutdown(ctx) })
+		event(serveEvent{Event: "serving", Protocol: "HTTP", Address: listener.Addr().String()})
+	}
+	if *grpcAddr != "" {
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			return err
+		}
+		server := grpc.NewServer()
+		grpcflake.RegisterIDServiceServer(server, &grpcflake.Server{Flaker: flaker, MaxBatch: *maxBatch})
+		go func() {
+			if err := server.Serve(listener); err != nil {
+				errs <- err
+			}
+		}()
+		shutdown = append(shutdown, func(ctx context.Context) {
+			done := make(chan struct{})
+			go func() {
+				server.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-ctx.Done():
+				server.Stop()
+			}
+		})
+		event(serveEvent{Event: "serving", Protocol: "gRPC", Address: listener.Addr().String()})
+	}
+
+	select {
+	case <-ctx.Done():
+		err = nil
+	case err = <-errs:
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	for _, stop := range shutdown {
+		stop(shutdownCtx)
+	}
+	event(serveEvent{Event: "stopped"})
+	return err
+}
+
+// serveEvent is the record of an event of serve
+type serveEvent struct {
+	Event    string `json:"event"`
+	Protocol string `json:"protocol,omitempty"`
+	Address  string `json:"address,omitempty"`
+}
+
+// envOr returns the environment variable or the fallback if unset
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-flake"
+	"go-flake/grpcflake"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	serveContext = func() (context.Context, context.CancelFunc) { return ctx, cancel }
+
+	dir := t.TempDir()
+	config, state := filepath.Join(dir, "flake.json"), filepath.Join(dir, "flake.state")
+	if err := os.WriteFile(config, []byte(`{"machineId": 42, "mode": "raw"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stderr, w := io.Pipe()
+	code := make(chan int)
+	go func() {
+		code <- run([]string{"serve", "-config", config, "-state", state, "-http", "127.0.0.1:0", "-grpc", "127.0.0.1:0"},
+			&stdio{in: strings.NewReader(""), out: io.Discard, err: w})
+		w.Close()
+	}()
+	lines := bufio.NewScanner(stderr)
+	addrs := make(map[string]string)
+	for len(addrs) < 2 && lines.Scan() {
+		if protocol, addr, ok := strings.Cut(strings.TrimPrefix(lines.Text(), "serving "), " on "); ok {
+			addrs[protocol] = addr
+		}
+	}
+	go io.Copy(io.Discard, stderr)
+
+	resp, err := http.Get("http://" + addrs["HTTP"] + "/ids?n=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	ids := strings.Fields(string(body))
+	if resp.StatusCode != http.StatusOK || len(ids) != 3 {
+		t.Errorf("Expected 3 IDs but got %d: %q", resp.StatusCode, body)
+	}
+
+	conn, err := grpc.NewClient(addrs["gRPC"], grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	id, err := grpcflake.NewClient(conn).Next(context.Background())
+	if err != nil {
+		t.Fatalf("Expected an ID of the gRPC service but got %v", err)
+	}
+	if info, _ := flake.RawWithMachineId(42).Inspect(id); info.MachineId != 42 || len(ids) > 0 && id.Encode(flake.FormatDecimal) <= ids[2] {
+		t.Errorf("Expected a raw ID of machine-id 42 after %v but got %d", ids, id)
+	}
+
+	cancel()
+	if code := <-code; code != 0 {
+		t.Errorf("Expected exit code 0 after the shutdown but got %d", code)
+	}
+	if _, err := os.Stat(state); err != nil {
+		t.Errorf("Expected the state file but got %v", err)
+	}
+
+	if _, stderr, code := runArgs("", "serve", "-http", "", "-grpc", ""); code != 1 || !strings.Contains(stderr, "neither") {
+		t.Errorf("Expected an error without addresses but got %d: %s", code, stderr)
+	}
+}
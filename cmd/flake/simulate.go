@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-flake"
+)
+
+// cmdSimulate simulates the sequence consumption of a layout by a load profile
+func cmdSimulate(args []string, s *stdio) error {
+	fs := newFlagSet("simulate", s)
+	var layout layoutValue
+	fs.Var(&layout, "layout", "layout: default, high-entropy or interval/sequence/machine/resolution[/random] bits")
+	rate := fs.Float64("rate", 1000, "IDs per second and machine-id")
+	duration := fs.Duration("duration", time.Minute, "simulated time")
+	var profile profileValue
+	out := outputFlag(fs)
+	fs.Var(&profile, "profile", "load profile of rate:duration phases replacing -rate and -duration, e.g. 1000:1m,500000:5s,1000:1m")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !layout.set {
+		layout.Layout = flake.DefaultLayout
+	}
+	if len(profile) == 0 {
+		profile = profileValue{{Rate: *rate, Duration: *duration}}
+	}
+	report, err := flake.Simulate(layout.Layout, profile...)
+	if err != nil {
+		return err
+	}
+	return writeReport(s.out, *out, report, func() error {
+		_, err := fmt.Fprintln(s.out, report)
+		return err
+	})
+}
+
+// profileValue is the flag.Value of a load profile
+type profileValue []flake.Load
+
+// String returns the phases of the profile
+func (p *profileValue) String() string {
+	phases := make([]string, len(*p))
+	for i, load := range *p {
+		phases[i] = strconv.FormatFloat(load.Rate, 'g', -1, 64) + ":" + load.Duration.String()
+	}
+	return strings.Join(phases, ",")
+}
+
+// Set parses the comma separated rate:duration phases
+func (p *profileValue) Set(s string) error {
+	*p = nil
+	for _, phase := range strings.Split(s, ",") {
+		rate, duration, ok := strings.Cut(phase, ":")
+		if !ok {
+			return fmt.Errorf("expected rate:duration instead of %q", phase)
+		}
+		load := flake.Load{}
+		var err error
+		if load.Rate, err = strconv.ParseFloat(rate, 64); err != nil {
+			return fmt.Errorf("invalid rate %q", rate)
+		}
+		if load.Duration, err = time.ParseDuration(duration); err != nil {
+			return fmt.Errorf("invalid duration %q", duration)
+		}
+		*p = append(*p, load)
+	}
+	return nil
+}
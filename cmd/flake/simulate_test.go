@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	stdout, stderr, code := runArgs("", "simulate", "-layout", "high-entropy", "-profile", "100:10s,2000:2s,100:10s")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 but got %d: %s", code, stderr)
+	}
+	for _, expected := range []string{"simulated 22s", "borrowing starts at", "final debt 0s", "cool-down"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Expected %q in the report %q", expected, stdout)
+		}
+	}
+	if stdout, _, _ := runArgs("", "simulate", "-rate", "10"); !strings.Contains(stdout, "no borrowing") {
+		t.Errorf("Expected no borrowing but got %q", stdout)
+	}
+	for _, profile := range []string{"100", "x:1s", "100:x", "-1:1s"} {
+		if _, _, code := runArgs("", "simulate", "-profile", profile); code == 0 {
+			t.Errorf("Expected an error of the profile %q", profile)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"go-flake"
+)
+
+// stressReport is the result of the stress subcommand
+type stressReport struct {
+	IDs        int           `json:"ids"`
+	Machines   int           `json:"machines"`
+	Goroutines int           `json:"goroutines"`
+	Elapsed    time.Duration `json:"elapsed"`
+	Duplicates int           `json:"duplicates"`
+	// Unordered counts IDs not above their predecessor of the same goroutine,
+	// -1 if not checked outside of ModeRaw
+	Unordered int `json:"unordered"`
+	// RandomBits are the checked bit positions, MaxBias the largest deviation
+	// of the share of ones of a position from 0.5 in standard deviations.
+	RandomBits int     `json:"randomBits"`
+	Samples    int     `json:"samples"`
+	MaxBias    float64 `json:"maxBias"`
+	Passed     bool    `json:"passed"`
+}
+
+// maxBias is the largest deviation of the bit shares in standard deviations
+// tolerated by stress
+const maxBias = 5
+
+// cmdStress generates IDs with concurrent generators and checks their quality
+func cmdStress(args []string, s *stdio) error {
+	fs := newFlagSet("stress", s)
+	n := fs.Int("n", 1000000, "count of IDs")
+	machines := fs.Int("machines", 4, "count of generators with consecutive machine-ids from 0")
+	goroutines := fs.Int("goroutines", 4, "count of goroutines per generator")
+	out := outputFlag(fs)
+	var g generator
+	g.flags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *n < 1 || *machines < 1 || *goroutines < 1 {
+		return fmt.Errorf("invalid counts")
+	}
+	flaker, err := g.flaker()
+	if err != nil {
+		return err
+	}
+	if *machines > flaker.Layout().MachineIds() {
+		return fmt.Errorf("%d machines exceed the %d machine-ids of the layout", *machines, flaker.Layout().MachineIds())
+	}
+
+	r := stress(flaker, *n, *machines, *goroutines)
+	if err := writeReport(s.out, *out, r, func() error { return printStress(s.out, r) }); err != nil {
+		return err
+	}
+	if !r.Passed {
+		return fmt.Errorf("quality check failed")
+	}
+	return nil
+}
+
+// printStress prints the report for humans
+func printStress(w io.Writer, r stressReport) error {
+	fmt.Fprintf(w, "%d IDs of %d machines with %d goroutines each in %s (%.3g IDs/s)\n",
+		r.IDs, r.Machines, r.Goroutines, r.Elapsed.Round(time.Millisecond), float64(r.IDs)/r.Elapsed.Seconds())
+	fmt.Fprintf(w, "duplicates: %d\n", r.Duplicates)
+	if r.Unordered >= 0 {
+		fmt.Fprintf(w, "unordered: %d\n", r.Unordered)
+	}
+	if r.RandomBits > 0 {
+		fmt.Fprintf(w, "random bits: %d positions of %d IDs, max bias %.2f sigma\n", r.RandomBits, r.Samples, r.MaxBias)
+	}
+	if !r.Passed {
+		_, err := fmt.Fprintln(w, "FAIL")
+		return err
+	}
+	_, err := fmt.Fprintln(w, "PASS")
+	return err
+}
+
+// stress generates n IDs with the machines and goroutines and checks them
+func stress(flaker flake.Flaker, n, machines, goroutines int) stressReport {
+	workers := machines * goroutines
+	batches := make([][]flake.Flake, workers)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for m := 0; m < machines; m++ {
+		machine := flaker.Clone().WithMachineId(byte(m))
+		for i := 0; i < goroutines; i++ {
+			w := m*goroutines + i
+			count := n / workers
+			if w < n%workers {
+				count++
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ids := make([]flake.Flake, count)
+				for j := range ids {
+					ids[j] = machine.Next()
+				}
+				batches[w] = ids
+			}()
+		}
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	r := check(flaker, batches)
+	r.Machines, r.Goroutines, r.Elapsed = machines, goroutines, elapsed
+	return r
+}
+
+// check checks the batches of IDs of the goroutines
+func check(flaker flake.Flaker, batches [][]flake.Flake) stressReport {
+	r := stressReport{Unordered: -1}
+	for _, ids := range batches {
+		r.IDs += len(ids)
+	}
+	all := make([]flake.Flake, 0, r.IDs)
+	if flaker.Mode() == flake.ModeRaw {
+		r.Unordered = 0
+	}
+	for _, ids := range batches {
+		for j := 1; j < len(ids) && r.Unordered >= 0; j++ {
+			if ids[j] <= ids[j-1] {
+				r.Unordered++
+			}
+		}
+		all = append(all, ids...)
+	}
+	slices.Sort(all)
+	for i := 1; i < len(all); i++ {
+		if all[i] == all[i-1] {
+			r.Duplicates++
+		}
+	}
+
+	ones := randomBitCounts(flaker, all, &r)
+	for _, count := range ones {
+		if r.Samples > 0 {
+			// Deviation of the binomial share of ones from 0.5
+			bias := math.Abs(float64(count)-float64(r.Samples)/2) / (math.Sqrt(float64(r.Samples)) / 2)
+			r.MaxBias = math.Max(r.MaxBias, bias)
+		}
+	}
+	r.Passed = r.Duplicates == 0 && r.Unordered <= 0 && r.MaxBias <= maxBias
+	return r
+}
+
+// randomBitCounts counts the ones of the bit positions filled with random bits
+// for all IDs. It checks the positions random in all IDs: all bits in
+// ModeRandom, the random bits of the layout or the least count of random bits
+// of the default phased sequence.
+func randomBitCounts(flaker flake.Flaker, ids []flake.Flake, r *stressReport) []int {
+	l := flaker.Layout()
+	if flaker.Mode() == flake.ModeRandom {
+		r.RandomBits = 63
+	} else {
+		r.RandomBits = l.RandomBits
+		if r.RandomBits == 0 {
+			r.RandomBits = 16
+			for _, id := range ids {
+				info, _ := flaker.Inspect(id)
+				if info.Sequence >= 1<<(l.SequenceBits-1) {
+					r.RandomBits = 0
+					break
+				} else if info.Sequence >= 1<<(l.SequenceBits-2) {
+					r.RandomBits = 8
+				}
+			}
+		}
+	}
+	ones := make([]int, r.RandomBits)
+	if r.RandomBits == 0 {
+		return ones
+	}
+	r.Samples = len(ids)
+	for _, id := range ids {
+		bits := int64(id)
+		if flaker.Mode() != flake.ModeRandom {
+			info, _ := flaker.Inspect(id)
+			bits = info.Sequence
+		}
+		for b := range ones {
+			ones[b] += int(bits >> b & 1)
+		}
+	}
+	return ones
+}
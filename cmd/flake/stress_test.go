@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go-flake"
+)
+
+func TestStress(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-n", "20000", "-mode", "raw"}, "unordered: 0\n"},
+		{[]string{"-n", "20000", "-layout", "high-entropy", "-machines", "16"}, "random bits: 18 positions of 20000 IDs"},
+		{[]string{"-n", "20000", "-mode", "random"}, "random bits: 63 positions"},
+		{[]string{"-n", "100", "-machines", "1", "-goroutines", "1"}, "random bits: 8 positions"},
+	} {
+		stdout, stderr, code := runArgs("", append([]string{"stress"}, tc.args...)...)
+		if code != 0 || !strings.Contains(stdout, "duplicates: 0\n") || !strings.HasSuffix(stdout, "PASS\n") || !strings.Contains(stdout, tc.expected) {
+			t.Errorf("Expected to pass with %q for %v but got %d: %s%s", tc.expected, tc.args, code, stdout, stderr)
+		}
+	}
+	if _, stderr, code := runArgs("", "stress", "-layout", "high-entropy", "-machines", "17"); code != 1 || !strings.Contains(stderr, "exceed") {
+		t.Errorf("Expected an error of too many machines but got %d: %s", code, stderr)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	g := flake.WithMode(flake.ModeRaw)
+	ids := g.NextN(1000)
+	if r := check(g, [][]flake.Flake{ids}); !r.Passed || r.IDs != 1000 || r.Unordered != 0 {
+		t.Errorf("Expected to pass but got %+v", r)
+	}
+	if r := check(g, [][]flake.Flake{ids, ids[:10]}); r.Passed || r.Duplicates != 10 {
+		t.Errorf("Expected 10 duplicates but got %+v", r)
+	}
+	reversed := []flake.Flake{ids[1], ids[0]}
+	if r := check(g, [][]flake.Flake{reversed}); r.Passed || r.Unordered != 1 {
+		t.Errorf("Expected an unordered ID but got %+v", r)
+	}
+
+	// IDs of ModeRandom with constant lower bits
+	random := flake.WithMode(flake.ModeRandom)
+	ids = random.NextN(1000)
+	for i := range ids {
+		ids[i] |= 1
+	}
+	if r := check(random, [][]flake.Flake{ids}); r.Passed || r.MaxBias < 30 {
+		t.Errorf("Expected a bias of the constant bit but got %+v", r)
+	}
+}
@@ -0,0 +1,109 @@
+package flake
+
+import (
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// CollisionChecker detects suspected duplicates in streams of IDs with a
+// Bloom filter of bounded memory, e.g. to validate the merge of the IDs of
+// several clusters offline. The filter uses about 1.2 bytes per expected ID
+// at a 1% false positive rate instead of the 8 bytes or more of an exact set.
+// Suspects are either duplicates or false positives, Confirm separates them
+// in a second pass. A CollisionChecker isn't safe for concurrent use.
+type CollisionChecker struct {
+	bits     []uint64
+	m        uint64 // count of bits
+	k        int    // count of hashes per ID
+	count    uint64
+	suspects FlakeSet
+}
+
+// NewCollisionChecker returns a CollisionChecker sized for the expected count
+// of IDs at the false positive rate, e.g. 0.01
+func NewCollisionChecker(expected int, falsePositiveRate float64) *CollisionChecker {
+	if expected < 1 {
+		expected = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+	m := uint64(math.Ceil(-float64(expected) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	m = (m + 63) &^ 63
+	k := int(math.Round(float64(m) / float64(expected) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &CollisionChecker{bits: make([]uint64, m/64), m: m, k: k}
+}
+
+// Add ingests the ID and reports whether it is a suspected duplicate of a
+// previously added one
+func (c *CollisionChecker) Add(f Flake) bool {
+	c.count++
+	h1 := mix64(uint64(f))
+	h2 := mix64(h1) | 1
+	seen := true
+	for i := 0; i < c.k; i++ {
+		bit, _ := bits.Mul64(h1+uint64(i)*h2, c.m)
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if c.bits[word]&mask == 0 {
+			seen = false
+			c.bits[word] |= mask
+		}
+	}
+	if seen {
+		c.suspects.Add(f)
+	}
+	return seen
+}
+
+// Count returns the count of added IDs
+func (c *CollisionChecker) Count() uint64 {
+	return c.count
+}
+
+// Suspects returns the sorted suspected duplicates
+func (c *CollisionChecker) Suspects() []Flake {
+	suspects := make([]Flake, 0, c.suspects.Len())
+	if c.suspects.hasNil {
+		suspects = append(suspects, Nil)
+	}
+	for _, f := range c.suspects.slots {
+		if f != Nil {
+			suspects = append(suspects, f)
+		}
+	}
+	slices.Sort(suspects)
+	return suspects
+}
+
+// FalsePositiveRate returns the estimated probability of a new ID to be
+// reported as suspect at the current fill of the filter
+func (c *CollisionChecker) FalsePositiveRate() float64 {
+	return math.Pow(1-math.Exp(-float64(c.k)*float64(c.count)/float64(c.m)), float64(c.k))
+}
+
+// Confirm returns the suspects occurring more than once in the IDs, the
+// confirmed duplicates, from a second pass over the same stream
+func (c *CollisionChecker) Confirm(ids func(yield func(Flake) bool)) []Flake {
+	seen := NewFlakeSet(c.suspects.Len())
+	var duplicates []Flake
+	reported := NewFlakeSet(0)
+	ids(func(f Flake) bool {
+		if c.suspects.Contains(f) && !seen.Add(f) && reported.Add(f) {
+			duplicates = append(duplicates, f)
+		}
+		return true
+	})
+	slices.Sort(duplicates)
+	return duplicates
+}
+
+// mix64 is the SplitMix64 finalizer
+func mix64(x uint64) uint64 {
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
@@ -0,0 +1,51 @@
+package flake
+
+import (
+	"testing"
+)
+
+func TestCollisionChecker(t *testing.T) {
+	ids := Raw.NextN(100000)
+	duplicates := []Flake{ids[10], ids[5000], ids[99999]}
+	stream := append(append([]Flake{}, ids...), duplicates...)
+
+	c := NewCollisionChecker(len(stream), 0.001)
+	if c.k != 10 || len(c.bits)*8 > 2*len(stream) {
+		t.Errorf("Expected 10 hashes and about 1.8 bytes per ID but got %d hashes and %d bytes", c.k, len(c.bits)*8)
+	}
+	suspected := 0
+	for _, f := range stream {
+		if c.Add(f) {
+			suspected++
+		}
+	}
+	if c.Count() != uint64(len(stream)) || suspected != len(c.Suspects()) {
+		t.Errorf("Expected %d IDs with %d suspects but got %d with %v", len(stream), suspected, c.Count(), c.Suspects())
+	}
+	if suspected < len(duplicates) || suspected > len(duplicates)+1000 {
+		t.Errorf("Expected the duplicates and few false positives as suspects but got %d", suspected)
+	}
+	for _, d := range duplicates {
+		found := false
+		for _, s := range c.Suspects() {
+			found = found || s == d
+		}
+		if !found {
+			t.Errorf("Expected duplicate %d to be suspected", d)
+		}
+	}
+	if rate := c.FalsePositiveRate(); rate < 0.0005 || rate > 0.002 {
+		t.Errorf("Expected a false positive rate of about 0.001 but got %g", rate)
+	}
+
+	confirmed := c.Confirm(func(yield func(Flake) bool) {
+		for _, f := range stream {
+			if !yield(f) {
+				return
+			}
+		}
+	})
+	if len(confirmed) != len(duplicates) || confirmed[0] != duplicates[0] || confirmed[2] != duplicates[2] {
+		t.Errorf("Expected the confirmed duplicates %v but got %v", duplicates, confirmed)
+	}
+}
@@ -0,0 +1,49 @@
+package flake
+
+import (
+	"cmp"
+	"time"
+)
+
+// Compare compares raw flakes in creation order, which is -1 if f is less
+// than other and +1 if greater. Compare IDs of shuffling generators with
+// CompareRaw.
+func (f Flake) Compare(other Flake) int {
+	return cmp.Compare(f, other)
+}
+
+// Before reports whether the raw flake was created before the other
+func (f Flake) Before(other Flake) bool {
+	return f < other
+}
+
+// After reports whether the raw flake was created after the other
+func (f Flake) After(other Flake) bool {
+	return f > other
+}
+
+// Before reports whether the ID of the flaker was created before the interval
+// containing the time, e.g. for a cutoff. The interval is decoded according
+// to the epoch, the layout and the mode of the flaker, IDs of the interval of
+// the time are neither before nor after it. It's false for IDs without a
+// time like those of ModeRandom.
+func Before(flaker Flaker, f Flake, t time.Time) bool {
+	interval, ok := compareInterval(flaker, f, t)
+	return ok && interval < 0
+}
+
+// After reports whether the ID of the flaker was created after the interval
+// containing the time like Before.
+func After(flaker Flaker, f Flake, t time.Time) bool {
+	interval, ok := compareInterval(flaker, f, t)
+	return ok && interval > 0
+}
+
+// compareInterval compares the interval of the ID with the one of the time
+func compareInterval(flaker Flaker, f Flake, t time.Time) (int, bool) {
+	info, err := flaker.Inspect(f)
+	if err != nil {
+		return 0, false
+	}
+	return cmp.Compare(info.Interval, (t.UnixNano()-flaker.EpochStart().UnixNano())>>flaker.Layout().ResolutionBits), true
+}
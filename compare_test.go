@@ -0,0 +1,48 @@
+package flake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlakeCompare(t *testing.T) {
+	a, b := Flake(10), Flake(20)
+	if a.Compare(b) != -1 || b.Compare(a) != 1 || a.Compare(a) != 0 {
+		t.Errorf("Expected %d < %d", a, b)
+	}
+	if !a.Before(b) || a.After(b) || !b.After(a) || a.Before(a) {
+		t.Errorf("Expected %d before %d", a, b)
+	}
+}
+
+func TestBeforeAfter(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	for _, mode := range []Mode{ModeShuffled, ModeRaw} {
+		f, err := New(SetClock(clock), SetMode(mode), SetMachineId(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := f.Next()
+		if !Before(f, id, clock.now.Add(time.Minute)) || After(f, id, clock.now.Add(time.Minute)) {
+			t.Errorf("Expected %s ID %d before a minute later", mode, id)
+		}
+		if !After(f, id, clock.now.Add(-time.Minute)) || Before(f, id, clock.now.Add(-time.Minute)) {
+			t.Errorf("Expected %s ID %d after a minute earlier", mode, id)
+		}
+		if Before(f, id, clock.now) || After(f, id, clock.now) {
+			t.Errorf("Expected %s ID %d neither before nor after its creation time", mode, id)
+		}
+		// The generator's epoch decodes the time, another epoch shifts it
+		other := f.WithEpochStart(time.Unix(1500000000, 0))
+		if !Before(other, id, clock.now) {
+			t.Errorf("Expected %s ID %d of a later epoch before its creation time with an earlier epoch", mode, id)
+		}
+	}
+	random := WithMode(ModeRandom)
+	if id := random.Next(); Before(random, id, time.Now().Add(time.Hour)) || After(random, id, time.Time{}) {
+		t.Errorf("Expected random ID %d neither before nor after any time", id)
+	}
+	if id := Next(); !Before(Default, id, time.Now().Add(time.Minute)) || !After(struct{ Flaker }{Default}, id, time.Now().Add(-time.Minute)) {
+		t.Errorf("Expected ID %d of Default between a minute before and after now", id)
+	}
+}
@@ -0,0 +1,139 @@
+package flake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// MachineIdSource defines where the machine-id of a Config comes from.
+type MachineIdSource string
+
+const (
+	// MachineIdStatic uses Config.MachineId. This is the default when the
+	// machine-id is set.
+	MachineIdStatic MachineIdSource = "static"
+	// MachineIdFromIP derives the machine-id from the first private IPv4
+	// address. This is the default when no machine-id is set.
+	MachineIdFromIP MachineIdSource = "ip"
+	// MachineIdFromHostname uses the number the hostname ends with, e.g. the
+	// ordinal of a stateful set pod like web-3.
+	MachineIdFromHostname MachineIdSource = "hostname"
+)
+
+// Config describes a Flaker for NewFromConfig. It's meant to be embedded into
+// the configuration of a service. Zero values keep the defaults of New.
+type Config struct {
+	MachineIdSource MachineIdSource `json:"machineIdSource,omitempty" yaml:"machineIdSource,omitempty"`
+	MachineId       *int            `json:"machineId,omitempty" yaml:"machineId,omitempty"`
+	EpochStart      *time.Time      `json:"epochStart,omitempty" yaml:"epochStart,omitempty"`
+	Layout          *Layout         `json:"layout,omitempty" yaml:"layout,omitempty"`
+	Mode            Mode            `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Entropy         EntropySource   `json:"entropy,omitempty" yaml:"entropy,omitempty"`
+	StateFile       string          `json:"stateFile,omitempty" yaml:"stateFile,omitempty"`
+	ClockPolicy     ClockPolicy     `json:"clockPolicy,omitempty" yaml:"clockPolicy,omitempty"`
+	EpochPolicy     EpochPolicy     `json:"epochPolicy,omitempty" yaml:"epochPolicy,omitempty"`
+	SequencePolicy  SequencePolicy  `json:"sequencePolicy,omitempty" yaml:"sequencePolicy,omitempty"`
+	EntropyPolicy   EntropyPolicy   `json:"entropyPolicy,omitempty" yaml:"entropyPolicy,omitempty"`
+}
+
+// LoadConfig reads a Config from a JSON file. Unknown fields are rejected to
+// not silently ignore typos.
+func LoadConfig(path string) (cfg Config, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer file.Close()
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&cfg); err != nil && !errors.Is(err, ErrInvalidConfig) {
+		err = fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return
+}
+
+// NewFromConfig returns a new Flaker described by the config. The options are
+// applied before the config, e.g. to register callbacks of the policies. The
+// returned errors wrap ErrInvalidConfig or result from loading the state file.
+func NewFromConfig(cfg Config, opts ...Option) (Flaker, error) {
+	source := cfg.MachineIdSource
+	if source == "" {
+		source = MachineIdFromIP
+		if cfg.MachineId != nil {
+			source = MachineIdStatic
+		}
+	}
+	switch {
+	case source == MachineIdStatic && cfg.MachineId == nil:
+		return nil, fmt.Errorf("%w: static machine-id not set", ErrInvalidConfig)
+	case source != MachineIdStatic && cfg.MachineId != nil:
+		return nil, fmt.Errorf("%w: machine-id set for source %q", ErrInvalidConfig, source)
+	case source == MachineIdFromHostname:
+		machineId, err := hostnameOrdinal()
+		if err != nil {
+			return nil, err
+		}
+		cfg.MachineId = &machineId
+	case source != MachineIdStatic && source != MachineIdFromIP:
+		return nil, fmt.Errorf("%w: unknown machine-id source %q", ErrInvalidConfig, source)
+	}
+
+	if cfg.MachineId != nil {
+		if *cfg.MachineId < 0 || *cfg.MachineId > 255 {
+			return nil, fmt.Errorf("%w: machine-id %d", ErrInvalidConfig, *cfg.MachineId)
+		}
+		opts = append(opts, SetMachineId(byte(*cfg.MachineId)))
+	}
+	if cfg.EpochStart != nil {
+		opts = append(opts, SetEpochStart(*cfg.EpochStart))
+	}
+	if cfg.Layout != nil {
+		opts = append(opts, SetLayout(*cfg.Layout))
+	}
+	if cfg.StateFile != "" {
+		opts = append(opts, SetStateStore(FileStore(cfg.StateFile)))
+	}
+	// Only set the policies to keep the callbacks of the options
+	opts = append(opts, func(o *options) {
+		if cfg.Mode != ModeShuffled {
+			o.mode = cfg.Mode
+		}
+		if cfg.Entropy != EntropyCrypto {
+			o.entropySource = cfg.Entropy
+		}
+		if cfg.ClockPolicy != ClockBorrow {
+			o.clockPolicy = cfg.ClockPolicy
+		}
+		if cfg.EpochPolicy != EpochWrap {
+			o.epochPolicy = cfg.EpochPolicy
+		}
+		if cfg.SequencePolicy != SequenceBorrow {
+			o.sequencePolicy = cfg.SequencePolicy
+		}
+		if cfg.EntropyPolicy != EntropyError {
+			o.entropyPolicy = cfg.EntropyPolicy
+		}
+	})
+	return New(opts...)
+}
+
+// hostnameOrdinal returns the number the hostname ends with
+func hostnameOrdinal() (int, error) {
+	name, err := os.Hostname()
+	if err != nil {
+		return 0, err
+	}
+	i := len(name)
+	for i > 0 && name[i-1] >= '0' && name[i-1] <= '9' {
+		i--
+	}
+	ordinal, err := strconv.Atoi(name[i:])
+	if err != nil {
+		return 0, fmt.Errorf("%w: hostname %q doesn't end with a number", ErrInvalidConfig, name)
+	}
+	return ordinal, nil
+}
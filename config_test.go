@@ -0,0 +1,83 @@
+package flake
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "flake.json")
+	_ = os.WriteFile(path, []byte(`{
+		"machineId": 42,
+		"epochStart": "2024-01-01T00:00:00Z",
+		"layout": {"intervalBits": 32, "sequenceBits": 27, "machineIdBits": 4, "resolutionBits": 30},
+		"mode": "raw",
+		"entropy": "chacha8",
+		"clockPolicy": "wait",
+		"sequencePolicy": "spin"
+	}`), 0600)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Errorf("Loading config failed: %v", err)
+		return
+	}
+	if _, err := NewFromConfig(cfg); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for a machine-id exceeding the layout but got %v", err)
+	}
+	machineId := 7
+	cfg.MachineId = &machineId
+	f, err := NewFromConfig(cfg, SetEntropyPolicy(EntropyRetry, nil))
+	if err != nil {
+		t.Errorf("Creating flaker failed: %v", err)
+		return
+	}
+	g := f.(*flaker)
+	if g.machineId != 7 || g.epochStart != time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano() || g.layout.SequenceBits != 27 ||
+		g.mode != ModeRaw || g.entropySource != EntropyChaCha8 || g.clockPolicy != ClockWait ||
+		g.sequencePolicy != SequenceSpin || g.entropyPolicy != EntropyRetry {
+		t.Errorf("Expected the configuration of the file")
+	}
+
+	b, _ := json.Marshal(cfg)
+	var out Config
+	if err := json.Unmarshal(b, &out); err != nil || out.Mode != ModeRaw || out.ClockPolicy != ClockWait || *out.MachineId != 7 {
+		t.Errorf("Expected the config to survive a JSON round trip: %s %v", b, err)
+	}
+
+	for _, content := range []string{`{"machine_id": 1}`, `{"mode": "sorted"}`, `{"clockPolicy": 1}`, `[]`} {
+		_ = os.WriteFile(path, []byte(content), 0600)
+		if _, err := LoadConfig(path); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for %s but got %v", content, err)
+		}
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing file error but got %v", err)
+	}
+
+	large := 256
+	for _, cfg := range []Config{
+		{MachineIdSource: MachineIdStatic},
+		{MachineIdSource: MachineIdFromIP, MachineId: &machineId},
+		{MachineIdSource: "mac"},
+		{MachineId: &large},
+	} {
+		if _, err := NewFromConfig(cfg); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for %+v but got %v", cfg, err)
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	ordinal, err := hostnameOrdinal()
+	if last := hostname[len(hostname)-1]; last >= '0' && last <= '9' {
+		if err != nil {
+			t.Errorf("Expected the ordinal of hostname %q but got %v", hostname, err)
+		}
+	} else if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for hostname %q but got %d: %v", hostname, ordinal, err)
+	}
+}
@@ -0,0 +1,252 @@
+package flake
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// ErrNotSorted is returned when writing IDs in descending order to a
+// DeltaWriter.
+var ErrNotSorted = errors.New("flakes not sorted")
+
+// deltaMagic starts a delta stream, followed by the format version
+const deltaMagic = "FLKD\x01"
+
+// DeltaBlockSize is the count of IDs of a block of a delta stream
+const DeltaBlockSize = 4096
+
+// maxDeltaBlock is the maximum payload length of a block of uvarints
+const maxDeltaBlock = DeltaBlockSize * binary.MaxVarintLen64
+
+// DeltaWriter writes ascending raw IDs as a compact delta stream, e.g. for
+// backups and exports of millions of nearly sequential IDs: blocks of up to
+// DeltaBlockSize IDs, each holding the count, the payload length, the first
+// ID and the differences to the predecessors as uvarints, and a CRC-32 of the
+// payload. Raw IDs of a busy generator compress to 2 to 3 bytes each. Close
+// the writer to flush the last block and to terminate the stream.
+type DeltaWriter struct {
+	w       io.Writer
+	header  bool
+	block   []byte
+	count   int
+	prev    Flake
+	started bool
+	err     error
+}
+
+// NewDeltaWriter returns a DeltaWriter writing to w
+func NewDeltaWriter(w io.Writer) *DeltaWriter {
+	return &DeltaWriter{w: w}
+}
+
+// Write appends the IDs, which must not descend from the previous ones
+func (w *DeltaWriter) Write(ids ...Flake) error {
+	for _, f := range ids {
+		if w.err != nil {
+			return w.err
+		}
+		if f < 0 {
+			return fmt.Errorf("%w: %d", ErrOutOfRange, f)
+		} else if w.started && f < w.prev {
+			return fmt.Errorf("%w: %d after %d", ErrNotSorted, f, w.prev)
+		}
+		if w.count == 0 {
+			w.block = binary.AppendUvarint(w.block, uint64(f))
+		} else {
+			w.block = binary.AppendUvarint(w.block, uint64(f-w.prev))
+		}
+		w.prev, w.started = f, true
+		if w.count++; w.count == DeltaBlockSize {
+			w.err = w.flush()
+		}
+	}
+	return w.err
+}
+
+// Close flushes the last block and writes the end of the stream. It doesn't
+// close the underlying writer.
+func (w *DeltaWriter) Close() error {
+	if w.err == nil && w.count > 0 {
+		w.err = w.flush()
+	}
+	if w.err == nil {
+		w.err = w.write(binary.AppendUvarint(nil, 0))
+	}
+	return w.err
+}
+
+// flush writes the pending block
+func (w *DeltaWriter) flush() error {
+	frame := binary.AppendUvarint(nil, uint64(w.count))
+	frame = binary.AppendUvarint(frame, uint64(len(w.block)))
+	frame = append(frame, w.block...)
+	frame = binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(w.block))
+	w.block, w.count = w.block[:0], 0
+	return w.write(frame)
+}
+
+// write writes b after the header
+func (w *DeltaWriter) write(b []byte) error {
+	if !w.header {
+		w.header = true
+		b = append([]byte(deltaMagic), b...)
+	}
+	_, err := w.w.Write(b)
+	return err
+}
+
+// ----------------------------------------------------------------------------
+
+// DeltaReader reads the IDs of a stream written by DeltaWriter block by block
+type DeltaReader struct {
+	r      *bufio.Reader
+	header bool
+	block  []Flake
+	err    error
+}
+
+// NewDeltaReader returns a DeltaReader reading from r
+func NewDeltaReader(r io.Reader) *DeltaReader {
+	return &DeltaReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next ID or io.EOF at the end of the stream
+func (r *DeltaReader) Next() (Flake, error) {
+	for len(r.block) == 0 {
+		if err := r.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+	f := r.block[0]
+	r.block = r.block[1:]
+	return f, nil
+}
+
+// Read reads up to len(ids) IDs into ids and returns their count, which is
+// zero with io.EOF at the end of the stream
+func (r *DeltaReader) Read(ids []Flake) (int, error) {
+	n := 0
+	for n < len(ids) {
+		if len(r.block) == 0 {
+			if err := r.readBlock(); err != nil {
+				if n > 0 && err == io.EOF {
+					return n, nil
+				}
+				return n, err
+			}
+			continue
+		}
+		c := copy(ids[n:], r.block)
+		r.block = r.block[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// readBlock decodes the next block, io.EOF at the end of the stream
+func (r *DeltaReader) readBlock() error {
+	if r.err != nil {
+		return r.err
+	}
+	r.err = r.decodeBlock()
+	return r.err
+}
+
+// decodeBlock decodes the next block
+func (r *DeltaReader) decodeBlock() error {
+	if !r.header {
+		magic := make([]byte, len(deltaMagic))
+		if _, err := io.ReadFull(r.r, magic); err != nil {
+			return unexpected(err)
+		}
+		if string(magic) != deltaMagic {
+			return fmt.Errorf("%w: no delta stream", ErrInvalidEncoding)
+		}
+		r.header = true
+	}
+	count, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return unexpected(err)
+	} else if count == 0 {
+		return io.EOF
+	}
+	length, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return unexpected(err)
+	} else if count > DeltaBlockSize || length > maxDeltaBlock {
+		return fmt.Errorf("%w: block of %d IDs in %d bytes", ErrInvalidEncoding, count, length)
+	}
+	payload := make([]byte, length+4)
+	if _, err := io.ReadFull(r.r, payload); err != nil {
+		return unexpected(err)
+	}
+	payload, sum := payload[:length], payload[length:]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(sum) {
+		return ErrInvalidChecksum
+	}
+	block := make([]Flake, 0, count)
+	values := bytes.NewReader(payload)
+	var prev uint64
+	for i := uint64(0); i < count; i++ {
+		v, err := binary.ReadUvarint(values)
+		if err != nil {
+			return fmt.Errorf("%w: truncated block", ErrInvalidEncoding)
+		}
+		if i > 0 {
+			v += prev
+		}
+		if v > uint64(MaxFlake) || v < prev {
+			return fmt.Errorf("%w: %d", ErrOutOfRange, v)
+		}
+		block = append(block, Flake(v))
+		prev = v
+	}
+	if values.Len() > 0 {
+		return fmt.Errorf("%w: trailing bytes in block", ErrInvalidEncoding)
+	}
+	r.block = block
+	return nil
+}
+
+// unexpected converts io.EOF within a stream to io.ErrUnexpectedEOF
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// ----------------------------------------------------------------------------
+
+// EncodeDelta returns the ascending IDs as delta stream like DeltaWriter
+func EncodeDelta(ids []Flake) ([]byte, error) {
+	var b bytes.Buffer
+	w := NewDeltaWriter(&b)
+	if err := w.Write(ids...); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// DecodeDelta returns the IDs of a delta stream
+func DecodeDelta(b []byte) ([]Flake, error) {
+	r := NewDeltaReader(bytes.NewReader(b))
+	var ids []Flake
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			return ids, nil
+		} else if err != nil {
+			return nil, err
+		}
+		ids = append(ids, f)
+	}
+}
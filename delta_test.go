@@ -0,0 +1,79 @@
+package flake
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestDelta(t *testing.T) {
+	ids := Raw.NextN(3*DeltaBlockSize + 100)
+	b, err := EncodeDelta(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perID := float64(len(b)) / float64(len(ids)); perID > 4 {
+		t.Errorf("Expected at most 4 bytes per ID but got %.2f", perID)
+	}
+	decoded, err := DecodeDelta(b)
+	if err != nil || !slices.Equal(decoded, ids) {
+		t.Errorf("Expected %d IDs decoded but got %d, %v", len(ids), len(decoded), err)
+	}
+
+	// Streaming reads across blocks
+	r := NewDeltaReader(bytes.NewReader(b))
+	var read []Flake
+	buf := make([]Flake, 1000)
+	for {
+		n, err := r.Read(buf)
+		read = append(read, buf[:n]...)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(read, ids) {
+		t.Errorf("Expected %d IDs read but got %d", len(ids), len(read))
+	}
+
+	if empty, err := EncodeDelta(nil); err != nil || len(empty) != len(deltaMagic)+1 {
+		t.Errorf("Expected an empty stream but got %x, %v", empty, err)
+	} else if decoded, err := DecodeDelta(empty); err != nil || len(decoded) != 0 {
+		t.Errorf("Expected no IDs but got %v, %v", decoded, err)
+	}
+	dups := []Flake{1, 1, 2, MaxFlake}
+	if b, _ := EncodeDelta(dups); !slices.Equal(must(DecodeDelta(b)), dups) {
+		t.Errorf("Expected duplicates and MaxFlake to round-trip")
+	}
+}
+
+func TestDeltaErrors(t *testing.T) {
+	if _, err := EncodeDelta([]Flake{2, 1}); !errors.Is(err, ErrNotSorted) {
+		t.Errorf("Expected unsorted IDs to fail but got %v", err)
+	}
+	if _, err := EncodeDelta([]Flake{-1}); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected negative IDs to fail but got %v", err)
+	}
+	b, _ := EncodeDelta([]Flake{1, 2, 3})
+	if _, err := DecodeDelta(b[:len(b)-1]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected a truncated stream to fail but got %v", err)
+	}
+	corrupted := slices.Clone(b)
+	corrupted[len(deltaMagic)+3]++
+	if _, err := DecodeDelta(corrupted); !errors.Is(err, ErrInvalidChecksum) {
+		t.Errorf("Expected a corrupted block to fail but got %v", err)
+	}
+	if _, err := DecodeDelta([]byte("nope!\x00")); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("Expected a foreign stream to fail but got %v", err)
+	}
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
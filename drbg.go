@@ -0,0 +1,104 @@
+package flake
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	randv2 "math/rand/v2"
+	"sync"
+)
+
+// drbg is the HMAC_DRBG of NIST SP 800-90A with SHA-256 without prediction
+// resistance and reseeding.
+type drbg struct {
+	mutex sync.Mutex
+	mac   hash.Hash // keyed with k
+	k, v  [sha256.Size]byte
+}
+
+// drbgMaxRequest is the maximum count of bytes generated by a request
+const drbgMaxRequest = 1 << 16
+
+// NewDRBG returns the HMAC_DRBG of NIST SP 800-90A with SHA-256 instantiated
+// with the seed, the concatenation of entropy input, nonce and optional
+// personalization string. Seed it with at least 48 bytes from an approved
+// entropy source, e.g. crypto/rand in FIPS mode. The same seed reproduces
+// the same bytes. It's safe for concurrent use.
+func NewDRBG(seed []byte) Entropy {
+	d := &drbg{}
+	for i := range d.v {
+		d.v[i] = 1
+	}
+	d.update(seed)
+	return d
+}
+
+// update updates the key and the value with the provided data
+func (d *drbg) update(data []byte) {
+	for i := byte(0); i == 0 || i == 1 && len(data) > 0; i++ {
+		mac := hmac.New(sha256.New, d.k[:])
+		mac.Write(d.v[:])
+		mac.Write([]byte{i})
+		mac.Write(data)
+		mac.Sum(d.k[:0])
+		d.mac = hmac.New(sha256.New, d.k[:])
+		d.next()
+	}
+}
+
+// next sets the value to its HMAC
+func (d *drbg) next() {
+	d.mac.Reset()
+	d.mac.Write(d.v[:])
+	d.mac.Sum(d.v[:0])
+}
+
+// Read fills p with generated bytes in requests of up to 64 KiB
+func (d *drbg) Read(p []byte) (int, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for request := p; len(request) > 0; {
+		n := min(len(request), drbgMaxRequest)
+		for i := 0; i < n; {
+			d.next()
+			i += copy(request[i:n], d.v[:])
+		}
+		d.update(nil)
+		request = request[n:]
+	}
+	return len(p), nil
+}
+
+// deterministic is a ChaCha8 stream of a fixed seed
+type deterministic struct {
+	mutex  sync.Mutex
+	chacha *randv2.ChaCha8
+	word   [8]byte
+	offset int
+}
+
+// NewDeterministicEntropy returns a reproducible stream of pseudo random
+// bytes of the seed for tests, e.g. golden files containing IDs generated
+// with a manual clock. Never use it in production, the IDs become guessable.
+// It's safe for concurrent use.
+func NewDeterministicEntropy(seed uint64) Entropy {
+	var key [32]byte
+	binary.BigEndian.PutUint64(key[:], seed)
+	return &deterministic{chacha: randv2.NewChaCha8(key), offset: 8}
+}
+
+// Read fills p with the next bytes of the stream
+func (d *deterministic) Read(p []byte) (int, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for i := range p {
+		if d.offset == len(d.word) {
+			binary.LittleEndian.PutUint64(d.word[:], d.chacha.Uint64())
+			d.offset = 0
+		}
+		p[i] = d.word[d.offset]
+		d.offset++
+	}
+	return len(p), nil
+}
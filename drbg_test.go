@@ -0,0 +1,41 @@
+package flake
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDRBG(t *testing.T) {
+	// NIST CAVP HMAC_DRBG SHA-256 without prediction resistance, count 0
+	entropy, _ := hex.DecodeString("ca851911349384bffe89de1cbdc46e6831e44d34a4fb935ee285dd14b71a7488")
+	nonce, _ := hex.DecodeString("659ba96c601dc69fc902940805ec0ca8")
+	expected, _ := hex.DecodeString("e528e9abf2dece54d47c7e75e5fe302149f817ea9fb4bee6f4199697d04d5b89" +
+		"d54fbb978a15b5c443c9ec21036d2460b6f73ebad0dc2aba6e624abf07745bc1" +
+		"07694bb7547bb0995f70de25d6b29e2d3011bb19d27676c07162c8b5ccde0668" +
+		"961df86803482cb37ed6d5c0bb8d50cf1f50d476aa0458bdaba806f48be9dcb8")
+	d := NewDRBG(append(entropy, nonce...))
+	returned := make([]byte, 128)
+	d.Read(returned)
+	d.Read(returned)
+	if !bytes.Equal(returned, expected) {
+		t.Errorf("Expected the NIST test vector but got %x", returned)
+	}
+
+	large := make([]byte, drbgMaxRequest+100)
+	if n, err := NewDRBG(entropy).Read(large); n != len(large) || err != nil || bytes.Equal(large[:100], large[drbgMaxRequest:]) {
+		t.Errorf("Expected to generate %d bytes in several requests", len(large))
+	}
+}
+
+func TestDeterministicEntropy(t *testing.T) {
+	a, b := make([]byte, 64), make([]byte, 64)
+	NewDeterministicEntropy(42).Read(a)
+	NewDeterministicEntropy(42).Read(b)
+	if !bytes.Equal(a, b) {
+		t.Errorf("Expected the same bytes for the same seed")
+	}
+	if NewDeterministicEntropy(43).Read(b); bytes.Equal(a, b) {
+		t.Errorf("Expected other bytes for another seed")
+	}
+}
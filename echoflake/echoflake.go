@@ -0,0 +1,30 @@
+// Package echoflake adapts the request ID middleware of httpflake to Echo.
+//
+//	e.Use(echoflake.RequestID(nil))
+package echoflake
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-flake"
+	"go-flake/httpflake"
+)
+
+// RequestID returns the Echo middleware assigning request IDs as configured,
+// a nil config uses the defaults of httpflake.RequestIDs.
+func RequestID(config *httpflake.RequestIDs) echo.MiddlewareFunc {
+	if config == nil {
+		config = &httpflake.RequestIDs{}
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			_, r := config.Assign(c.Response(), c.Request())
+			c.SetRequest(r)
+			return next(c)
+		}
+	}
+}
+
+// FromContext returns the request ID of the Echo context
+func FromContext(c echo.Context) (flake.Flake, bool) {
+	return httpflake.FromContext(c.Request().Context())
+}
@@ -0,0 +1,35 @@
+package echoflake
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"go-flake"
+	"go-flake/httpflake"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen flake.Flake
+	e := echo.New()
+	e.Use(RequestID(&httpflake.RequestIDs{Format: flake.FormatHex}))
+	e.GET("/", func(c echo.Context) error {
+		seen, _ = FromContext(c)
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if seen == flake.Nil || w.Header().Get(httpflake.HeaderRequestID) != seen.Hex() {
+		t.Errorf("Expected the ID %d in the header but got %q", seen, w.Header().Get(httpflake.HeaderRequestID))
+	}
+
+	incoming := flake.Next()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(httpflake.HeaderRequestID, incoming.Hex())
+	e.ServeHTTP(httptest.NewRecorder(), r)
+	if seen != incoming {
+		t.Errorf("Expected the incoming ID %d but got %d", incoming, seen)
+	}
+}
@@ -0,0 +1,352 @@
+package flake
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Format identifies an encoding of a flake.
+type Format int
+
+const (
+	// FormatUnknown is used when the format couldn't be detected
+	FormatUnknown Format = iota
+	// FormatHex is the 16 characters hex encoding
+	FormatHex
+	// FormatBase32 is the 13 characters base32 (extended hex alphabet) encoding
+	FormatBase32
+	// FormatBase64 is the 11 characters URL safe base64 encoding
+	FormatBase64
+	// FormatBytes is the 8 bytes big endian encoding
+	FormatBytes
+	// FormatBase58 is the 11 characters base58 (bitcoin alphabet) encoding
+	FormatBase58
+	// FormatDecimal is the decimal representation of the int64 value
+	FormatDecimal
+)
+
+// String returns the name of the format
+func (f Format) String() string {
+	switch f {
+	case FormatHex:
+		return "hex"
+	case FormatBase32:
+		return "base32"
+	case FormatBase64:
+		return "base64"
+	case FormatBytes:
+		return "bytes"
+	case FormatBase58:
+		return "base58"
+	case FormatDecimal:
+		return "decimal"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the name of the format
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText sets the format of the given name
+func (f *Format) UnmarshalText(text []byte) error {
+	if string(text) == FormatUnknown.String() {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidConfig, text)
+	}
+	return unmarshalName(f, text, FormatDecimal, "format")
+}
+
+var (
+	// ErrInvalidLength is returned when the input length matches no format.
+	ErrInvalidLength = errors.New("invalid length")
+	// ErrInvalidEncoding is returned when the input contains invalid characters
+	// for the format.
+	ErrInvalidEncoding = errors.New("invalid encoding")
+	// ErrInvalidChecksum is returned when the checksum of an encoding carrying
+	// one doesn't match.
+	ErrInvalidChecksum = errors.New("invalid checksum")
+	// ErrOutOfRange is returned when a parsed number exceeds the 63 bit range.
+	ErrOutOfRange = errors.New("out of range")
+)
+
+// DecodeError describes why decoding an input failed. Err is one of
+// ErrInvalidLength, ErrInvalidEncoding or ErrInvalidChecksum so use errors.Is
+// to branch on the cause.
+type DecodeError struct {
+	Input  string
+	Format Format
+	Err    error
+}
+
+// Error returns the error message
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %s flake %q: %v", e.Format, e.Input, e.Err)
+}
+
+// Unwrap returns the cause
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ----------------------------------------------------------------------------
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() (index [256]byte) {
+	for i := range index {
+		index[i] = 0xff
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		index[base58Alphabet[i]] = byte(i)
+	}
+	return
+}()
+
+// Base58 encodes the flake to base58 padded to 11 characters, so that raw
+// flakes keep their order
+func (f Flake) Base58() string {
+	return string(appendBase58(make([]byte, 0, 11), f))
+}
+
+func appendBase58(dst []byte, f Flake) []byte {
+	dst = append(dst, "11111111111"...)
+	for i, n := len(dst)-1, uint64(f); n > 0; i-- {
+		dst[i] = base58Alphabet[n%58]
+		n /= 58
+	}
+	return dst
+}
+
+func decodeBase58(s string) (Flake, error) {
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		digit := base58Index[s[i]]
+		if digit == 0xff || n > (1<<64-1-uint64(digit))/58 {
+			return 0, ErrInvalidEncoding
+		}
+		n = n*58 + uint64(digit)
+	}
+	if n > 1<<63-1 {
+		return 0, ErrOutOfRange
+	}
+	return Flake(n), nil
+}
+
+// Decimal returns the decimal representation of the flake
+func (f Flake) Decimal() string {
+	return strconv.FormatInt(int64(f), 10)
+}
+
+// Parse parses a flake from its decimal representation or from a 0x prefixed
+// hex number as they frequently appear in logs and URLs. The value must be in
+// the 63 bit range of a flake. Errors are of type *DecodeError.
+func Parse(s string) (Flake, error) {
+	format, n, err := FormatDecimal, uint64(0), error(nil)
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		format = FormatHex
+		n, err = strconv.ParseUint(s[2:], 16, 64)
+	} else {
+		n, err = strconv.ParseUint(s, 10, 64)
+	}
+	if errors.Is(err, strconv.ErrRange) || err == nil && n > 1<<63-1 {
+		return 0, &DecodeError{Input: s, Format: format, Err: ErrOutOfRange}
+	} else if err != nil {
+		return 0, &DecodeError{Input: s, Format: format, Err: ErrInvalidEncoding}
+	}
+	return Flake(n), nil
+}
+
+// ----------------------------------------------------------------------------
+
+// DecodeHex strictly decodes a flake encoded with Hex
+func DecodeHex(s string) (Flake, error) {
+	return DecodeFormat(s, FormatHex)
+}
+
+// DecodeBase32 strictly decodes a flake encoded with Base32
+func DecodeBase32(s string) (Flake, error) {
+	return DecodeFormat(s, FormatBase32)
+}
+
+// DecodeBase64 strictly decodes a flake encoded with Base64
+func DecodeBase64(s string) (Flake, error) {
+	return DecodeFormat(s, FormatBase64)
+}
+
+// DecodeBase58 strictly decodes a flake encoded with Base58
+func DecodeBase58(s string) (Flake, error) {
+	return DecodeFormat(s, FormatBase58)
+}
+
+// DecodeDecimal strictly decodes a flake encoded with Decimal
+func DecodeDecimal(s string) (Flake, error) {
+	return DecodeFormat(s, FormatDecimal)
+}
+
+// DecodeFormat strictly decodes a flake of the specified format. Unlike Decode
+// only the canonical encoding is accepted, e.g. no upper case hex digits.
+// Errors are of type *DecodeError.
+func DecodeFormat(s string, format Format) (flake Flake, err error) {
+	// Decode and re-encode on the stack without allocations
+	var buf [24]byte
+	switch format {
+	case FormatHex, FormatBase32, FormatBase64:
+		if len(s) != encodedLen(format) {
+			err = ErrInvalidLength
+		} else if flake, err = decode(s, buf[:]); err != nil {
+			err = ErrInvalidEncoding
+		}
+	case FormatBase58:
+		if len(s) != 11 {
+			err = ErrInvalidLength
+		} else {
+			flake, err = decodeBase58(s)
+		}
+	case FormatDecimal:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, 64); errors.Is(err, strconv.ErrRange) || err == nil && n < 0 {
+			err = ErrOutOfRange
+		} else if err != nil {
+			err = ErrInvalidEncoding
+		} else {
+			flake = Flake(n)
+		}
+	default:
+		err = ErrInvalidEncoding
+	}
+	if err == nil && string(appendFormat(buf[:0], flake, format)) != s {
+		err = ErrInvalidEncoding
+	}
+	if err != nil {
+		return 0, &DecodeError{Input: s, Format: format, Err: err}
+	}
+	return flake, nil
+}
+
+// decodeBytes converts the result of a byte decoder to a flake
+func decodeBytes(b []byte, err error) (Flake, error) {
+	if err != nil || len(b) != 8 {
+		return 0, ErrInvalidEncoding
+	}
+	return Flake(binary.BigEndian.Uint64(b)), nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Encode encodes the flake to the specified format. Unknown formats result in
+// an empty string.
+func (f Flake) Encode(format Format) string {
+	return string(appendFormat(make([]byte, 0, 20), f, format))
+}
+
+// EncodeAll encodes all flakes to the specified format. All strings share a
+// single buffer instead of allocating each one.
+func EncodeAll(flakes []Flake, format Format) []string {
+	buf := make([]byte, 0, len(flakes)*encodedLen(format))
+	for _, f := range flakes {
+		buf = appendFormat(buf, f, format)
+	}
+	all := string(buf)
+	out := make([]string, len(flakes))
+	var scratch [20]byte
+	for i, f := range flakes {
+		n := encodedLen(format)
+		if format == FormatDecimal {
+			n = len(strconv.AppendInt(scratch[:0], int64(f), 10))
+		}
+		out[i], all = all[:n], all[n:]
+	}
+	return out
+}
+
+// DecodeAll decodes all strings like Decode does. It reuses one buffer for
+// all strings, so the result slice is the only allocation. The returned error
+// wraps the *DecodeError of the first invalid string.
+func DecodeAll(strs []string) ([]Flake, error) {
+	out := make([]Flake, len(strs))
+	var buf [24]byte
+	for i, s := range strs {
+		f, err := decode(s, buf[:])
+		if err != nil {
+			return nil, fmt.Errorf("decoding flake %d: %w", i, err)
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// appendFormat appends the encoded flake to dst
+func appendFormat(dst []byte, f Flake, format Format) []byte {
+	b := f.Array()
+	n := len(dst)
+	switch format {
+	case FormatHex:
+		dst = append(dst, b[:]...)
+		dst = append(dst, b[:]...)
+		hex.Encode(dst[n:], b[:])
+	case FormatBase32:
+		dst = append(dst, "0000000000000"...)
+		base32RawEncoding.Encode(dst[n:], b[:])
+	case FormatBase64:
+		dst = append(dst, "00000000000"...)
+		base64.RawURLEncoding.Encode(dst[n:], b[:])
+	case FormatBase58:
+		dst = appendBase58(dst, f)
+	case FormatDecimal:
+		dst = strconv.AppendInt(dst, int64(f), 10)
+	case FormatBytes:
+		dst = append(dst, b[:]...)
+	}
+	return dst
+}
+
+// encodedLen returns the (maximum) length of an encoded flake
+func encodedLen(format Format) int {
+	switch format {
+	case FormatHex:
+		return 16
+	case FormatBase32:
+		return 13
+	case FormatBase64, FormatBase58:
+		return 11
+	case FormatDecimal:
+		return 20
+	case FormatBytes:
+		return 8
+	}
+	return 0
+}
+
+// base64Strict rejects set padding bits, which most base58 flakes of the same
+// length have
+var base64Strict = base64.RawURLEncoding.Strict()
+
+// decode decodes a hex, base32 or base64 encoded flake detected by the length
+// of s using buf of 24 bytes as scratch space.
+func decode(s string, buf []byte) (Flake, error) {
+	var format Format
+	var err error
+	src, dst := buf[:copy(buf[:16], s)], buf[16:24]
+	switch len(s) {
+	case 11:
+		format = FormatBase64
+		_, err = base64Strict.Decode(dst, src)
+	case 13:
+		format = FormatBase32
+		_, err = base32RawEncoding.Decode(dst, src)
+	case 16:
+		format = FormatHex
+		_, err = hex.Decode(dst, src)
+	default:
+		return 0, &DecodeError{Input: s, Format: FormatUnknown, Err: ErrInvalidLength}
+	}
+	if err != nil {
+		return 0, &DecodeError{Input: s, Format: format, Err: ErrInvalidEncoding}
+	}
+	return Flake(binary.BigEndian.Uint64(dst)), nil
+}
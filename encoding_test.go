@@ -0,0 +1,225 @@
+package flake
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		input  string
+		format Format
+		err    error
+	}{
+		{"", FormatUnknown, ErrInvalidLength},
+		{"1234567890", FormatUnknown, ErrInvalidLength},
+		{"!2345678901", FormatBase64, ErrInvalidEncoding},
+		{"!234567890123", FormatBase32, ErrInvalidEncoding},
+		{"!234567890123456", FormatHex, ErrInvalidEncoding},
+	}
+	for _, test := range tests {
+		_, err := Decode(test.input)
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("Expected a DecodeError for %q but got %v", test.input, err)
+			continue
+		}
+		if !errors.Is(err, test.err) || decodeErr.Format != test.format || decodeErr.Input != test.input {
+			t.Errorf("Expected %v for %s %q but got %v", test.err, test.format, test.input, err)
+		}
+	}
+	if _, err := FromBytes([]byte{1, 2}); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength but got %v", err)
+	}
+}
+
+func TestDecodeFormat(t *testing.T) {
+	for _, in := range []Flake{0, 1, 57, 58, Next(), NextRaw(), 1<<63 - 1, -1} {
+		encodings := map[Format]string{
+			FormatHex:    in.Hex(),
+			FormatBase32: in.Base32(),
+			FormatBase64: in.Base64(),
+			FormatBase58: in.Base58(),
+		}
+		if in < 0 {
+			delete(encodings, FormatBase58)
+		}
+		for format, s := range encodings {
+			if out, err := DecodeFormat(s, format); err != nil || out != in {
+				t.Errorf("Decoding of %s value %q failed for input %d with output %d: %v", format, s, in, out, err)
+			}
+		}
+	}
+	if out, err := DecodeBase58(MaxFlake.Base58()); err != nil || out != MaxFlake {
+		t.Errorf("Decoding of max base58 value failed with output %d: %v", out, err)
+	}
+	for _, s := range []string{"jpXCZedGfVQ", Flake(-1 << 63).Base58()} {
+		if _, err := DecodeBase58(s); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("Expected ErrOutOfRange for base58 %q beyond 63 bits but got %v", s, err)
+		}
+	}
+	if out, err := Decode("DSAWKqL2Nxu"); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("Expected the base58 flake to be no base64 flake but got %d (%v)", out, err)
+	}
+
+	tests := []struct {
+		input  string
+		format Format
+		err    error
+	}{
+		{"0123456789ABCDEF", FormatHex, ErrInvalidEncoding},
+		{"0123456789abcde", FormatHex, ErrInvalidLength},
+		{"80O40G20E0003", FormatBase32, ErrInvalidEncoding},
+		{"80o40g20e0002", FormatBase32, ErrInvalidEncoding},
+		{"QDBAQEBwAAF", FormatBase64, ErrInvalidEncoding},
+		{"QDBAQEBwAAE", FormatHex, ErrInvalidLength},
+		{"jpXCZedGfVR", FormatBase58, ErrInvalidEncoding},
+		{"0000000000O", FormatBase58, ErrInvalidEncoding},
+		{"11111111111", FormatUnknown, ErrInvalidEncoding},
+	}
+	for _, test := range tests {
+		if _, err := DecodeFormat(test.input, test.format); !errors.Is(err, test.err) {
+			t.Errorf("Expected %v for %s %q but got %v", test.err, test.format, test.input, err)
+		}
+	}
+	if _, err := DecodeHex("0123456789abcdef"); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+	if _, err := DecodeBase32("80O40G20E0002"); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+	if _, err := DecodeBase64("QDBAQEBwAAE"); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+	for _, format := range benchmarkFormats {
+		s := NextRaw().Encode(format)
+		if allocs := testing.AllocsPerRun(10, func() { _, _ = DecodeFormat(s, format) }); allocs != 0 {
+			t.Errorf("Expected no allocations decoding %s but got %.0f", format, allocs)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	id := Next()
+	tests := []struct {
+		input string
+		out   Flake
+		err   error
+	}{
+		{id.Decimal(), id, nil},
+		{"0x" + id.Hex(), id, nil},
+		{"0XFF", 255, nil},
+		{"0", 0, nil},
+		{"9223372036854775807", 1<<63 - 1, nil},
+		{"9223372036854775808", 0, ErrOutOfRange},
+		{"99999999999999999999", 0, ErrOutOfRange},
+		{"0x8000000000000000", 0, ErrOutOfRange},
+		{"-1", 0, ErrInvalidEncoding},
+		{"0x", 0, ErrInvalidEncoding},
+		{"12a", 0, ErrInvalidEncoding},
+		{"", 0, ErrInvalidEncoding},
+	}
+	for _, test := range tests {
+		if out, err := Parse(test.input); !errors.Is(err, test.err) || out != test.out {
+			t.Errorf("Parsing %q expected %d (%v) but got %d (%v)", test.input, test.out, test.err, out, err)
+		}
+	}
+
+	if out, err := DecodeDecimal(id.Decimal()); err != nil || out != id {
+		t.Errorf("Decoding of decimal value failed for input %d with output %d: %v", id, out, err)
+	}
+	for _, s := range []string{"+1", "01", "-0"} {
+		if _, err := DecodeDecimal(s); !errors.Is(err, ErrInvalidEncoding) {
+			t.Errorf("Expected ErrInvalidEncoding for %q but got %v", s, err)
+		}
+	}
+	if _, err := DecodeDecimal("-1"); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected ErrOutOfRange but got %v", err)
+	}
+}
+
+func TestEncodeAll(t *testing.T) {
+	in := []Flake{0, 1, 12345, Next(), NextRaw(), 1<<63 - 1}
+	for _, format := range []Format{FormatHex, FormatBase32, FormatBase64, FormatBase58, FormatDecimal, FormatBytes} {
+		out := EncodeAll(in, format)
+		for i, f := range in {
+			if s := f.Encode(format); out[i] != s {
+				t.Errorf("Expected %s encoding %q of %d but got %q", format, s, f, out[i])
+			}
+			if decoded, err := DecodeFormat(out[i], format); format != FormatBytes && (err != nil || decoded != f) {
+				t.Errorf("Decoding of %s value %q failed for input %d with output %d: %v", format, out[i], f, decoded, err)
+			}
+		}
+	}
+	if s := Flake(1).Encode(FormatUnknown); s != "" {
+		t.Errorf("Expected an empty string for an unknown format but got %q", s)
+	}
+}
+
+func TestDecodeAll(t *testing.T) {
+	in := []Flake{Next(), Next(), Next()}
+	strs := []string{in[0].Hex(), in[1].Base32(), in[2].Base64()}
+	out, err := DecodeAll(strs)
+	if err != nil {
+		t.Errorf("Decoding failed: %v", err)
+	}
+	for i := range in {
+		if out[i] != in[i] {
+			t.Errorf("Decoding of %q failed for input %d with output %d", strs[i], in[i], out[i])
+		}
+	}
+	if allocs := testing.AllocsPerRun(10, func() { _, _ = DecodeAll(strs) }); allocs > 1 {
+		t.Errorf("Expected a single allocation but got %.0f", allocs)
+	}
+
+	strs[1] = "invalid"
+	var decodeErr *DecodeError
+	if _, err := DecodeAll(strs); !errors.As(err, &decodeErr) || decodeErr.Input != "invalid" {
+		t.Errorf("Expected a DecodeError for the invalid input but got %v", err)
+	}
+}
+
+var encoded string
+
+var benchmarkFormats = []Format{FormatHex, FormatBase32, FormatBase64, FormatBase58, FormatDecimal}
+
+func BenchmarkEncode(b *testing.B) {
+	f := Next()
+	for _, format := range benchmarkFormats {
+		b.Run(format.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				encoded = f.Encode(format)
+			}
+		})
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	f := Next()
+	for _, format := range benchmarkFormats {
+		s := f.Encode(format)
+		b.Run(format.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = DecodeFormat(s, format)
+			}
+		})
+	}
+}
+
+func TestFormatText(t *testing.T) {
+	for format := FormatHex; format <= FormatDecimal; format++ {
+		text, _ := format.MarshalText()
+		var parsed Format
+		if err := parsed.UnmarshalText(text); err != nil || parsed != format {
+			t.Errorf("Expected format %s but got %s: %v", format, parsed, err)
+		}
+	}
+	var format Format
+	for _, name := range []string{"unknown", "base16", ""} {
+		if err := format.UnmarshalText([]byte(name)); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for %q but got %v", name, err)
+		}
+	}
+}
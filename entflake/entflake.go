@@ -0,0 +1,39 @@
+// Package entflake declares flake ID fields of ent schemas.
+//
+//	func (User) Mixin() []ent.Mixin {
+//		return []ent.Mixin{entflake.Mixin{}}
+//	}
+package entflake
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/mixin"
+	"go-flake"
+)
+
+// Mixin declares the immutable id field of a schema as flake.Flake populated
+// with a new flake on create.
+type Mixin struct {
+	mixin.Schema
+	// Flaker generates the IDs, the installed flake.Default if nil
+	Flaker flake.Flaker
+}
+
+// Fields returns the id field
+func (m Mixin) Fields() []ent.Field {
+	return []ent.Field{Field("id", m.Flaker)}
+}
+
+// Field returns an immutable flake field of the name populated by the flaker,
+// the installed flake.Default if nil, e.g. for flake fields besides the id.
+func Field(name string, flaker flake.Flaker) ent.Field {
+	next := flake.Next
+	if flaker != nil {
+		next = flaker.Next
+	}
+	return field.Int64(name).
+		GoType(flake.Flake(0)).
+		DefaultFunc(next).
+		Immutable()
+}
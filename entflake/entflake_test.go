@@ -0,0 +1,34 @@
+package entflake
+
+import (
+	"reflect"
+	"testing"
+
+	"go-flake"
+)
+
+func TestMixin(t *testing.T) {
+	fields := Mixin{Flaker: flake.RawWithMachineId(5)}.Fields()
+	if len(fields) != 1 {
+		t.Fatalf("Expected the id field but got %d fields", len(fields))
+	}
+	d := fields[0].Descriptor()
+	if d.Err != nil {
+		t.Fatalf("Expected a valid field but got %v", d.Err)
+	}
+	if d.Name != "id" || !d.Immutable || d.Info.RType.Name != "Flake" || d.Info.RType.PkgPath != "go-flake" {
+		t.Errorf("Expected an immutable flake id field but got %+v", d)
+	}
+	next, ok := d.Default.(func() flake.Flake)
+	if !ok {
+		t.Fatalf("Expected a default func but got %T", d.Default)
+	}
+	if first, second := next(), next(); first&0xff != 5 || second <= first {
+		t.Errorf("Expected sortable IDs of machine-id 5 but got %d and %d", first, second)
+	}
+
+	d = Field("ref", nil).Descriptor()
+	if d.Name != "ref" || reflect.TypeOf(d.Default).Out(0) != reflect.TypeOf(flake.Flake(0)) {
+		t.Errorf("Expected a flake field named ref but got %+v", d)
+	}
+}
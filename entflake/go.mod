@@ -0,0 +1,9 @@
+module go-flake/entflake
+
+go 1.24
+
+require go-flake v0.0.0
+
+require entgo.io/ent v0.14.6
+
+replace go-flake => ../
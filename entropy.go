@@ -0,0 +1,292 @@
+package flake
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	randv2 "math/rand/v2"
+	"sync"
+	"time"
+)
+
+// EntropyPolicy defines how a Flaker reacts when reading random bytes from
+// crypto/rand fails. The random bytes don't contribute to the uniqueness of
+// the IDs but make them hard to guess.
+type EntropyPolicy int
+
+const (
+	// EntropyError lets NextErr() fail with ErrEntropy. Next() continues with
+	// zeroed random bytes since it never fails. This is the default policy.
+	EntropyError EntropyPolicy = iota
+	// EntropyRetry retries reading random bytes before failing like
+	// EntropyError.
+	EntropyRetry
+	// EntropyFallback continues with random bytes of a pseudo random number
+	// generator seeded by the time.
+	EntropyFallback
+)
+
+// EntropySource selects where a Flaker draws the random bits of its IDs from.
+type EntropySource int
+
+const (
+	// EntropyCrypto reads from crypto/rand in pooled chunks. This is the
+	// default source.
+	EntropyCrypto EntropySource = iota
+	// EntropyChaCha8 draws from in-process ChaCha8 generators seeded from
+	// crypto/rand. It's a CSPRNG as well but avoids the system calls of
+	// crypto/rand for throughput beyond what the pooled reads provide.
+	EntropyChaCha8
+)
+
+// Entropy is a source of random bytes like crypto/rand.Reader. Read fills p
+// completely or fails. The generators serialize their reads of a source, it
+// doesn't need to be safe for concurrent use unless shared otherwise.
+type Entropy interface {
+	Read(p []byte) (n int, err error)
+}
+
+// CryptoEntropy reads from crypto/rand, which uses the FIPS 140-3 validated
+// module of the Go runtime when running with GODEBUG=fips140=on.
+var CryptoEntropy Entropy = rand.Reader
+
+// entropyRetries is the count of retries with the EntropyRetry policy
+const entropyRetries = 3
+
+// String returns the name of the policy
+func (p EntropyPolicy) String() string {
+	switch p {
+	case EntropyError:
+		return "error"
+	case EntropyRetry:
+		return "retry"
+	case EntropyFallback:
+		return "fallback"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the name of the entropy policy
+func (p EntropyPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText sets the entropy policy of the given name
+func (p *EntropyPolicy) UnmarshalText(text []byte) error {
+	return unmarshalName(p, text, EntropyFallback, "entropy policy")
+}
+
+// String returns the name of the source
+func (s EntropySource) String() string {
+	switch s {
+	case EntropyCrypto:
+		return "crypto"
+	case EntropyChaCha8:
+		return "chacha8"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the name of the entropy source
+func (s EntropySource) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText sets the entropy source of the given name
+func (s *EntropySource) UnmarshalText(text []byte) error {
+	return unmarshalName(s, text, EntropyChaCha8, "entropy source")
+}
+
+// Returns a new Flaker instance copy with the specified entropy policy set.
+// The policy defines how the generator reacts when reading random bytes
+// fails. The optional onError callback is invoked with each failure, e.g. to
+// count them.
+func (g *flaker) WithEntropyPolicy(policy EntropyPolicy, onError func(err error)) Flaker {
+	c := g.derive()
+	c.entropyPolicy = policy
+	c.onEntropyError = onError
+	return c
+}
+
+// Returns a new Flaker instance copy drawing the random bits of its IDs from
+// the specified source. Failures to seed the ChaCha8 generators are handled
+// according to the entropy policy like failing reads of crypto/rand.
+func (g *flaker) WithEntropySource(source EntropySource) Flaker {
+	c := g.derive()
+	c.entropySource = source
+	return c
+}
+
+// Returns a new Flaker instance copy drawing the random bits of its IDs from
+// the specified entropy instead of its entropy source, e.g. a DRBG of NewDRBG
+// for FIPS constraints or NewDeterministicEntropy for reproducible tests. The
+// entropy is read in chunks serialized by a mutex, which its copies share.
+// Failing reads are handled according to the entropy policy. A nil entropy
+// restores the entropy source.
+func (g *flaker) WithEntropy(entropy Entropy) Flaker {
+	c := g.derive()
+	c.entropy = newEntropyReader(entropy)
+	return c
+}
+
+// random returns n <= 4 random bytes as int32 handling failures according to
+// the entropy policy.
+func (g *flaker) random(n int) (int32, error) {
+	read := g.reader()
+	r, err := read(n)
+	g.counters.entropyReads.Add(1)
+	for i := 0; err != nil; i++ {
+		if g.onEntropyError != nil {
+			g.onEntropyError(err)
+		}
+		g.fire(EventEntropyError, 0, err)
+		if g.entropyPolicy == EntropyFallback {
+			return fallbackRandom(n), nil
+		} else if g.entropyPolicy != EntropyRetry || i == entropyRetries {
+			break
+		}
+		r, err = read(n)
+		g.counters.entropyReads.Add(1)
+	}
+	return r, err
+}
+
+// reader returns the function reading n <= 4 random bytes of the entropy or
+// the entropy source
+func (g *flaker) reader() func(n int) (int32, error) {
+	if g.entropy != nil {
+		return g.entropy.read
+	} else if g.entropySource == EntropyChaCha8 {
+		return readChaCha8
+	}
+	return readRandom
+}
+
+// generateRandom fills ids with 63 random bits each for ModeRandom
+func (g *flaker) generateRandom(fallible bool, ids []Flake) error {
+	for i := range ids {
+		for ids[i] == Nil {
+			high, err := g.random(4)
+			if err == nil {
+				var low int32
+				low, err = g.random(4)
+				ids[i] = Flake(int64(high)<<32|int64(uint32(low))) & MaxFlake
+			}
+			if err != nil && fallible {
+				return fmt.Errorf("%w: %v", ErrEntropy, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+// randReader is the source of random bytes
+var randReader = rand.Reader
+
+// entropyChunk is the count of random bytes read from randReader at once
+const entropyChunk = 512
+
+// entropyBuffer holds random bytes read ahead from a reader
+type entropyBuffer struct {
+	reader io.Reader
+	bytes  [entropyChunk]byte
+	offset int
+}
+
+// entropyPool keeps the read ahead buffers. Getting a buffer from a sync.Pool
+// is lock-free and mostly served from a per-processor cache.
+var entropyPool sync.Pool
+
+// readRandom returns n <= 4 random bytes as int32. The bytes are served from
+// a pooled buffer refilled in chunks, so the underlying reader is only called
+// once per entropyChunk bytes. Each byte is served once.
+func readRandom(n int) (int32, error) {
+	buf, _ := entropyPool.Get().(*entropyBuffer)
+	if buf == nil || buf.reader != randReader {
+		buf = &entropyBuffer{reader: randReader, offset: entropyChunk}
+	}
+	if buf.offset+n > entropyChunk {
+		if _, err := io.ReadFull(buf.reader, buf.bytes[:]); err != nil {
+			return 0, err
+		}
+		buf.offset = 0
+	}
+	var r int32
+	for _, b := range buf.bytes[buf.offset : buf.offset+n] {
+		r = r<<8 | int32(b)
+	}
+	buf.offset += n
+	entropyPool.Put(buf)
+	return r, nil
+}
+
+// entropyReader serves the random bytes of an Entropy read ahead in chunks
+type entropyReader struct {
+	mutex  sync.Mutex
+	source Entropy
+	bytes  [entropyChunk]byte
+	offset int
+}
+
+// newEntropyReader returns the reader of the entropy, nil for a nil entropy
+func newEntropyReader(entropy Entropy) *entropyReader {
+	if entropy == nil {
+		return nil
+	}
+	return &entropyReader{source: entropy, offset: entropyChunk}
+}
+
+// read returns n <= 4 random bytes as int32. Bytes read by a failing read
+// are discarded.
+func (e *entropyReader) read(n int) (int32, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.offset+n > entropyChunk {
+		if _, err := io.ReadFull(e.source, e.bytes[:]); err != nil {
+			return 0, err
+		}
+		e.offset = 0
+	}
+	var r int32
+	for _, b := range e.bytes[e.offset : e.offset+n] {
+		r = r<<8 | int32(b)
+	}
+	e.offset += n
+	return r, nil
+}
+
+// chacha8Pool keeps the seeded ChaCha8 generators, which aren't safe for
+// concurrent use on their own.
+var chacha8Pool sync.Pool
+
+// readChaCha8 returns n <= 4 random bytes as int32 drawn from a pooled
+// ChaCha8 generator. New generators are seeded from randReader.
+func readChaCha8(n int) (int32, error) {
+	c, _ := chacha8Pool.Get().(*randv2.ChaCha8)
+	if c == nil {
+		var seed [32]byte
+		if _, err := io.ReadFull(randReader, seed[:]); err != nil {
+			return 0, err
+		}
+		c = randv2.NewChaCha8(seed)
+	}
+	r := int32(c.Uint64() >> (64 - 8*n))
+	chacha8Pool.Put(c)
+	return r, nil
+}
+
+var fallback = struct {
+	sync.Mutex
+	*mathrand.Rand
+}{Rand: mathrand.New(mathrand.NewSource(time.Now().UnixNano()))}
+
+// fallbackRandom returns n <= 4 pseudo random bytes as int32
+func fallbackRandom(n int) int32 {
+	fallback.Lock()
+	r := fallback.Uint32()
+	fallback.Unlock()
+	return int32(r >> (32 - 8*n))
+}
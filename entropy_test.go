@@ -0,0 +1,172 @@
+package flake
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyReader fails the given count of reads before reading from crypto/rand
+type flakyReader struct {
+	failures int
+}
+
+func (r *flakyReader) Read(b []byte) (int, error) {
+	if r.failures > 0 {
+		r.failures--
+		return 0, errors.New("no entropy")
+	}
+	return rand.Read(b)
+}
+
+func TestEntropyPolicy(t *testing.T) {
+	defer func() { randReader = rand.Reader }()
+	clock := &manualClock{now: time.Now().Add(time.Hour)}
+	var failures int
+	onError := func(err error) {
+		failures++
+	}
+
+	randReader = &flakyReader{failures: 1}
+	f := WithClock(clock).WithEntropyPolicy(EntropyError, onError)
+	if _, err := f.NextErr(); !errors.Is(err, ErrEntropy) {
+		t.Errorf("Expected ErrEntropy but got %v", err)
+	}
+
+	randReader = &flakyReader{failures: 2}
+	f = f.WithEntropyPolicy(EntropyRetry, onError)
+	if _, err := f.NextErr(); err != nil {
+		t.Errorf("Expected retry to succeed but got %v", err)
+	}
+	randReader = &flakyReader{failures: entropyRetries + 1}
+	if _, err := f.NextErr(); !errors.Is(err, ErrEntropy) {
+		t.Errorf("Expected ErrEntropy after %d retries but got %v", entropyRetries, err)
+	}
+
+	randReader = failingReader{}
+	f = f.WithEntropyPolicy(EntropyFallback, onError)
+	m := make(map[Flake]int)
+	generate(t, f, m, 100)
+	if _, err := f.NextErr(); err != nil {
+		t.Errorf("Expected fallback to succeed but got %v", err)
+	}
+
+	if expected := 1 + 2 + entropyRetries + 1 + 101; failures != expected {
+		t.Errorf("Expected %d failure callbacks but got %d", expected, failures)
+	}
+}
+
+// countingReader counts the reads from crypto/rand and records their sizes
+type countingReader struct {
+	reads int
+	sizes map[int]int
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	r.reads++
+	r.sizes[len(b)]++
+	return rand.Read(b)
+}
+
+func TestReadRandom(t *testing.T) {
+	defer func() { randReader = rand.Reader }()
+	reader := &countingReader{sizes: make(map[int]int)}
+	randReader = reader
+	seen := make(map[int32]bool)
+	for i := 0; i < 1000; i++ {
+		r, err := readRandom(2)
+		if err != nil || r < 0 || r > 0xffff {
+			t.Errorf("Unexpected random value %d: %v", r, err)
+			return
+		}
+		seen[r] = true
+	}
+	// The race detector drops pooled buffers at random, so only the chunk
+	// size of the reads is deterministic, not their count
+	if reader.sizes[entropyChunk] != reader.reads {
+		t.Errorf("Expected reads in chunks of %d bytes but got sizes %v", entropyChunk, reader.sizes)
+	}
+	if len(seen) < 900 {
+		t.Errorf("Expected random values but got only %d distinct ones", len(seen))
+	}
+
+	// Without a pool each byte of a chunk is served once
+	buffered := &countingReader{sizes: make(map[int]int)}
+	e := newEntropyReader(buffered)
+	for i := 0; i < 1000; i++ {
+		if _, err := e.read(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buffered.reads != (2000+entropyChunk-1)/entropyChunk {
+		t.Errorf("Expected %d reads of the 2000 bytes but got %d", (2000+entropyChunk-1)/entropyChunk, buffered.reads)
+	}
+}
+
+func BenchmarkReadRandom(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = readRandom(1)
+		}
+	})
+}
+
+func TestEntropySource(t *testing.T) {
+	clock := &manualClock{now: time.Now().Add(time.Hour)}
+	f := Raw.WithClock(clock).WithEntropySource(EntropyChaCha8)
+	seen := make(map[int64]bool)
+	for _, id := range f.NextN(100) {
+		seen[int64(id)>>machineIdBits&0xffff] = true
+	}
+	if len(seen) < 90 {
+		t.Errorf("Expected random sequence bits but got only %d distinct values", len(seen))
+	}
+	m := make(map[Flake]int)
+	generate(t, f, m, 10000)
+
+	if s := EntropyChaCha8.String(); s != "chacha8" {
+		t.Errorf("Unexpected source name %q", s)
+	}
+	if s := EntropySource(42).String(); s != "unknown" {
+		t.Errorf("Unexpected source name %q", s)
+	}
+}
+
+func BenchmarkReadChaCha8(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = readChaCha8(1)
+		}
+	})
+}
+
+func TestWithEntropy(t *testing.T) {
+	clock := &manualClock{now: time.Now()}
+	f := WithClock(clock).WithMode(ModeRaw)
+	a := f.Clone().WithEntropy(NewDeterministicEntropy(1)).NextN(100)
+	b := f.Clone().WithEntropy(NewDeterministicEntropy(1)).NextN(100)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("Expected reproducible IDs of the same clock and entropy but got %d and %d", a[i], b[i])
+			return
+		}
+	}
+
+	defer func() { randReader = rand.Reader }()
+	randReader = failingReader{}
+	if _, err := f.WithEntropy(NewDRBG([]byte("seed"))).NextErr(); err != nil {
+		t.Errorf("Expected the DRBG to replace crypto/rand but got %v", err)
+	}
+	if _, err := f.WithEntropy(failingReader{}).NextErr(); !errors.Is(err, ErrEntropy) {
+		t.Errorf("Expected ErrEntropy of a failing entropy but got %v", err)
+	}
+	g, _ := New(SetEntropy(CryptoEntropy))
+	if _, err := g.WithEntropy(nil).NextErr(); !errors.Is(err, ErrEntropy) {
+		t.Errorf("Expected a nil entropy to restore the entropy source but got %v", err)
+	}
+	randReader = rand.Reader
+	if _, err := g.NextErr(); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+}
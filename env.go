@@ -0,0 +1,114 @@
+package flake
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by NewFromEnv
+const (
+	// EnvMachineId is the static machine-id
+	EnvMachineId = "FLAKE_MACHINE_ID"
+	// EnvMachineIdSource is the source of the machine-id: static, ip or hostname
+	EnvMachineIdSource = "FLAKE_MACHINE_ID_SOURCE"
+	// EnvEpochStart is the epoch start as RFC 3339 time or date, e.g. 2020-01-01
+	EnvEpochStart = "FLAKE_EPOCH_START"
+	// EnvMode is the mode: shuffled, raw or random
+	EnvMode = "FLAKE_MODE"
+	// EnvEntropy is the entropy source: crypto or chacha8
+	EnvEntropy = "FLAKE_ENTROPY"
+	// EnvStateFile is the path of the file to persist the state to
+	EnvStateFile = "FLAKE_STATE_FILE"
+	// EnvClockPolicy is the clock policy: borrow, wait or error
+	EnvClockPolicy = "FLAKE_CLOCK_POLICY"
+	// EnvEpochPolicy is the epoch policy: wrap, error or panic
+	EnvEpochPolicy = "FLAKE_EPOCH_POLICY"
+	// EnvSequencePolicy is the sequence policy: borrow, spin or error
+	EnvSequencePolicy = "FLAKE_SEQUENCE_POLICY"
+	// EnvEntropyPolicy is the entropy policy: error, retry or fallback
+	EnvEntropyPolicy = "FLAKE_ENTROPY_POLICY"
+)
+
+// NewFromEnv returns a new Flaker configured by the FLAKE_* environment
+// variables, the 12-factor way to configure the generator in containers.
+// Unset or empty variables keep the defaults of New or of the passed options;
+// the policy variables keep the callbacks of the passed options.
+// Malformed values are reported as errors wrapping ErrInvalidConfig instead
+// of being ignored.
+func NewFromEnv(opts ...Option) (Flaker, error) {
+	cfg, err := envConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewFromConfig(cfg, opts...)
+}
+
+// envConfig returns the Config of the environment variables
+func envConfig() (cfg Config, err error) {
+	get := os.Getenv
+	invalid := func(key string) error {
+		return fmt.Errorf("%w: %s=%q", ErrInvalidConfig, key, get(key))
+	}
+
+	cfg.MachineIdSource = MachineIdSource(get(EnvMachineIdSource))
+	if value := get(EnvMachineId); value != "" {
+		machineId, err := strconv.Atoi(value)
+		if err != nil {
+			return cfg, invalid(EnvMachineId)
+		}
+		cfg.MachineId = &machineId
+	}
+	if value := get(EnvEpochStart); value != "" {
+		start, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if start, err = time.Parse("2006-01-02", value); err != nil {
+				return cfg, invalid(EnvEpochStart)
+			}
+		}
+		cfg.EpochStart = &start
+	}
+	cfg.StateFile = get(EnvStateFile)
+	for key, value := range map[string]interface {
+		UnmarshalText(text []byte) error
+	}{
+		EnvMode:           &cfg.Mode,
+		EnvEntropy:        &cfg.Entropy,
+		EnvClockPolicy:    &cfg.ClockPolicy,
+		EnvEpochPolicy:    &cfg.EpochPolicy,
+		EnvSequencePolicy: &cfg.SequencePolicy,
+		EnvEntropyPolicy:  &cfg.EntropyPolicy,
+	} {
+		if text := get(key); text != "" && value.UnmarshalText([]byte(text)) != nil {
+			return cfg, invalid(key)
+		}
+	}
+	return cfg, nil
+}
+
+// named is an enumeration with names
+type named interface {
+	~int
+	String() string
+}
+
+// parseName returns the value of the enumeration 0..last with the given name
+func parseName[T named](name string, last T) (T, bool) {
+	for value := T(0); value <= last; value++ {
+		if value.String() == name {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// unmarshalName sets value to the enumeration 0..last named text
+func unmarshalName[T named](value *T, text []byte, last T, kind string) error {
+	v, ok := parseName(string(text), last)
+	if !ok {
+		return fmt.Errorf("%w: unknown %s %q", ErrInvalidConfig, kind, text)
+	}
+	*value = v
+	return nil
+}
@@ -0,0 +1,60 @@
+package flake
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flake.state")
+	t.Setenv(EnvMachineId, "42")
+	t.Setenv(EnvEpochStart, "2024-01-01")
+	t.Setenv(EnvMode, "raw")
+	t.Setenv(EnvEntropy, "chacha8")
+	t.Setenv(EnvStateFile, path)
+	t.Setenv(EnvClockPolicy, "wait")
+	t.Setenv(EnvEpochPolicy, "error")
+	t.Setenv(EnvSequencePolicy, "spin")
+	t.Setenv(EnvEntropyPolicy, "fallback")
+
+	var lags int
+	f, err := NewFromEnv(SetClockPolicy(ClockError, func(time.Duration) { lags++ }))
+	if err != nil {
+		t.Errorf("Creating flaker failed: %v", err)
+		return
+	}
+	g := f.(*flaker)
+	if g.machineId != 42 || g.epochStart != time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano() || g.mode != ModeRaw ||
+		g.entropySource != EntropyChaCha8 || g.store != FileStore(path) || g.clockPolicy != ClockWait ||
+		g.epochPolicy != EpochError || g.sequencePolicy != SequenceSpin || g.entropyPolicy != EntropyFallback {
+		t.Errorf("Expected the configuration of the environment")
+	}
+	if g.onRegression == nil {
+		t.Errorf("Expected the callback of the options to be kept")
+	}
+
+	t.Setenv(EnvEpochStart, "2024-01-01T12:00:00+02:00")
+	if f, err := NewFromEnv(); err != nil || f.(*flaker).epochStart != time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC).UnixNano() {
+		t.Errorf("Expected the RFC 3339 epoch start to be set: %v", err)
+	}
+
+	for key, value := range map[string]string{
+		EnvMachineId:      "256",
+		EnvEpochStart:     "yesterday",
+		EnvMode:           "sorted",
+		EnvEntropy:        "dice",
+		EnvClockPolicy:    "ignore",
+		EnvEpochPolicy:    "unknown",
+		EnvSequencePolicy: "3",
+		EnvEntropyPolicy:  "Retry",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := NewFromEnv(); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Expected ErrInvalidConfig for %s=%q but got %v", key, value, err)
+			}
+		})
+	}
+}
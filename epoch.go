@@ -0,0 +1,111 @@
+package flake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EpochPolicy defines how a Flaker reacts when the time is outside of its
+// epoch, either because the epoch ended or because the epoch start is in the
+// future.
+type EpochPolicy int
+
+const (
+	// EpochWrap silently wraps the interval into the next epoch. IDs are not
+	// guarantied unique to the ones of the previous epoch anymore. This is the
+	// default policy.
+	EpochWrap EpochPolicy = iota
+	// EpochError lets NextErr() fail with ErrEpochOverflow. Next() falls back
+	// to EpochWrap since it never fails.
+	EpochError
+	// EpochPanic panics with ErrEpochOverflow.
+	EpochPanic
+)
+
+// ErrEpochOverflow is returned when the time is outside of the epoch.
+var ErrEpochOverflow = errors.New("time outside of flake epoch")
+
+// String returns the name of the policy
+func (p EpochPolicy) String() string {
+	switch p {
+	case EpochWrap:
+		return "wrap"
+	case EpochError:
+		return "error"
+	case EpochPanic:
+		return "panic"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the name of the epoch policy
+func (p EpochPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText sets the epoch policy of the given name
+func (p *EpochPolicy) UnmarshalText(text []byte) error {
+	return unmarshalName(p, text, EpochPanic, "epoch policy")
+}
+
+// EpochEnd returns the time the current epoch ends and the interval wraps.
+func (g *flaker) EpochEnd() time.Time {
+	return time.Unix(0, g.epochEnd(g.now()))
+}
+
+// RemainingEpoch returns the time left until the current epoch ends.
+func (g *flaker) RemainingEpoch() time.Duration {
+	return g.remainingEpoch(g.now())
+}
+
+// Returns a new Flaker instance copy invoking onWarning with the remaining
+// time of the epoch on each new interval as soon as less than the specified
+// margin is left. Use it to get alerted in time to migrate to a new epoch.
+func (g *flaker) WithEpochWarning(margin time.Duration, onWarning func(remaining time.Duration)) Flaker {
+	c := g.derive()
+	c.epochMargin = margin
+	c.onEpochWarning = onWarning
+	return c
+}
+
+// Returns a new Flaker instance copy with the specified epoch policy set. The
+// policy defines how the generator reacts when the time is outside of its
+// epoch. The optional onOverflow callback is invoked with the elapsed time
+// since the epoch start each time this is detected.
+func (g *flaker) WithEpochPolicy(policy EpochPolicy, onOverflow func(elapsed time.Duration)) Flaker {
+	c := g.derive()
+	c.epochPolicy = policy
+	c.onEpochOverflow = onOverflow
+	return c
+}
+
+// overflow handles a time outside of the epoch according to the policy.
+func (g *flaker) overflow(elapsed time.Duration, fallible bool) error {
+	if g.onEpochOverflow != nil {
+		g.onEpochOverflow(elapsed)
+	}
+	g.fire(EventEpochOverflow, elapsed, nil)
+	switch {
+	case g.epochPolicy == EpochPanic:
+		panic(fmt.Errorf("%w: %s elapsed since epoch start", ErrEpochOverflow, elapsed))
+	case g.epochPolicy == EpochError && fallible:
+		return ErrEpochOverflow
+	}
+	return nil
+}
+
+// epochEnd returns the end of the epoch containing now in nanoseconds since
+// 1/1/1970.
+func (g *flaker) epochEnd(now int64) int64 {
+	length := int64(g.layout.EpochLength())
+	end := g.epochStart + length
+	if end <= now {
+		end += ((now-end)/length + 1) * length
+	}
+	return end
+}
+
+func (g *flaker) remainingEpoch(now int64) time.Duration {
+	return time.Duration(g.epochEnd(now) - now)
+}
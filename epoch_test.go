@@ -0,0 +1,76 @@
+package flake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEpochEnd(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := WithEpochStart(start)
+	if end := f.EpochEnd(); !end.Equal(start.Add(EpochLength)) {
+		t.Errorf("Expected epoch end %s but got %s", start.Add(EpochLength), end)
+	}
+	if remaining := f.RemainingEpoch(); remaining <= 0 || remaining > EpochLength {
+		t.Errorf("Unexpected remaining epoch %s", remaining)
+	}
+
+	// An epoch started long ago wraps into the next one
+	start = time.Now().Add(-EpochLength - time.Hour)
+	if end := WithEpochStart(start).EpochEnd(); !end.Equal(start.Add(EpochLength).Add(EpochLength)) {
+		t.Errorf("Expected epoch end %s but got %s", start.Add(EpochLength).Add(EpochLength), end)
+	}
+}
+
+func TestWithEpochWarning(t *testing.T) {
+	clock := &manualClock{now: time.Now()}
+	var warnings []time.Duration
+	f := WithEpochStart(clock.now.Add(-EpochLength+time.Hour)).
+		WithClock(clock).
+		WithEpochWarning(2*time.Hour, func(remaining time.Duration) {
+			warnings = append(warnings, remaining)
+		})
+	for i := 0; i < 3; i++ {
+		f.Next()
+		f.Next()
+		clock.now = clock.now.Add(2 * time.Second)
+	}
+	if len(warnings) != 3 {
+		t.Errorf("Expected a warning on each of 3 intervals but got %d", len(warnings))
+	}
+	if len(warnings) > 0 && (warnings[0] > time.Hour || warnings[0] < 59*time.Minute) {
+		t.Errorf("Expected about 1h remaining but got %s", warnings[0])
+	}
+}
+
+func TestWithEpochPolicy(t *testing.T) {
+	start := time.Now().Add(-EpochLength - time.Hour)
+	var overflows int
+	f := WithEpochStart(start).WithEpochPolicy(EpochError, func(elapsed time.Duration) {
+		overflows++
+	})
+	if _, err := f.NextErr(); err != ErrEpochOverflow {
+		t.Errorf("Expected ErrEpochOverflow but got %v", err)
+	}
+	if id := f.Next(); id == 0 {
+		t.Errorf("Expected Next() to fall back to wrapping")
+	}
+	if overflows != 2 {
+		t.Errorf("Expected 2 overflow callbacks but got %d", overflows)
+	}
+
+	if _, err := WithEpochStart(time.Now().Add(time.Hour)).WithEpochPolicy(EpochError, nil).NextErr(); err != ErrEpochOverflow {
+		t.Errorf("Expected ErrEpochOverflow for an epoch start in the future but got %v", err)
+	}
+	if _, err := WithEpochPolicy(EpochError, nil).NextErr(); err != nil {
+		t.Errorf("Expected no error within the epoch but got %v", err)
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, ErrEpochOverflow) {
+			t.Errorf("Expected a panic with ErrEpochOverflow but got %v", err)
+		}
+	}()
+	WithEpochStart(start).WithEpochPolicy(EpochPanic, nil).Next()
+}
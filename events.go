@@ -0,0 +1,109 @@
+package flake
+
+import "time"
+
+// EventKind identifies a notable condition of the generator reported to
+// event hooks.
+type EventKind int
+
+const (
+	// EventClockRegression reports the clock moving backwards behind the last
+	// issued interval, Event.Duration is the lag.
+	EventClockRegression EventKind = iota
+	// EventSequenceExhausted reports an exhausted sequence of an interval,
+	// which borrows future intervals, spins or fails according to the
+	// sequence policy. Event.Duration is the time the IDs are ahead of the
+	// clock.
+	EventSequenceExhausted
+	// EventEntropyError reports a failed read of random bytes, Event.Err is
+	// the error.
+	EventEntropyError
+	// EventEpochWarning reports a new interval within the margin of
+	// WithEpochWarning before the epoch end, Event.Duration is the remaining
+	// time. It's not fired without margin.
+	EventEpochWarning
+	// EventEpochOverflow reports a time outside of the epoch, Event.Duration
+	// is the elapsed time since the epoch start.
+	EventEpochOverflow
+	// EventStateSave reports a failed save of the state store, Event.Err is
+	// the error.
+	EventStateSave
+)
+
+// Event describes a notable condition of the generator for event hooks.
+type Event struct {
+	Kind EventKind `json:"kind"`
+	// Time is the clock reading of the event
+	Time time.Time `json:"time"`
+	// MachineId is the machine-id of the generator
+	MachineId byte `json:"machineId"`
+	// Duration is the lag, the time ahead, the remaining or the elapsed time
+	// according to the kind
+	Duration time.Duration `json:"duration,omitempty"`
+	// Err is the error of EventEntropyError and EventStateSave
+	Err error `json:"-"`
+}
+
+// String returns the name of the event kind
+func (k EventKind) String() string {
+	switch k {
+	case EventClockRegression:
+		return "clock-regression"
+	case EventSequenceExhausted:
+		return "sequence-exhausted"
+	case EventEntropyError:
+		return "entropy-error"
+	case EventEpochWarning:
+		return "epoch-warning"
+	case EventEpochOverflow:
+		return "epoch-overflow"
+	case EventStateSave:
+		return "state-save"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the name of the event kind
+func (k EventKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText sets the event kind of the given name
+func (k *EventKind) UnmarshalText(text []byte) error {
+	return unmarshalName(k, text, EventStateSave, "event kind")
+}
+
+// WithEventHook is a shorthand for Default.WithEventHook(hook)
+func WithEventHook(hook func(event Event)) Flaker {
+	return getDefault().WithEventHook(hook)
+}
+
+// Returns a new Flaker instance copy additionally calling hook on every
+// notable event, e.g. to alert on clock regressions or borrowing instead of
+// discovering problems later. Hooks are called synchronously by the
+// generating goroutine besides the callbacks of the policies, so they should
+// return quickly.
+func (g *flaker) WithEventHook(hook func(event Event)) Flaker {
+	c := g.derive()
+	c.hooks = append(c.hooks[:len(c.hooks):len(c.hooks)], hook)
+	return c
+}
+
+// fire counts the event of the kind and calls the event hooks with it
+func (g *flaker) fire(kind EventKind, d time.Duration, err error) {
+	switch kind {
+	case EventClockRegression:
+		g.counters.regressions.Add(1)
+	case EventSequenceExhausted:
+		g.counters.exhausted.Add(1)
+	case EventEntropyError:
+		g.counters.entropyErrors.Add(1)
+	}
+	if len(g.hooks) == 0 {
+		return
+	}
+	event := Event{Kind: kind, Time: time.Unix(0, g.now()), MachineId: g.machineId, Duration: d, Err: err}
+	for _, hook := range g.hooks {
+		hook(event)
+	}
+}
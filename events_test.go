@@ -0,0 +1,61 @@
+package flake
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestEventHooks(t *testing.T) {
+	var events []Event
+	hook := func(event Event) { events = append(events, event) }
+	expect := func(kind EventKind) {
+		t.Helper()
+		if len(events) == 0 || events[len(events)-1].Kind != kind {
+			t.Errorf("Expected a %s event but got %v", kind, events)
+		}
+	}
+
+	regress(WithMachineId(7).WithEventHook(hook), time.Minute).Next()
+	expect(EventClockRegression)
+	if e := events[0]; e.MachineId != 7 || e.Duration <= 0 || e.Duration > time.Minute || time.Since(e.Time) > time.Second {
+		t.Errorf("Unexpected event %+v", e)
+	}
+
+	exhaust(WithEventHook(hook), 500*time.Millisecond).NextN(2)
+	expect(EventSequenceExhausted)
+
+	WithEventHook(hook).WithEntropy(failingReader{}).WithEntropyPolicy(EntropyFallback, nil).Next()
+	expect(EventEntropyError)
+	if events[len(events)-1].Err == nil {
+		t.Errorf("Expected the entropy error in the event")
+	}
+
+	f, err := New(SetEventHook(hook), SetEpochWarning(200*365*24*time.Hour, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Next()
+	expect(EventEpochWarning)
+
+	WithEventHook(hook).WithEpochStart(time.Now().Add(time.Hour)).Next()
+	expect(EventEpochOverflow)
+
+	f, _ = WithEventHook(hook).WithStateStore(failingStore{})
+	f.Next()
+	expect(EventStateSave)
+
+	// Hooks add up and don't leak into the origin of a copy
+	var second int
+	g := WithEventHook(hook)
+	regress(g.WithEventHook(func(Event) { second++ }), time.Minute).Next()
+	count := len(events)
+	regress(g.Clone(), time.Minute).Next()
+	if second != 1 || len(events) != count+1 {
+		t.Errorf("Expected both hooks once and the first again but got %d and %d", second, len(events)-count)
+	}
+
+	if b, _ := json.Marshal(Event{Kind: EventEpochWarning}); string(b) != `{"kind":"epoch-warning","time":"0001-01-01T00:00:00Z","machineId":0}` {
+		t.Errorf("Unexpected JSON %s", b)
+	}
+}
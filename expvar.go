@@ -0,0 +1,65 @@
+package flake
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SetExpvar publishes the generator under the name with expvar like
+// PublishExpvar, e.g. to show up at /debug/vars. New fails with
+// ErrInvalidConfig if the name is taken.
+func SetExpvar(name string) Option {
+	return func(o *options) {
+		o.expvar = name
+	}
+}
+
+// PublishExpvar publishes the configuration and the counters of the generator
+// under the name with expvar: the machine-id, epoch start, mode, layout, the
+// last issued interval and the Metrics of a MetricsReporter. The returned errors wrap
+// ErrInvalidConfig if the name is taken. Concurrent calls are serialized,
+// but a name published by other packages in the meantime makes expvar panic.
+func PublishExpvar(name string, f Flaker) error {
+	expvarMutex.Lock()
+	defer expvarMutex.Unlock()
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("%w: expvar %q already published", ErrInvalidConfig, name)
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		return expvarOf(f)
+	}))
+	return nil
+}
+
+// expvarMutex serializes the check and the publishing of PublishExpvar
+var expvarMutex sync.Mutex
+
+// expvarState is the published state of a generator
+type expvarState struct {
+	MachineId    byte      `json:"machineId"`
+	EpochStart   time.Time `json:"epochStart"`
+	Mode         Mode      `json:"mode"`
+	Layout       Layout    `json:"layout"`
+	LastInterval int64     `json:"lastInterval"`
+	Metrics      Metrics   `json:"metrics"`
+}
+
+// expvarOf returns the published state of the generator
+func expvarOf(f Flaker) expvarState {
+	state := expvarState{
+		MachineId:  f.MachineId(),
+		EpochStart: f.EpochStart().UTC(),
+		Mode:       f.Mode(),
+		Layout:     f.Layout(),
+	}
+	if r, ok := f.(MetricsReporter); ok {
+		state.Metrics = r.Metrics()
+	}
+	if g, ok := f.(*flaker); ok {
+		state.LastInterval, _ = unpack(atomic.LoadUint64(&g.state))
+	}
+	return state
+}
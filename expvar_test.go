@@ -0,0 +1,64 @@
+package flake
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSetExpvar(t *testing.T) {
+	flaker, err := New(SetMachineId(7), SetExpvar("flake-test"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	flaker.NextN(3)
+	v := expvar.Get("flake-test")
+	if v == nil {
+		t.Fatalf("expvar.Get() = nil")
+	}
+	var got struct {
+		MachineId    byte   `json:"machineId"`
+		Mode         string `json:"mode"`
+		LastInterval int64  `json:"lastInterval"`
+		Layout       Layout `json:"layout"`
+		Metrics      struct {
+			IDs uint64 `json:"ids"`
+		} `json:"metrics"`
+	}
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error = %v", v, err)
+	}
+	if got.MachineId != 7 || got.Metrics.IDs != 3 || got.LastInterval == 0 {
+		t.Errorf("expvar = %s, want machine-id 7, 3 IDs and the last interval", v)
+	}
+	if got.Mode != flaker.Mode().String() || got.Layout != flaker.Layout() {
+		t.Errorf("expvar = %s, want mode %v and layout %v", v, flaker.Mode(), flaker.Layout())
+	}
+
+	if _, err := New(SetExpvar("flake-test")); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("New() with a published name error = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestPublishExpvarConcurrently(t *testing.T) {
+	var published, taken atomic.Int32
+	w := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		w.Add(1)
+		go func() {
+			defer w.Done()
+			if err := PublishExpvar("flake-concurrent", Default); err == nil {
+				published.Add(1)
+			} else if errors.Is(err, ErrInvalidConfig) {
+				taken.Add(1)
+			}
+		}()
+	}
+	w.Wait()
+	if published.Load() != 1 || taken.Load() != 7 {
+		t.Errorf("Expected 1 publication and 7 taken names but got %d and %d", published.Load(), taken.Load())
+	}
+}
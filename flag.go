@@ -0,0 +1,88 @@
+package flake
+
+import "fmt"
+
+// String returns the flake as decimal number
+func (f Flake) String() string {
+	return f.Decimal()
+}
+
+// Format formats the flake as int64 for all verbs but %s and %q, which print
+// its String, so %x keeps printing the hex number instead of the hex of the
+// decimal string.
+func (f Flake) Format(state fmt.State, verb rune) {
+	if verb == 's' || verb == 'q' {
+		fmt.Fprintf(state, fmt.FormatString(state, verb), f.Decimal())
+		return
+	}
+	fmt.Fprintf(state, fmt.FormatString(state, verb), int64(f))
+}
+
+// Set decodes the flake in any format detected by Decode, in base58 or as
+// decimal number of Parse, so a *Flake is a flag.Value of command line tools:
+//
+//	var id flake.Flake
+//	flag.Var(&id, "id", "ID of the order")
+//
+// Values of digits only are decimal numbers even of the lengths of the other
+// encodings, prefix hex numbers of digits with 0x. Values of 11 characters are
+// base58 unless they are valid base64, which few base58 flakes are. Errors
+// are of type *DecodeError.
+func (f *Flake) Set(s string) error {
+	decoded, err := decodeAny(s)
+	if err != nil {
+		return err
+	}
+	*f = decoded
+	return nil
+}
+
+// Type returns the type name "flake" of the flag value as pflag.Value
+// requires
+func (f *Flake) Type() string {
+	return "flake"
+}
+
+// decodeAny decodes decimal and 0x prefixed hex numbers with Parse and all
+// other values with Decode, falling back to base58 for invalid base64 flakes.
+// Decoded values beyond the 63 bits of a flake are out of range.
+func decodeAny(s string) (Flake, error) {
+	if isDigits(s) || len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return Parse(s)
+	}
+	f, err := Decode(s)
+	if (err != nil || f < 0) && len(s) == 11 {
+		if f, err := DecodeBase58(s); err == nil {
+			return f, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	} else if f < 0 {
+		return 0, &DecodeError{Input: s, Format: detectFormat(s), Err: ErrOutOfRange}
+	}
+	return f, nil
+}
+
+// isDigits reports whether s is a non-empty string of decimal digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// detectFormat returns the format Decode detects by the length of s
+func detectFormat(s string) Format {
+	switch len(s) {
+	case 11:
+		return FormatBase64
+	case 13:
+		return FormatBase32
+	case 16:
+		return FormatHex
+	}
+	return FormatUnknown
+}
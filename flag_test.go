@@ -0,0 +1,72 @@
+package flake
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestFlagValue(t *testing.T) {
+	id := Next()
+	for _, arg := range []string{id.Hex(), id.Base32(), id.Base64(), id.Decimal(), fmt.Sprintf("0x%x", uint64(id))} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var f Flake
+		fs.Var(&f, "id", "ID")
+		if err := fs.Parse([]string{"-id", arg}); err != nil || f != id {
+			t.Errorf("Expected %d of flag %s but got %d: %v", id, arg, f, err)
+		}
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	f := Flake(42)
+	fs.Var(&f, "id", "ID")
+	if def := fs.Lookup("id").DefValue; def != "42" {
+		t.Errorf("Expected the default 42 but got %s", def)
+	}
+	if err := fs.Parse([]string{"-id", "invalid"}); err == nil || f != 42 {
+		t.Errorf("Expected an invalid flag keeping 42 but got %d: %v", f, err)
+	}
+	var de *DecodeError
+	if err := f.Set("12345678901234567890"); !errors.As(err, &de) {
+		t.Errorf("Expected a decode error but got %v", err)
+	}
+	if s := fmt.Sprintf("%v %s", id, id); s != id.Decimal()+" "+id.Decimal() {
+		t.Errorf("Expected decimal strings but got %s", s)
+	}
+	if s := fmt.Sprintf("%x %X %5d %q %08s", Flake(255), Flake(255), Flake(255), Flake(255), Flake(255)); s != `ff FF   255 "255" 00000255` {
+		t.Errorf("Expected int64 formatting but got %s", s)
+	}
+	if err := f.Set("ffffffffffffffff"); !errors.Is(err, ErrOutOfRange) || !errors.As(err, &de) || de.Format != FormatHex {
+		t.Errorf("Expected a hex flake out of range but got %v", err)
+	}
+	if f.Type() != "flake" {
+		t.Errorf("Expected the type flake but got %s", f.Type())
+	}
+}
+
+func TestFlagValueRoundTrip(t *testing.T) {
+	// Decimals of the lengths of base64, base32 and hex must stay decimal
+	for n, digits := Flake(1), 1; digits <= 19; n, digits = n*10+Flake(digits%10), digits+1 {
+		var f Flake
+		if err := f.Set(n.String()); err != nil || f != n {
+			t.Errorf("Expected %d of %d digits but got %d: %v", n, digits, f, err)
+		}
+	}
+	// Base58 flakes are mostly invalid base64 flakes
+	for _, n := range []Flake{5356559267517187318, 57, MaxFlake} {
+		var f Flake
+		if err := f.Set(n.Base58()); err != nil || f != n {
+			t.Errorf("Expected %d of base58 %s but got %d: %v", n, n.Base58(), f, err)
+		}
+	}
+	var f Flake
+	if err := f.Set(Flake(-1).Base58()); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected a flake out of range but got %v", err)
+	}
+	if err := f.Set("0x0000000000000255"); err != nil || f != 0x255 {
+		t.Errorf("Expected 0x255 of the prefixed hex but got %d: %v", f, err)
+	}
+}
@@ -1,36 +1,109 @@
 package flake
 
 import (
-	"crypto/rand"
+	"context"
 	"encoding/base32"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Flake represents a unique 63 bit ID.
 type Flake int64
 
-// Flaker is the generator interface.
+// Nil is the zero Flake which is never generated.
+const Nil Flake = 0
+
+// Flaker is the generator interface. Next() never fails and works around
+// conditions weakening the uniqueness guaranties as configured by the
+// policies. NextErr() reports these conditions as error instead: a clock
+// regression, an epoch overflow, a failing state store or missing entropy.
 type Flaker interface {
 	Next() Flake
+	NextErr() (Flake, error)
+	NextContext(ctx context.Context) (Flake, error)
+	NextN(n int) []Flake
+	AppendNext(dst []Flake, n int) []Flake
+	NextPair() (raw, shuffled Flake)
 	WithMachineId(machineId byte) Flaker
 	WithEpochStart(time time.Time) Flaker
+	WithMode(mode Mode) Flaker
+	WithClockPolicy(policy ClockPolicy, onRegression func(lag time.Duration)) Flaker
+	WithClock(clock Clock) Flaker
+	WithStateStore(store StateStore) (Flaker, error)
+	Snapshot() []byte
+	Restore(snapshot []byte) (Flaker, error)
+	WithClockCheck(reference ClockReference, maxSkew time.Duration, onSkew func(skew time.Duration)) (Flaker, error)
+	EpochEnd() time.Time
+	RemainingEpoch() time.Duration
+	WithEpochWarning(margin time.Duration, onWarning func(remaining time.Duration)) Flaker
+	WithEpochPolicy(policy EpochPolicy, onOverflow func(elapsed time.Duration)) Flaker
+	GenerateAt(t time.Time, sequence uint32) (Flake, error)
+	WithSequencePolicy(policy SequencePolicy, onExhausted func(ahead time.Duration)) Flaker
+	WithEntropyPolicy(policy EntropyPolicy, onError func(err error)) Flaker
+	WithEntropySource(source EntropySource) Flaker
+	WithEntropy(entropy Entropy) Flaker
+	WithEventHook(hook func(event Event)) Flaker
+	WithReserved(reserved ...Reserved) Flaker
+	Validate(f Flake, machineIds ...byte) error
+	Inspect(f Flake) (Info, error)
+	MachineId() byte
+	EpochStart() time.Time
+	Layout() Layout
+	Mode() Mode
+	WithShuffleKey(key []byte) Flaker
+	WithEncryptionKey(key []byte) (Flaker, error)
+	WithObfuscator(obfuscator *Obfuscator) Flaker
+	Shuffle(raw Flake) Flake
+	Unshuffle(shuffled Flake) Flake
+	Clone() Flaker
 }
 
 // ----------------------------------------------------------------------------
 
 type flaker struct {
-	mutex           *sync.Mutex
-	raw             bool
+	state  uint64 // current interval << 32 | sequence counter, accessed atomically
+	stored int64  // last interval saved to the store, accessed atomically
+	mutex  *sync.Mutex
+	config
+	counters counters
+}
+
+// config is the immutable configuration of a flaker
+type config struct {
+	mode            Mode
+	clock           Clock
+	anchor          time.Time
 	machineId       byte
+	derived         bool  // machine-id derived from the local IPv4 address
+	shard           int32 // first sequence counter of each interval
+	shards          int32 // sequence counter increment
+	layout          Layout
 	epochStart      int64
-	sequence        int32
-	currentInterval int64
+	clockPolicy     ClockPolicy
+	onRegression    func(lag time.Duration)
+	store           StateStore
+	epochMargin     time.Duration
+	onEpochWarning  func(remaining time.Duration)
+	epochPolicy     EpochPolicy
+	onEpochOverflow func(elapsed time.Duration)
+	sequencePolicy  SequencePolicy
+	onExhausted     func(ahead time.Duration)
+	entropyPolicy   EntropyPolicy
+	onEntropyError  func(err error)
+	hooks           []func(event Event)
+	entropySource   EntropySource
+	entropy         *entropyReader // replaces the entropy source unless nil
+	reserved        reservations
+	permutation     *permutation      // keyed shuffle, nil for the bit transposition
+	codec           codec             // encryption or obfuscation replacing the shuffle
+	health          *HealthThresholds // DefaultHealthThresholds if nil
 }
 
 // [interval(4byte)][sequence/random(3byte)][machine(1byte)]
@@ -43,44 +116,299 @@ const (
 	machineIdMask   = (1 << machineIdBits) - 1
 )
 
+// Bounds and capacities derived from the layout
+const (
+	// MinFlake is the smallest generated flake
+	MinFlake Flake = 1
+	// MaxFlake is the largest flake fitting into 63 bit
+	MaxFlake Flake = 1<<(intervalBits+sequenceBits+machineIdBits) - 1
+	// MachineIds is the count of distinct machine-ids
+	MachineIds = 1 << machineIdBits
+	// IntervalLength is the time span of an interval (~1.07 s)
+	IntervalLength = time.Duration(1) << ignoredTimeBits
+	// EpochLength is the time span of a flake epoch (~146 years) after which
+	// the interval wraps
+	EpochLength = IntervalLength << intervalBits
+	// SequenceCapacity is the count of IDs per interval and machine-id before
+	// the sequence is exhausted: 1 + 31 with 2 random bytes + 8,192 with one
+	// random byte + 4,194,304 without random bytes
+	SequenceCapacity = 0x400000 + 0x2000 + 0x20
+	// Throughput is the count of IDs per second and machine-id before the
+	// sequence is exhausted (~3,900,000)
+	Throughput = SequenceCapacity * float64(time.Second) / float64(IntervalLength)
+)
+
 var base32RawEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
 
+var (
+	// ErrInvalidSequence is returned for a sequence value exceeding 23 bits.
+	ErrInvalidSequence = errors.New("invalid sequence")
+	// ErrFutureTime is returned when an ID is requested for a future time.
+	ErrFutureTime = errors.New("time is in the future")
+	// ErrEntropy is returned when reading random bytes failed.
+	ErrEntropy = errors.New("reading random bytes failed")
+	// ErrStateSave is returned when saving the state to the store failed.
+	ErrStateSave = errors.New("saving flake state failed")
+	// ErrInvalidConfig is returned for an invalid or unsupported configuration.
+	ErrInvalidConfig = errors.New("invalid flaker configuration")
+)
+
 // Default is the default singleton of Flaker with sets the lower 8 bits of
 // the first non loopback IPv4 address (zero if not available) as machine-id
-// and the 1/1/2020 as epoch start (epoch is only needed for sortable IDs).
+// and the 1/1/2020 as epoch start (epoch is only needed for sortable IDs). The
+// package-level shorthands use it unless replaced with SetDefault.
 var Default = Flaker(&flaker{
-	mutex:      &sync.Mutex{},
-	machineId:  byte(getLocalIPv4() & machineIdMask),
-	epochStart: 1577833200000000000, // 1/1/2020
+	mutex: &sync.Mutex{},
+	config: config{
+		shards:     1,
+		layout:     DefaultLayout,
+		clock:      SystemClock,
+		anchor:     SystemClock.Now(),
+		machineId:  byte(getLocalIPv4() & machineIdMask),
+		derived:    true,
+		epochStart: 1577833200000000000, // 1/1/2020
+	},
 })
 
+// Raw is the default singleton of Flaker generating sortable raw IDs. NextRaw
+// uses it unless replaced with SetRaw.
 var Raw = Flaker(&flaker{
-	raw:        true,
-	mutex:      &sync.Mutex{},
-	machineId:  byte(getLocalIPv4() & machineIdMask),
-	epochStart: 1577833200000000000, // 1/1/2020
+	mutex: &sync.Mutex{},
+	config: config{
+		mode:       ModeRaw,
+		shards:     1,
+		layout:     DefaultLayout,
+		clock:      SystemClock,
+		anchor:     SystemClock.Now(),
+		machineId:  byte(getLocalIPv4() & machineIdMask),
+		derived:    true,
+		epochStart: 1577833200000000000, // 1/1/2020
+	},
 })
 
+// installed holds a generator replacing Default or Raw
+type installed struct {
+	Flaker
+}
+
+var installedDefault, installedRaw atomic.Pointer[installed]
+
+// SetDefault replaces the generator behind the package-level shorthands, e.g.
+// with one configured with the machine-id of the application config. It's
+// safe to call concurrently with the shorthands, but the Default variable
+// itself keeps the initial generator. Pass nil to restore Default. The
+// previously installed generator is returned.
+func SetDefault(f Flaker) (previous Flaker) {
+	return install(&installedDefault, f, Default)
+}
+
+// SetRaw replaces the generator behind NextRaw like SetDefault does.
+func SetRaw(f Flaker) (previous Flaker) {
+	return install(&installedRaw, f, Raw)
+}
+
+func install(p *atomic.Pointer[installed], f Flaker, initial Flaker) Flaker {
+	var next *installed
+	if f != nil {
+		next = &installed{f}
+	}
+	if prev := p.Swap(next); prev != nil {
+		return prev.Flaker
+	}
+	return initial
+}
+
+// getDefault returns the generator used by the shorthands
+func getDefault() Flaker {
+	if p := installedDefault.Load(); p != nil {
+		return p.Flaker
+	}
+	return Default
+}
+
+// getRaw returns the generator used by NextRaw
+func getRaw() Flaker {
+	if p := installedRaw.Load(); p != nil {
+		return p.Flaker
+	}
+	return Raw
+}
+
 // ----------------------------------------------------------------------------
 
 // Next is a shorthand for Default.Next()
 func Next() Flake {
-	return Default.Next()
+	return getDefault().Next()
 }
 
 // NextRaw is a shorthand for Raw.Next()
 func NextRaw() Flake {
-	return Raw.Next()
+	return getRaw().Next()
+}
+
+// NextN is a shorthand for Default.NextN(n)
+func NextN(n int) []Flake {
+	return getDefault().NextN(n)
+}
+
+// AppendNext is a shorthand for Default.AppendNext(dst, n)
+func AppendNext(dst []Flake, n int) []Flake {
+	return getDefault().AppendNext(dst, n)
+}
+
+// NextPair is a shorthand for Default.NextPair()
+func NextPair() (raw, shuffled Flake) {
+	return getDefault().NextPair()
+}
+
+// NextErr is a shorthand for Default.NextErr()
+func NextErr() (Flake, error) {
+	return getDefault().NextErr()
+}
+
+// NextContext is a shorthand for Default.NextContext(ctx)
+func NextContext(ctx context.Context) (Flake, error) {
+	return getDefault().NextContext(ctx)
 }
 
 // WithMachineId is a shorthand for Default.WithMachineId(machineId)
 func WithMachineId(machineId byte) Flaker {
-	return Default.WithMachineId(machineId)
+	return getDefault().WithMachineId(machineId)
 }
 
 // WithEpochStart is a shorthand for Default.WithEpochStart(time)
 func WithEpochStart(time time.Time) Flaker {
-	return Default.WithEpochStart(time)
+	return getDefault().WithEpochStart(time)
+}
+
+// WithMode is a shorthand for Default.WithMode(mode)
+func WithMode(mode Mode) Flaker {
+	return getDefault().WithMode(mode)
+}
+
+// Clone is a shorthand for Default.Clone()
+func Clone() Flaker {
+	return getDefault().Clone()
+}
+
+// WithShuffleKey is a shorthand for Default.WithShuffleKey(key)
+func WithShuffleKey(key []byte) Flaker {
+	return getDefault().WithShuffleKey(key)
+}
+
+// WithEncryptionKey is a shorthand for Default.WithEncryptionKey(key)
+func WithEncryptionKey(key []byte) (Flaker, error) {
+	return getDefault().WithEncryptionKey(key)
+}
+
+// WithObfuscator is a shorthand for Default.WithObfuscator(obfuscator)
+func WithObfuscator(obfuscator *Obfuscator) Flaker {
+	return getDefault().WithObfuscator(obfuscator)
+}
+
+// Shuffle is a shorthand for Default.Shuffle(raw)
+func Shuffle(raw Flake) Flake {
+	return getDefault().Shuffle(raw)
+}
+
+// Unshuffle is a shorthand for Default.Unshuffle(shuffled)
+func Unshuffle(shuffled Flake) Flake {
+	return getDefault().Unshuffle(shuffled)
+}
+
+// RawWithMachineId is a shorthand for Raw.WithMachineId(machineId)
+func RawWithMachineId(machineId byte) Flaker {
+	return getRaw().WithMachineId(machineId)
+}
+
+// RawWithEpochStart is a shorthand for Raw.WithEpochStart(time)
+func RawWithEpochStart(time time.Time) Flaker {
+	return getRaw().WithEpochStart(time)
+}
+
+// WithClockPolicy is a shorthand for Default.WithClockPolicy(policy, onRegression)
+func WithClockPolicy(policy ClockPolicy, onRegression func(lag time.Duration)) Flaker {
+	return getDefault().WithClockPolicy(policy, onRegression)
+}
+
+// WithClock is a shorthand for Default.WithClock(clock)
+func WithClock(clock Clock) Flaker {
+	return getDefault().WithClock(clock)
+}
+
+// WithStateStore is a shorthand for Default.WithStateStore(store)
+func WithStateStore(store StateStore) (Flaker, error) {
+	return getDefault().WithStateStore(store)
+}
+
+// WithClockCheck is a shorthand for Default.WithClockCheck(reference, maxSkew, onSkew)
+func WithClockCheck(reference ClockReference, maxSkew time.Duration, onSkew func(skew time.Duration)) (Flaker, error) {
+	return getDefault().WithClockCheck(reference, maxSkew, onSkew)
+}
+
+// EpochEnd is a shorthand for Default.EpochEnd()
+func EpochEnd() time.Time {
+	return getDefault().EpochEnd()
+}
+
+// RemainingEpoch is a shorthand for Default.RemainingEpoch()
+func RemainingEpoch() time.Duration {
+	return getDefault().RemainingEpoch()
+}
+
+// WithEpochWarning is a shorthand for Default.WithEpochWarning(margin, onWarning)
+func WithEpochWarning(margin time.Duration, onWarning func(remaining time.Duration)) Flaker {
+	return getDefault().WithEpochWarning(margin, onWarning)
+}
+
+// WithEpochPolicy is a shorthand for Default.WithEpochPolicy(policy, onOverflow)
+func WithEpochPolicy(policy EpochPolicy, onOverflow func(elapsed time.Duration)) Flaker {
+	return getDefault().WithEpochPolicy(policy, onOverflow)
+}
+
+// WithSequencePolicy is a shorthand for Default.WithSequencePolicy(policy, onExhausted)
+func WithSequencePolicy(policy SequencePolicy, onExhausted func(ahead time.Duration)) Flaker {
+	return getDefault().WithSequencePolicy(policy, onExhausted)
+}
+
+// WithEntropyPolicy is a shorthand for Default.WithEntropyPolicy(policy, onError)
+func WithEntropyPolicy(policy EntropyPolicy, onError func(err error)) Flaker {
+	return getDefault().WithEntropyPolicy(policy, onError)
+}
+
+// WithEntropySource is a shorthand for Default.WithEntropySource(source)
+func WithEntropySource(source EntropySource) Flaker {
+	return getDefault().WithEntropySource(source)
+}
+
+// WithEntropy is a shorthand for Default.WithEntropy(entropy)
+func WithEntropy(entropy Entropy) Flaker {
+	return getDefault().WithEntropy(entropy)
+}
+
+// WithReserved is a shorthand for Default.WithReserved(reserved...)
+func WithReserved(reserved ...Reserved) Flaker {
+	return getDefault().WithReserved(reserved...)
+}
+
+// Validate is a shorthand for Default.Validate(f, machineIds...)
+func Validate(f Flake, machineIds ...byte) error {
+	return getDefault().Validate(f, machineIds...)
+}
+
+// GenerateAt is a shorthand for Default.GenerateAt(t, sequence)
+func GenerateAt(t time.Time, sequence uint32) (Flake, error) {
+	return getDefault().GenerateAt(t, sequence)
+}
+
+// Snapshot is a shorthand for Default.Snapshot()
+func Snapshot() []byte {
+	return getDefault().Snapshot()
+}
+
+// Restore is a shorthand for Default.Restore(snapshot)
+func Restore(snapshot []byte) (Flaker, error) {
+	return getDefault().Restore(snapshot)
 }
 
 // ----------------------------------------------------------------------------
@@ -90,24 +418,154 @@ func WithEpochStart(time time.Time) Flaker {
 // be guarantied unique within a 146 years time span. It can generate up to
 // 4,000,000 IDs each second but its save to generate unlimited more when stick
 // to a cool down time of GENERATED_IDS / 4,000,000 s between program restarts.
-// Generating a new ID is thread save and will never block.
+// Generating a new ID is thread save and will never fail. It only blocks on a
+// clock regression when the ClockWait policy is set.
 func (g *flaker) Next() Flake {
+	id, _ := g.emit(context.Background(), false, 0)
+	return id
+}
 
-	raw := g.next()
+// NextErr works like Next but reports conditions which would weaken the
+// uniqueness guaranties instead of working around them:
+//   - ErrClockRegression on a clock regression with the ClockError policy
+//   - ErrEpochOverflow on a time outside of the epoch with the EpochError policy
+//   - ErrSequenceExhausted on an exhausted sequence with the SequenceError policy
+//   - ErrStateSave when the state store failed to save the reached interval
+//   - ErrEntropy when reading the random bytes failed with the EntropyError
+//     or EntropyRetry policy
+//
+// Use errors.Is to check the returned error.
+func (g *flaker) NextErr() (Flake, error) {
+	return g.emit(context.Background(), true, 0)
+}
 
-	if g.raw {
-		return Flake(raw)
+// NextContext works like NextErr but returns the context error when the
+// context is done before an ID is safely available, e.g. while waiting for
+// the clock to catch up with the ClockWait policy.
+func (g *flaker) NextContext(ctx context.Context) (Flake, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return g.emit(ctx, true, 0)
+}
+
+// NextN returns n new unique IDs in ascending generation order. The sequence
+// counters are reserved in runs with a single time reading each, which is
+// much cheaper than calling Next() n times, e.g. for bulk inserts. Like
+// Next() it never fails.
+func (g *flaker) NextN(n int) []Flake {
+	if n <= 0 {
+		return []Flake{}
+	}
+	return g.AppendNext(make([]Flake, 0, n), n)
+}
+
+// AppendNext appends n new unique IDs to dst and returns the extended slice.
+// Reuse dst to generate batches without allocations.
+func (g *flaker) AppendNext(dst []Flake, n int) []Flake {
+	if n <= 0 {
+		return dst
+	}
+	start := len(dst)
+	if cap(dst)-start < n {
+		grown := make([]Flake, start, start+n)
+		copy(grown, dst)
+		dst = grown
+	}
+	dst = dst[:start+n]
+	for ids := dst[start:]; len(ids) > 0; {
+		_ = g.generate(context.Background(), false, 0, ids)
+		if g.mode == ModeShuffled {
+			for i, raw := range ids {
+				ids[i] = Flake(g.shuffle(int64(raw)))
+			}
+		}
+		if len(g.reserved) == 0 {
+			break
+		}
+		// Drop the reserved IDs and generate the missing ones
+		kept := 0
+		for _, id := range ids {
+			if !g.reserved.Contains(id) {
+				ids[kept] = id
+				kept++
+			}
+		}
+		ids = ids[kept:]
 	}
+	return dst
+}
 
-	// Shuffle bits
-	uid := make([]byte, 8, 8)
-	for i := int64(0); i < 8; i++ {
-		for l := int64(0); l < 8; l++ {
-			uid[l] |= byte((raw & (1 << (i*8 + l))) >> (i*7 + l))
+// NextPair returns both representations of a single new unique ID regardless
+// of the mode: the sortable raw form, e.g. to store internally, and the
+// shuffled form, e.g. to expose externally. In ModeRandom both are the same
+// random ID. IDs of which either form is reserved are skipped. Like Next() it
+// never fails.
+func (g *flaker) NextPair() (raw, shuffled Flake) {
+	for {
+		r, _ := g.next(context.Background(), false, 0)
+		raw, shuffled = Flake(r), Flake(r)
+		if g.mode != ModeRandom {
+			shuffled = Flake(g.shuffle(r))
+		}
+		if !g.reserved.Contains(raw) && !g.reserved.Contains(shuffled) {
+			return raw, shuffled
 		}
 	}
+}
+
+// format returns the raw ID as Flake, shuffled in ModeShuffled.
+func (g *flaker) format(raw int64) Flake {
+	if g.mode != ModeShuffled {
+		return Flake(raw)
+	}
+	return Flake(g.shuffle(raw))
+}
 
-	return Flake(binary.LittleEndian.Uint64(uid))
+// shuffle transposes the bits of the 8x8 bit matrix formed by the bytes of
+// the ID. The transposition is its own inverse, so it unshuffles as well. It
+// swaps the 2x2, 4x4 and 8x8 blocks below the diagonal in three steps
+// (Hacker's Delight 7-3): bit l of byte i moves to bit i of byte l.
+func shuffle(raw int64) int64 {
+	x := uint64(raw)
+	t := (x ^ x>>7) & 0x00aa00aa00aa00aa
+	x ^= t ^ t<<7
+	t = (x ^ x>>14) & 0x0000cccc0000cccc
+	x ^= t ^ t<<14
+	t = (x ^ x>>28) & 0x00000000f0f0f0f0
+	x ^= t ^ t<<28
+	return int64(x)
+}
+
+// GenerateAt deterministically returns the ID for the specified past time and
+// 23 bit sequence value, e.g. to migrate historical records into a flake-keyed
+// system. The IDs sort among the generated ones by their time. The caller is
+// responsible for the uniqueness of the sequence values within an interval of
+// ~1s; stay clear of the current interval to not collide with generated IDs.
+// IDs of ModeRandom have no time, GenerateAt fails with ErrInvalidConfig.
+// Reserved IDs fail with ErrReserved.
+func (g *flaker) GenerateAt(t time.Time, sequence uint32) (Flake, error) {
+	if g.mode == ModeRandom {
+		return 0, fmt.Errorf("%w: no time in %s mode", ErrInvalidConfig, g.mode)
+	}
+	l := &g.layout
+	if sequence >= 1<<l.SequenceBits {
+		return 0, ErrInvalidSequence
+	}
+	if t.UnixNano() > g.now() {
+		return 0, ErrFutureTime
+	}
+	elapsed := t.UnixNano() - g.epochStart
+	if elapsed < 0 || elapsed>>l.ResolutionBits >= 1<<l.IntervalBits {
+		return 0, ErrEpochOverflow
+	}
+	raw := elapsed >> l.ResolutionBits
+	raw = (raw << l.SequenceBits) | int64(sequence)
+	raw = (raw << l.MachineIdBits) | g.machineBits()
+	if id := g.format(raw); !g.reserved.Contains(id) {
+		return id, nil
+	}
+	return 0, ErrReserved
 }
 
 // next returns a raw unique ID generated from the flake algorithm but without
@@ -115,49 +573,236 @@ func (g *flaker) Next() Flake {
 // will increasing until end of flake epoch (2116-02-21) when the
 // sequence will start again. No matter that the IDs will be guarantied
 // unique within a 146 years time span. Generating a new ID is thread save
-// and will never block.
-func (g *flaker) next() int64 {
+// and will only block on a clock regression with the ClockWait policy or an
+// exhausted sequence with the SequenceSpin policy until the context is done.
+// Errors are only returned when fallible is set.
+func (g *flaker) next(ctx context.Context, fallible bool, kind byte) (int64, error) {
+	var raw [1]Flake
+	err := g.generate(ctx, fallible, kind, raw[:])
+	return int64(raw[0]), err
+}
+
+// generate fills ids with unshuffled unique IDs. Runs of sequence counters are
+// reserved at once with a single time reading each, as many as the sequence
+// policy permits within the interval.
+func (g *flaker) generate(ctx context.Context, fallible bool, kind byte, ids []Flake) error {
+	if g.mode == ModeRandom {
+		err := g.generateRandom(fallible, ids)
+		if err == nil {
+			g.counters.ids.Add(uint64(len(ids)))
+		}
+		return err
+	}
+	l := &g.layout
+	for len(ids) > 0 {
+		current, counter, count, err := g.reserve(ctx, fallible, len(ids))
+		if err != nil {
+			return err
+		}
+		for i := range ids[:count] {
+			counter += g.shards
+			sequence, randomBits := l.sequenceOf(counter)
+			if randomBits > 0 {
+				r, err := g.random((randomBits + 7) / 8)
+				if err != nil && fallible {
+					return fmt.Errorf("%w: %v", ErrEntropy, err)
+				}
+				sequence |= int64(r) & (1<<randomBits - 1) &^ (1<<l.KindBits - 1)
+			}
+			sequence |= int64(kind)
+			raw := current
+			raw = (raw << l.SequenceBits) + sequence // + to increment the interval too on rollover
+			raw = (raw << l.MachineIdBits) | g.machineBits()
+			ids[i] = Flake(raw)
+		}
+		g.counters.ids.Add(uint64(count))
+		ids = ids[count:]
+	}
+	return nil
+}
+
+// reserve reserves a run of up to n sequence counters and returns the interval
+// and the counter preceding the run together with its length.
+func (g *flaker) reserve(ctx context.Context, fallible bool, n int) (current int64, counter int32, count int, err error) {
+
+	l := &g.layout
+	now := g.now()
+
+	if elapsed := now - g.epochStart; elapsed < 0 || elapsed>>l.ResolutionBits >= 1<<l.IntervalBits {
+		if err := g.overflow(time.Duration(elapsed), fallible); err != nil {
+			return 0, 0, 0, err
+		}
+	}
 
 	// 32 bit time interval with nano-time >> 20 (~1s) clock loops after reaching end of epoch each ~ 146 years
-	interval := ((time.Now().UnixNano() - g.epochStart) >> ignoredTimeBits) & intervalMask
+	interval := ((now - g.epochStart) >> l.ResolutionBits) & (1<<l.IntervalBits - 1)
 
 	// 23 bit sequence and random
-	sequence := int32(0)
-	g.mutex.Lock()
-	loop := (g.sequence + 0x400000 - 0x2020) >> sequenceBits // 4194304 - 8224 = 4186080
-	if interval-int64(loop) <= g.currentInterval {
-		g.sequence++
-		if g.sequence < 0x20 {
-			// Small counter and 2 random bytes
-			sequence = (g.sequence << 16) | (randomByte() << 8) | randomByte()
-		} else if g.sequence < 0x2020 {
-			// Enlarge the counter
-			sequence = (0x200000 - 0x2000 + (g.sequence << 8)) | randomByte()
-		} else {
-			// Use all space for the counter
-			sequence = 0x400000 - 0x2020 + g.sequence
+	var last int32
+	var ahead time.Duration
+	newInterval, exhausted, regressed := false, false, false
+	for {
+		// Lock-free: compute the successor of the state and retry if another
+		// goroutine changed the state meanwhile
+		state := atomic.LoadUint64(&g.state)
+		current, counter = unpack(state)
+		if interval < current && !regressed {
+			// The clock went backwards behind the last issued interval
+			regressed = true
+			lag := time.Duration(g.epochStart + current<<l.ResolutionBits - now)
+			if g.onRegression != nil {
+				g.onRegression(lag)
+			}
+			g.fire(EventClockRegression, lag, nil)
+			switch {
+			case g.clockPolicy == ClockWait:
+				if err := sleep(ctx, lag); err != nil {
+					return 0, 0, 0, err
+				}
+				return g.reserve(ctx, fallible, n)
+			case g.clockPolicy == ClockError && fallible:
+				return 0, 0, 0, ErrClockRegression
+			}
+		}
+		newInterval, exhausted = false, false
+		if interval-l.borrowed(counter) > current {
+			current, counter = interval, g.shard-g.shards
+			newInterval = true
+		}
+		count = n
+		if capacity := l.SequenceCapacity(); count > capacity {
+			count = capacity
+		}
+		if reached := current + l.borrowed(counter+int32(count)*g.shards); reached > interval && reached > current {
+			// The sequence of the interval is exhausted, IDs of the run are ahead of the clock.
+			// Shards never borrow, the intervals ahead belong to the other shards too.
+			if policy := g.sequencePolicy; policy == SequenceSpin || policy == SequenceError && fallible || g.shards > 1 {
+				// Shorten the run to the counters left within the clock interval
+				top := interval
+				if top < current {
+					top = current
+				}
+				limit := l.lastCounter(top - current)
+				if limit < int64(counter) {
+					limit = int64(counter)
+				}
+				count = int((limit - int64(counter)) / int64(g.shards))
+				if count == 0 {
+					ahead = time.Duration(g.epochStart + (current+l.borrowed(counter+g.shards))<<l.ResolutionBits - now)
+					if g.onExhausted != nil {
+						g.onExhausted(ahead)
+					}
+					g.fire(EventSequenceExhausted, ahead, nil)
+					if policy == SequenceError && fallible {
+						return 0, 0, 0, ErrSequenceExhausted
+					}
+					if err := sleep(ctx, ahead); err != nil {
+						return 0, 0, 0, err
+					}
+					return g.reserve(ctx, fallible, n)
+				}
+			} else {
+				ahead = time.Duration(g.epochStart + reached<<l.ResolutionBits - now)
+				exhausted = l.borrowed(counter+int32(count)*g.shards) > l.borrowed(counter)
+			}
+		}
+		last = counter + int32(count)*g.shards
+		if atomic.CompareAndSwapUint64(&g.state, state, pack(current, last)) {
+			break
+		}
+	}
+
+	if g.store != nil {
+		if err := g.persist(current, last); err != nil {
+			g.fire(EventStateSave, 0, err)
+			if fallible {
+				return 0, 0, 0, fmt.Errorf("%w: %v", ErrStateSave, err)
+			}
+		}
+	}
+
+	if exhausted {
+		if g.onExhausted != nil {
+			g.onExhausted(ahead)
 		}
-	} else {
-		g.currentInterval = interval
-		g.sequence = int32(0)
+		g.fire(EventSequenceExhausted, ahead, nil)
 	}
-	g.mutex.Unlock()
 
-	raw := interval
-	raw = (raw << sequenceBits) + int64(sequence) // + to increment the interval too on rollover
-	raw = (raw << machineIdBits) | int64(g.machineId)
+	if newInterval && (g.onEpochWarning != nil || len(g.hooks) > 0) {
+		if remaining := g.remainingEpoch(now); remaining < g.epochMargin {
+			if g.onEpochWarning != nil {
+				g.onEpochWarning(remaining)
+			}
+			g.fire(EventEpochWarning, remaining, nil)
+		}
+	}
 
-	return raw
+	return current, counter, count, nil
+}
+
+// persist saves the state to the store before IDs of a newly reached interval
+// are issued. Concurrent saves are serialized by the mutex.
+func (g *flaker) persist(interval int64, counter int32) error {
+	reached := interval + g.layout.borrowed(counter)
+	if reached <= atomic.LoadInt64(&g.stored) {
+		return nil
+	}
+	start := time.Now()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.counters.lockWait.Add(int64(time.Since(start)))
+	if reached <= atomic.LoadInt64(&g.stored) {
+		return nil
+	}
+	if err := g.store.Save(State{Interval: interval, Sequence: counter}); err != nil {
+		return err
+	}
+	atomic.StoreInt64(&g.stored, reached)
+	return nil
+}
+
+// MachineId returns the machine-id of the generator
+func (g *flaker) MachineId() byte {
+	return g.machineId
+}
+
+// EpochStart returns the start of the epoch of the generator
+func (g *flaker) EpochStart() time.Time {
+	return time.Unix(0, g.epochStart)
+}
+
+// Layout returns the bit layout of the generated flakes
+func (g *flaker) Layout() Layout {
+	return g.layout
+}
+
+// Mode returns the representation of the generated flakes
+func (g *flaker) Mode() Mode {
+	return g.mode
+}
+
+// machineBits returns the machine-id masked to the bits of the layout
+func (g *flaker) machineBits() int64 {
+	return int64(g.machineId) & (1<<g.layout.MachineIdBits - 1)
+}
+
+// now returns the current time in nanoseconds since 1/1/1970 computed from the
+// wall clock anchor plus the monotonic time elapsed since. NTP slews and steps
+// during the process lifetime can't move the interval backwards this way as
+// long as the clock provides monotonic readings.
+func (g *flaker) now() int64 {
+	return g.anchor.UnixNano() + int64(g.clock.Now().Sub(g.anchor))
 }
 
 // Returns a new Flaker instance copy with the specified machine-id set. You
 // should create one Flaker instance per machine as singleton. Do not create
 // multiple instances with the same machine-id since it's not guarantied to
 // generate unique IDs from different instances with the same machine-id.
-func (g flaker) WithMachineId(machineId byte) Flaker {
-	g.machineId = machineId
-	g.mutex = &sync.Mutex{}
-	return &g
+func (g *flaker) WithMachineId(machineId byte) Flaker {
+	c := g.derive()
+	c.machineId = machineId
+	c.derived = false
+	return c
 }
 
 // Returns a new Flaker instance copy with the specified epoch start time set.
@@ -165,19 +810,156 @@ func (g flaker) WithMachineId(machineId byte) Flaker {
 // unique within this time span. You don't have to set this value as long you
 // don't need sorted ID values generated with the NextRaw() function. The uniqueness
 // of the generated IDs is guarantied within a timespan of 146 years anyhow.
-func (g flaker) WithEpochStart(time time.Time) Flaker {
-	g.epochStart = time.UnixNano()
-	g.mutex = &sync.Mutex{}
-	return &g
+func (g *flaker) WithEpochStart(time time.Time) Flaker {
+	c := g.derive()
+	c.epochStart = time.UnixNano()
+	return c
+}
+
+// Returns a new Flaker instance copy generating flakes of the specified mode,
+// e.g. Default.WithMode(ModeRaw) for sortable IDs. Unknown modes fall back to
+// ModeShuffled.
+func (g *flaker) WithMode(mode Mode) Flaker {
+	c := g.derive()
+	if mode != ModeRaw && mode != ModeRandom {
+		mode = ModeShuffled
+	}
+	c.mode = mode
+	return c
+}
+
+// Returns a new Flaker instance copy with the specified clock policy set. The
+// policy defines how the generator reacts when the clock moves backwards
+// behind the last issued interval, e.g. after a NTP step. The optional
+// onRegression callback is invoked with the time the clock is lagging behind
+// each time a regression is detected.
+func (g *flaker) WithClockPolicy(policy ClockPolicy, onRegression func(lag time.Duration)) Flaker {
+	c := g.derive()
+	c.clockPolicy = policy
+	c.onRegression = onRegression
+	return c
+}
+
+// Returns a new Flaker instance copy using the specified clock for all time
+// readings. The clock is read once to anchor the wall time; intervals are
+// derived from the elapsed time since. Use it to control time in tests and
+// simulations or to supply a disciplined clock.
+func (g *flaker) WithClock(clock Clock) Flaker {
+	c := g.derive()
+	c.clock = clock
+	c.anchor = clock.Now()
+	return c
+}
+
+// Returns a new Flaker instance copy persisting its state to the specified
+// store. The last saved state is loaded first and the generator resumes after
+// the last interval it reached, so no cool down time between program restarts
+// is required. The state is saved each time a new interval is reached. Use
+// one store per generator and machine-id.
+func (g *flaker) WithStateStore(store StateStore) (Flaker, error) {
+	state, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading flake state: %w", err)
+	}
+	c := g.derive()
+	c.store = store
+	c.restore(state)
+	return c, nil
+}
+
+// Snapshot returns the current state of the generator as opaque blob. Store
+// it in any datastore and pass it to Restore on startup to resume safely
+// without a cool down time.
+func (g *flaker) Snapshot() []byte {
+	interval, counter := unpack(atomic.LoadUint64(&g.state))
+	b, _ := State{Interval: interval, Sequence: counter}.MarshalBinary()
+	return b
+}
+
+// Returns a new Flaker instance copy resuming after the state of the
+// specified snapshot taken with Snapshot. Restore it on a generator with the
+// same machine-id and epoch start the snapshot was taken from.
+func (g *flaker) Restore(snapshot []byte) (Flaker, error) {
+	var state State
+	if err := state.UnmarshalBinary(snapshot); err != nil {
+		return nil, err
+	}
+	c := g.derive()
+	c.restore(state)
+	return c, nil
+}
+
+// Clone returns a new Flaker instance with the configuration of this one but
+// a fresh mutex and a fresh sequence state, e.g. to intentionally derive an
+// independent generator for another machine-id. The state store isn't carried
+// over, so two generators never save to the same store. Don't use two
+// generators with the same machine-id at the same time.
+//
+// The With* methods copy the configuration like Clone but carry the sequence
+// state over, so the derived generator continues where this one left off. Use
+// the derived generator in place of this one.
+func (g *flaker) Clone() Flaker {
+	c := &flaker{mutex: &sync.Mutex{}, config: g.config}
+	c.store = nil
+	return c
+}
+
+// derive returns a copy of the generator with a fresh mutex and a snapshot of
+// the sequence state. Only the config is copied to not race with generating
+// IDs concurrently.
+func (g *flaker) derive() *flaker {
+	return &flaker{
+		state:  atomic.LoadUint64(&g.state),
+		stored: atomic.LoadInt64(&g.stored),
+		mutex:  &sync.Mutex{},
+		config: g.config,
+	}
+}
+
+// restore continues the sequence with the interval following the one reached
+// with the given state since IDs of that interval may have been issued after
+// the state was saved.
+func (g *flaker) restore(state State) {
+	g.stored = state.Interval + g.layout.borrowed(state.Sequence)
+	g.state = pack(state.Interval, int32(g.layout.lastCounter(g.layout.borrowed(state.Sequence))+1-int64(g.shards)))
+}
+
+// Returns a new Flaker instance copy after checking its clock against the
+// specified reference, e.g. NTPReference("pool.ntp.org", time.Second). A
+// large skew silently eats the uniqueness guaranties across machines and
+// restarts. When the skew exceeds maxSkew the onSkew callback is invoked or,
+// if onSkew is nil, ErrClockSkew is returned.
+func (g *flaker) WithClockCheck(reference ClockReference, maxSkew time.Duration, onSkew func(skew time.Duration)) (Flaker, error) {
+	skew, err := reference(g.clock)
+	if err != nil {
+		return nil, fmt.Errorf("checking clock: %w", err)
+	}
+	if skew > maxSkew || skew < -maxSkew {
+		if onSkew == nil {
+			return nil, fmt.Errorf("%w: %s", ErrClockSkew, skew)
+		}
+		onSkew(skew)
+	}
+	return g.derive(), nil
 }
 
 // ----------------------------------------------------------------------------
 
+// IsZero reports whether the flake is Nil
+func (f Flake) IsZero() bool {
+	return f == Nil
+}
+
 // Bytes returns the flak as 8 bytes
 func (f Flake) Bytes() []byte {
-	uid := make([]byte, 8, 8)
-	binary.BigEndian.PutUint64(uid, uint64(f))
-	return uid
+	uid := f.Array()
+	return uid[:]
+}
+
+// Array returns the flake as 8 big endian bytes without allocating
+func (f Flake) Array() (uid [8]byte) {
+	binary.BigEndian.PutUint64(uid[:], uint64(f))
+	return
 }
 
 // Int64 returns the flak as raw int64
@@ -192,53 +974,51 @@ func (f Flake) Uint64() uint64 {
 
 // Hex encodes the flake to hex
 func (f Flake) Hex() string {
-	return hex.EncodeToString(f.Bytes())
+	uid := f.Array()
+	return hex.EncodeToString(uid[:])
 }
 
 // Base64 encodes the flake to base64
 func (f Flake) Base64() string {
-	return base64.RawURLEncoding.EncodeToString(f.Bytes())
+	uid := f.Array()
+	return base64.RawURLEncoding.EncodeToString(uid[:])
 }
 
 // Base32 encodes the flake to base32
 func (f Flake) Base32() string {
-	return base32RawEncoding.EncodeToString(f.Bytes())
+	uid := f.Array()
+	return base32RawEncoding.EncodeToString(uid[:])
 }
 
 // FromBytes decodes a 8 bit flake instance from bytes
 func FromBytes(b []byte) (flake Flake, err error) {
 	if len(b) != 8 {
-		return 0, errors.New("unknown format")
+		return 0, &DecodeError{Input: hex.EncodeToString(b), Format: FormatBytes, Err: ErrInvalidLength}
 	}
 	flake = Flake(binary.BigEndian.Uint64(b))
 	return
 }
 
-// Decode decodes a hex, base32 or base64 encoded flake
+// Decode decodes a hex, base32 or base64 encoded flake. The format is
+// detected by the length of s. Base64 is the only format of 11 characters:
+// base58 flakes of the same length are rejected unless they happen to be
+// valid base64 too, so decode them with DecodeBase58. Use DecodeFormat to
+// reject inputs of other formats. Errors are of type *DecodeError.
 func Decode(s string) (flake Flake, err error) {
-	var b []byte
-	switch len(s) {
-	case 11:
-		b, err = base64.RawURLEncoding.DecodeString(s)
-	case 13:
-		b, err = base32RawEncoding.DecodeString(s)
-	case 16:
-		b, err = hex.DecodeString(s)
-	default:
-		err = errors.New("unknown format")
-	}
-	if err != nil {
-		return
-	}
-	return FromBytes(b)
+	var buf [24]byte
+	return decode(s, buf[:])
 }
 
 // ----------------------------------------------------------------------------
 
-func randomByte() int32 {
-	b := make([]byte, 1, 1)
-	_, _ = rand.Read(b)
-	return int32(b[0])
+// pack combines the current interval and sequence counter to the state
+func pack(interval int64, counter int32) uint64 {
+	return uint64(interval)<<32 | uint64(uint32(counter))
+}
+
+// unpack splits the state into the current interval and sequence counter
+func unpack(state uint64) (interval int64, counter int32) {
+	return int64(state >> 32), int32(state)
 }
 
 func getLocalIPv4() (ip4 uint32) {
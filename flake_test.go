@@ -1,7 +1,13 @@
 package flake
 
 import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math/bits"
+	mathrand "math/rand"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -45,6 +51,108 @@ func TestWithMachineId(t *testing.T) {
 	}
 }
 
+func TestWithMode(t *testing.T) {
+	f := WithMachineId(3).WithMode(ModeRaw)
+	if f.Mode() != ModeRaw || f.MachineId() != 3 {
+		t.Errorf("Expected a raw generator with machine-id 3")
+	}
+	if first, second := f.Next(), f.Next(); second <= first {
+		t.Errorf("Expected sortable IDs but got %d after %d", second, first)
+	}
+	if f := f.WithMode(ModeShuffled); f.Mode() != ModeShuffled || WithMode(Mode(7)).Mode() != ModeShuffled {
+		t.Errorf("Expected shuffled generators")
+	}
+	if f := RawWithMachineId(4); f.Mode() != ModeRaw || f.MachineId() != 4 || f.Next()&0xff != 4 {
+		t.Errorf("Expected a raw generator with machine-id 4")
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if f := RawWithEpochStart(start); f.Mode() != ModeRaw || !f.EpochStart().Equal(start) {
+		t.Errorf("Expected a raw generator with the epoch start %s", start)
+	}
+}
+
+func TestModeRandom(t *testing.T) {
+	f := WithMode(ModeRandom)
+	ids := append(f.NextN(1000), f.Next())
+	m := make(map[Flake]bool)
+	ones := 0
+	for _, id := range ids {
+		if id <= 0 || m[id] || f.Validate(id) != nil {
+			t.Errorf("Expected unique positive IDs but got %d", id)
+		}
+		m[id] = true
+		ones += bits.OnesCount64(uint64(id))
+	}
+	if mean := float64(ones) / float64(len(ids)); mean < 30 || mean > 33 {
+		t.Errorf("Expected ~31.5 random bits set per ID but got %.1f", mean)
+	}
+	if raw, shuffled := f.NextPair(); raw != shuffled {
+		t.Errorf("Expected the same random ID twice but got %d and %d", raw, shuffled)
+	}
+	if _, err := f.GenerateAt(time.Now(), 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for GenerateAt but got %v", err)
+	}
+	var mode Mode
+	if err := mode.UnmarshalText([]byte("random")); err != nil || mode != ModeRandom {
+		t.Errorf("Expected to parse the random mode but got %s: %v", mode, err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	store := FileStore(filepath.Join(t.TempDir(), "flake.state"))
+	f, _ := Raw.WithMachineId(6).WithStateStore(store)
+	g := f.(*flaker)
+	last := f.NextN(1000)[999]
+
+	// With* carry the sequence state over
+	if next := f.WithClockPolicy(ClockWait, nil).Next(); next <= last {
+		t.Errorf("Expected the derived generator to continue after %d but got %d", last, next)
+	}
+
+	c := f.Clone().(*flaker)
+	if c.mutex == g.mutex || c.state != 0 || c.stored != 0 || c.store != nil {
+		t.Errorf("Expected a fresh mutex and sequence state without store")
+	}
+	if c.machineId != 6 || c.mode != ModeRaw || c.epochStart != g.epochStart || c.layout != g.layout {
+		t.Errorf("Expected the configuration to be cloned")
+	}
+	if id := Clone().Next(); id == 0 {
+		t.Errorf("Expected a clone of Default to generate IDs")
+	}
+
+	// Deriving generators doesn't race with generating IDs
+	w := sync.WaitGroup{}
+	w.Add(1)
+	go func() {
+		defer w.Done()
+		for i := 0; i < 1000; i++ {
+			f.Next()
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		f.WithMachineId(7)
+		f.Clone()
+	}
+	w.Wait()
+}
+
+func TestNextPair(t *testing.T) {
+	f := WithMachineId(5)
+	raw, shuffled := f.NextPair()
+	if shuffle(int64(raw)) != int64(shuffled) || raw&0xff != 5 {
+		t.Errorf("Expected the raw %d and shuffled %d form of the same ID", raw, shuffled)
+	}
+	if next, _ := f.NextPair(); next <= raw {
+		t.Errorf("Expected sortable raw IDs but got %d after %d", next, raw)
+	}
+	if raw, shuffled := NextPair(); shuffle(int64(raw)) != int64(shuffled) {
+		t.Errorf("Expected the raw %d and shuffled %d form of the same ID", raw, shuffled)
+	}
+	if f.Validate(shuffled) != nil || f.WithMode(ModeRaw).Validate(raw) != nil {
+		t.Errorf("Expected both forms to be valid")
+	}
+}
+
 func TestWithEpochStart(t *testing.T) {
 	f1 := WithEpochStart(time.Unix(0, 0))
 	f2 := WithEpochStart(time.Unix(1000, 0))
@@ -78,6 +186,15 @@ func TestEncode(t *testing.T) {
 	if out, err := FromBytes(in.Bytes()); err != nil || out != in {
 		t.Errorf("Decoding of bytes value failed for input %d with output %d: %v", in, out, err)
 	}
+	if uid := in.Array(); string(uid[:]) != string(in.Bytes()) {
+		t.Errorf("Expected array %x to equal bytes %x", uid, in.Bytes())
+	}
+	if allocs := testing.AllocsPerRun(10, func() { _ = in.Array() }); allocs != 0 {
+		t.Errorf("Expected no allocations but got %.0f", allocs)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { encoded = in.Hex() }); allocs != 1 {
+		t.Errorf("Expected only the string allocation but got %.0f", allocs)
+	}
 }
 
 func TestDecode(t *testing.T) {
@@ -136,3 +253,284 @@ func generate(t *testing.T, f Flaker, m map[Flake]int, count int) {
 		m[id] = i
 	}
 }
+
+func TestSetDefault(t *testing.T) {
+	f := Default.WithMachineId(9)
+	if prev := SetDefault(f); prev != Default {
+		t.Errorf("Expected Default to be replaced")
+	}
+	if machineId := shuffle(int64(Next())) & 0xff; machineId != 9 {
+		t.Errorf("Expected an ID of the installed generator but got machine-id %d", machineId)
+	}
+	r := Raw.WithMachineId(10)
+	SetRaw(r)
+	if machineId := NextRaw() & 0xff; machineId != 10 {
+		t.Errorf("Expected a raw ID of the installed generator but got machine-id %d", machineId)
+	}
+
+	w := sync.WaitGroup{}
+	w.Add(2)
+	go func() {
+		defer w.Done()
+		for i := 0; i < 1000; i++ {
+			Next()
+		}
+	}()
+	go func() {
+		defer w.Done()
+		for i := 0; i < 1000; i++ {
+			SetDefault(f)
+		}
+	}()
+	w.Wait()
+
+	if prev := SetDefault(nil); prev != f {
+		t.Errorf("Expected the installed generator to be returned")
+	}
+	if prev := SetRaw(nil); prev != r {
+		t.Errorf("Expected the installed raw generator to be returned")
+	}
+	if machineId := NextRaw() & 0xff; machineId != Flake(Raw.(*flaker).machineId) {
+		t.Errorf("Expected Raw to be restored but got machine-id %d", machineId)
+	}
+}
+
+func TestGenerateAt(t *testing.T) {
+	at := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	f := Raw.WithMachineId(7)
+	id, err := f.GenerateAt(at, 42)
+	if err != nil {
+		t.Errorf("Generating at %s failed: %v", at, err)
+	}
+	if again, _ := f.GenerateAt(at, 42); again != id {
+		t.Errorf("Expected the same ID %d for the same input but got %d", id, again)
+	}
+	if later, _ := f.GenerateAt(at.Add(2*time.Second), 0); later <= id {
+		t.Errorf("Expected ID %d of a later time to sort after %d", later, id)
+	}
+	if live := f.Next(); live <= id {
+		t.Errorf("Expected live ID %d to sort after %d", live, id)
+	}
+	if shuffled, _ := WithMachineId(7).GenerateAt(at, 42); shuffled == id {
+		t.Errorf("Expected a shuffled ID")
+	}
+	if _, err := f.GenerateAt(at, 1<<sequenceBits); err != ErrInvalidSequence {
+		t.Errorf("Expected ErrInvalidSequence but got %v", err)
+	}
+	if _, err := f.GenerateAt(time.Now().Add(time.Hour), 0); err != ErrFutureTime {
+		t.Errorf("Expected ErrFutureTime but got %v", err)
+	}
+	if _, err := f.GenerateAt(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), 0); err != ErrEpochOverflow {
+		t.Errorf("Expected ErrEpochOverflow but got %v", err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("no entropy")
+}
+
+type failingStore struct{}
+
+func (failingStore) Load() (State, error) {
+	return State{}, nil
+}
+
+func (failingStore) Save(State) error {
+	return errors.New("read-only")
+}
+
+func TestNextErr(t *testing.T) {
+	if _, err := NextErr(); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+
+	f, _ := WithStateStore(failingStore{})
+	if _, err := f.NextErr(); !errors.Is(err, ErrStateSave) {
+		t.Errorf("Expected ErrStateSave but got %v", err)
+	}
+	if id := f.Next(); id == 0 {
+		t.Errorf("Expected Next() to ignore the failing store")
+	}
+
+	randReader = failingReader{}
+	defer func() { randReader = rand.Reader }()
+	f = WithClock(&manualClock{now: time.Now().Add(time.Hour)})
+	if _, err := f.NextErr(); !errors.Is(err, ErrEntropy) {
+		t.Errorf("Expected ErrEntropy but got %v", err)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	if MaxFlake != 1<<63-1 {
+		t.Errorf("Expected MaxFlake to be the largest int64 but got %d", MaxFlake)
+	}
+	if DefaultLayout.borrowed(SequenceCapacity-1) != 0 || DefaultLayout.borrowed(SequenceCapacity) != 1 {
+		t.Errorf("Expected the sequence to be exhausted after %d IDs", SequenceCapacity)
+	}
+	if Throughput < 3900000 || Throughput > 3920000 {
+		t.Errorf("Unexpected throughput of %.0f IDs per second", Throughput)
+	}
+	if years := EpochLength.Hours() / 24 / 365; years < 146 || years > 147 {
+		t.Errorf("Unexpected epoch length of %.2f years", years)
+	}
+	if MachineIds != 256 {
+		t.Errorf("Expected 256 machine-ids but got %d", MachineIds)
+	}
+}
+
+func TestNextN(t *testing.T) {
+	f := Raw.WithMachineId(1)
+	ids := f.NextN(10000)
+	if len(ids) != 10000 {
+		t.Errorf("Expected 10000 IDs but got %d", len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Errorf("Expected ascending IDs but got %d after %d", ids[i], ids[i-1])
+			return
+		}
+	}
+	if next := f.Next(); next <= ids[len(ids)-1] {
+		t.Errorf("Expected Next() to continue after the batch but got %d", next)
+	}
+
+	m := make(map[Flake]int)
+	for i, id := range WithMachineId(1).NextN(10000) {
+		if prev, ok := m[id]; ok {
+			t.Errorf("doubble @ %d and %d with %d", i, prev, id)
+			return
+		}
+		m[id] = i
+	}
+
+	dst := f.AppendNext([]Flake{1, 2}, 100)
+	if len(dst) != 102 || dst[0] != 1 || dst[1] != 2 || dst[2] <= 2 {
+		t.Errorf("Expected 100 IDs appended to the slice but got %v", dst[:3])
+	}
+	if allocs := testing.AllocsPerRun(10, func() { dst = f.AppendNext(dst[:0], 100) }); allocs != 0 {
+		t.Errorf("Expected no allocations appending into a reused slice but got %.0f", allocs)
+	}
+	if ids := f.NextN(0); ids == nil || len(ids) != 0 {
+		t.Errorf("Expected an empty slice but got %v", ids)
+	}
+
+	// Runs are shortened to the sequence left within the interval
+	exhausted := 0
+	g := exhaustRunning(Raw.WithSequencePolicy(SequenceSpin, func(time.Duration) { exhausted++ }), 200*time.Millisecond)
+	g.state = pack(0, SequenceCapacity-3)
+	ids = g.NextN(5)
+	if interval := ids[1] >> (sequenceBits + machineIdBits); interval != 0 || exhausted != 1 {
+		t.Errorf("Expected two IDs within the interval before spinning but got interval %d", interval)
+	}
+	if interval := ids[2] >> (sequenceBits + machineIdBits); interval != 1 {
+		t.Errorf("Expected the remaining IDs in the next interval but got interval %d", interval)
+	}
+
+	// or borrow from the following interval
+	exhausted = 0
+	g = exhaust(Raw.WithSequencePolicy(SequenceBorrow, func(time.Duration) { exhausted++ }), 500*time.Millisecond)
+	g.state = pack(0, SequenceCapacity-3)
+	ids = g.NextN(5)
+	if interval := ids[4] >> (sequenceBits + machineIdBits); interval != 1 || exhausted != 1 {
+		t.Errorf("Expected borrowed IDs of the next interval but got interval %d and %d callbacks", interval, exhausted)
+	}
+}
+
+// shuffleLoop is the original bit by bit transposition the shuffle must match
+func shuffleLoop(raw int64) int64 {
+	uid := make([]byte, 8, 8)
+	for i := int64(0); i < 8; i++ {
+		for l := int64(0); l < 8; l++ {
+			uid[l] |= byte((raw & (1 << (i*8 + l))) >> (i*7 + l))
+		}
+	}
+	return int64(binary.LittleEndian.Uint64(uid))
+}
+
+func TestShuffle(t *testing.T) {
+	// The shuffle is a bit permutation, so matching the mapping of each
+	// single bit proves the equality for all inputs
+	for bit := uint(0); bit < 64; bit++ {
+		raw := int64(1) << bit
+		if got, expected := shuffle(raw), shuffleLoop(raw); got != expected {
+			t.Errorf("Bit %d mapped to %x instead of %x", bit, uint64(got), uint64(expected))
+		}
+		if expected := int64(1) << (bit%8*8 + bit/8); shuffle(raw) != expected {
+			t.Errorf("Bit %d isn't transposed", bit)
+		}
+	}
+	r := mathrand.New(mathrand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		raw := int64(r.Uint64())
+		if shuffle(raw) != shuffleLoop(raw) || shuffle(shuffle(raw)) != raw {
+			t.Errorf("Shuffle of %x differs from the reference or isn't its own inverse", uint64(raw))
+			return
+		}
+		if raw >= 0 && shuffle(raw) < 0 {
+			t.Errorf("Shuffle of %x turned negative", raw)
+			return
+		}
+	}
+}
+
+var shuffled int64
+
+func BenchmarkShuffle(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		shuffled = shuffle(int64(i))
+	}
+}
+
+func BenchmarkNext(b *testing.B) {
+	f := WithMachineId(1)
+	for i := 0; i < b.N; i++ {
+		f.Next()
+	}
+}
+
+func BenchmarkNextRaw(b *testing.B) {
+	f := Raw.WithMachineId(1)
+	for i := 0; i < b.N; i++ {
+		f.Next()
+	}
+}
+
+// BenchmarkNextConcurrent measures the contention on a single generator with
+// a fixed count of goroutines independent of GOMAXPROCS
+func BenchmarkNextConcurrent(b *testing.B) {
+	for _, n := range []int{1, 2, 4, 8, 16, 32, 64} {
+		b.Run(fmt.Sprintf("goroutines=%d", n), func(b *testing.B) {
+			f := WithMachineId(1)
+			w := sync.WaitGroup{}
+			for i := 0; i < n; i++ {
+				w.Add(1)
+				go func(count int) {
+					defer w.Done()
+					for j := 0; j < count; j++ {
+						f.Next()
+					}
+				}((b.N + i) / n)
+			}
+			w.Wait()
+		})
+	}
+}
+
+func BenchmarkNextParallel(b *testing.B) {
+	f := WithMachineId(1)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			f.Next()
+		}
+	})
+}
+
+func BenchmarkNextN(b *testing.B) {
+	f := WithMachineId(1)
+	dst := make([]Flake, 0, 1000)
+	for i := 0; i < b.N; i += 1000 {
+		dst = f.AppendNext(dst[:0], 1000)
+	}
+}
@@ -0,0 +1,128 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: flakepb/flake.proto
+
+package flakepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Flake is a flake ID, the 63 bit unique ID of go-flake. Declare ID fields
+// with it to keep the type of the IDs in APIs. Plain int64 fields carry the
+// raw value of the flake too, string fields one of its encodings.
+type Flake struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// value is the flake as sfixed64 as flakes use the upper bits and would
+	// take 9 bytes as varint
+	Value         int64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Flake) Reset() {
+	*x = Flake{}
+	mi := &file_flakepb_flake_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Flake) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Flake) ProtoMessage() {}
+
+func (x *Flake) ProtoReflect() protoreflect.Message {
+	mi := &file_flakepb_flake_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Flake.ProtoReflect.Descriptor instead.
+func (*Flake) Descriptor() ([]byte, []int) {
+	return file_flakepb_flake_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Flake) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+var File_flakepb_flake_proto protoreflect.FileDescriptor
+
+const file_flakepb_flake_proto_rawDesc = "" +
+	"\n" +
+	"\x13flakepb/flake.proto\x12\n" +
+	"goflake.v1\"\x1d\n" +
+	"\x05Flake\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x10R\x05valueB\x1aZ\x18go-flake/flakepb;flakepbb\x06proto3"
+
+var (
+	file_flakepb_flake_proto_rawDescOnce sync.Once
+	file_flakepb_flake_proto_rawDescData []byte
+)
+
+func file_flakepb_flake_proto_rawDescGZIP() []byte {
+	file_flakepb_flake_proto_rawDescOnce.Do(func() {
+		file_flakepb_flake_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_flakepb_flake_proto_rawDesc), len(file_flakepb_flake_proto_rawDesc)))
+	})
+	return file_flakepb_flake_proto_rawDescData
+}
+
+var file_flakepb_flake_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_flakepb_flake_proto_goTypes = []any{
+	(*Flake)(nil), // 0: goflake.v1.Flake
+}
+var file_flakepb_flake_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_flakepb_flake_proto_init() }
+func file_flakepb_flake_proto_init() {
+	if File_flakepb_flake_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_flakepb_flake_proto_rawDesc), len(file_flakepb_flake_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_flakepb_flake_proto_goTypes,
+		DependencyIndexes: file_flakepb_flake_proto_depIdxs,
+		MessageInfos:      file_flakepb_flake_proto_msgTypes,
+	}.Build()
+	File_flakepb_flake_proto = out.File
+	file_flakepb_flake_proto_goTypes = nil
+	file_flakepb_flake_proto_depIdxs = nil
+}
@@ -0,0 +1,48 @@
+// Package flakepb carries flakes in protobuf messages, either as the Flake
+// message of flake.proto or mapped to the well-known wrapper types.
+package flakepb
+
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative flakepb/flake.proto
+
+import (
+	"go-flake"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// New returns the Flake message of the flake
+func New(f flake.Flake) *Flake {
+	return &Flake{Value: int64(f)}
+}
+
+// AsFlake returns the flake of the message, flake.Nil for a nil message
+func (x *Flake) AsFlake() flake.Flake {
+	return flake.Flake(x.GetValue())
+}
+
+// Int64Value returns the flake as Int64Value wrapper
+func Int64Value(f flake.Flake) *wrapperspb.Int64Value {
+	return wrapperspb.Int64(int64(f))
+}
+
+// FromInt64Value returns the flake of the Int64Value wrapper, flake.Nil for a
+// nil wrapper
+func FromInt64Value(v *wrapperspb.Int64Value) flake.Flake {
+	return flake.Flake(v.GetValue())
+}
+
+// StringValue returns the flake as StringValue wrapper of the format
+func StringValue(f flake.Flake, format flake.Format) *wrapperspb.StringValue {
+	return wrapperspb.String(f.Encode(format))
+}
+
+// FromStringValue decodes the flake of the StringValue wrapper of the format
+// like StringValue encodes it, flake.FormatUnknown decodes any format detected
+// by flake.Decode. A nil wrapper returns flake.Nil.
+func FromStringValue(v *wrapperspb.StringValue, format flake.Format) (flake.Flake, error) {
+	if v == nil {
+		return flake.Nil, nil
+	} else if format == flake.FormatUnknown {
+		return flake.Decode(v.GetValue())
+	}
+	return flake.DecodeFormat(v.GetValue(), format)
+}
@@ -0,0 +1,48 @@
+package flakepb
+
+import (
+	"testing"
+
+	"go-flake"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestFlake(t *testing.T) {
+	f := flake.Next()
+	data, err := proto.Marshal(New(f))
+	if err != nil || len(data) != 9 {
+		t.Errorf("Expected 9 bytes of the fixed field but got %d: %v", len(data), err)
+	}
+	var x Flake
+	if err := proto.Unmarshal(data, &x); err != nil || x.AsFlake() != f {
+		t.Errorf("Expected %d but got %d: %v", f, x.AsFlake(), err)
+	}
+	if (*Flake)(nil).AsFlake() != flake.Nil {
+		t.Errorf("Expected Nil of a nil message")
+	}
+}
+
+func TestWrappers(t *testing.T) {
+	f := flake.Next()
+	if FromInt64Value(Int64Value(f)) != f || FromInt64Value(nil) != flake.Nil {
+		t.Errorf("Expected the flake of the Int64Value")
+	}
+	for _, format := range []flake.Format{flake.FormatBase64, flake.FormatHex, flake.FormatBase32, flake.FormatBase58, flake.FormatDecimal} {
+		if g, err := FromStringValue(StringValue(f, format), format); g != f || err != nil {
+			t.Errorf("Expected %d of the %s StringValue but got %d: %v", f, format, g, err)
+		}
+	}
+	if g, err := FromStringValue(StringValue(f, flake.FormatHex), flake.FormatUnknown); g != f || err != nil {
+		t.Errorf("Expected %d of the detected StringValue but got %d: %v", f, g, err)
+	}
+	if g, err := FromStringValue(nil, flake.FormatBase64); g != flake.Nil || err != nil {
+		t.Errorf("Expected Nil of a nil wrapper but got %d: %v", g, err)
+	}
+	if _, err := FromStringValue(wrapperspb.String("invalid"), flake.FormatUnknown); err == nil {
+		t.Errorf("Expected an error of an invalid StringValue")
+	}
+	if _, err := FromStringValue(wrapperspb.String("SlZSRgawRPY"), flake.FormatBase58); err == nil {
+		t.Errorf("Expected an error of a StringValue of another format")
+	}
+}
@@ -0,0 +1,10 @@
+module go-flake/flakepb
+
+go 1.23
+
+require (
+	go-flake v0.0.0
+	google.golang.org/protobuf v1.36.12
+)
+
+replace go-flake => ../
@@ -0,0 +1,160 @@
+// Package flaketest provides conformance tests of Flaker implementations,
+// e.g. of custom layouts, providers or forks. Run them from a test:
+//
+//	func TestFlaker(t *testing.T) {
+//		flaketest.Run(t, myFlaker)
+//	}
+package flaketest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-flake"
+)
+
+// Concurrency is the count of goroutines of TestUniqueness
+var Concurrency = 8
+
+// Count is the count of IDs each goroutine of TestUniqueness generates. It's
+// capped to 10 seconds of the throughput of the layout to not borrow far ahead
+// of the clock, which would fail the time checks of the other tests.
+var Count = 20000
+
+// formats are the formats of the round-trips
+var formats = []flake.Format{flake.FormatHex, flake.FormatBase32, flake.FormatBase64, flake.FormatBase58, flake.FormatDecimal}
+
+// Run runs all conformance tests against the flaker as subtests
+func Run(t *testing.T, flaker flake.Flaker) {
+	t.Run("Layout", func(t *testing.T) { TestLayout(t, flaker) })
+	t.Run("RoundTrip", func(t *testing.T) { TestRoundTrip(t, flaker) })
+	t.Run("Order", func(t *testing.T) { TestOrder(t, flaker) })
+	t.Run("Uniqueness", func(t *testing.T) { TestUniqueness(t, flaker) })
+}
+
+// TestUniqueness checks that the IDs generated concurrently by Next, NextErr
+// and NextN are unique and not Nil
+func TestUniqueness(t *testing.T, flaker flake.Flaker) {
+	count := min(Count, int(10*flaker.Layout().Throughput())/Concurrency)
+	batches := make([][]flake.Flake, Concurrency)
+	wg := sync.WaitGroup{}
+	for i := range batches {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ids := make([]flake.Flake, 0, count)
+			for len(ids) < count {
+				switch len(ids) % 3 {
+				case 0:
+					ids = append(ids, flaker.Next())
+				case 1:
+					id, err := flaker.NextErr()
+					if err != nil {
+						t.Errorf("Expected no error but got %v", err)
+						return
+					}
+					ids = append(ids, id)
+				default:
+					ids = flaker.AppendNext(ids, min(100, count-len(ids)))
+				}
+			}
+			batches[i] = ids
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[flake.Flake]bool, Concurrency*count)
+	for _, ids := range batches {
+		for _, id := range ids {
+			if id == flake.Nil {
+				t.Fatalf("Generated Nil")
+			} else if seen[id] {
+				t.Fatalf("Generated %d twice", id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+// TestOrder checks that the IDs of a single goroutine increase in ModeRaw.
+// Other modes are skipped.
+func TestOrder(t *testing.T, flaker flake.Flaker) {
+	if flaker.Mode() != flake.ModeRaw {
+		t.Skipf("IDs of mode %s are unordered", flaker.Mode())
+	}
+	prev := flaker.Next()
+	for i := 0; i < 1000; i++ {
+		id := flaker.Next()
+		if id <= prev {
+			t.Fatalf("Expected an ID greater than %d but got %d", prev, id)
+		}
+		prev = id
+	}
+}
+
+// TestRoundTrip checks the encodings, the shuffling and the inspection of
+// generated IDs
+func TestRoundTrip(t *testing.T, flaker flake.Flaker) {
+	before := time.Now()
+	for _, id := range flaker.NextN(100) {
+		for _, format := range formats {
+			s := id.Encode(format)
+			if decoded, err := flake.DecodeFormat(s, format); err != nil || decoded != id {
+				t.Fatalf("Expected %d of the %s encoding %q but got %d: %v", id, format, s, decoded, err)
+			}
+			if format == flake.FormatDecimal || format == flake.FormatBase58 {
+				continue // not detected by Decode
+			}
+			if decoded, err := flake.Decode(s); err != nil || decoded != id {
+				t.Fatalf("Expected %d of the detected %s encoding %q but got %d: %v", id, format, s, decoded, err)
+			}
+		}
+		if decoded, err := flake.FromBytes(id.Bytes()); err != nil || decoded != id {
+			t.Fatalf("Expected %d of the bytes but got %d: %v", id, decoded, err)
+		}
+		if raw := flaker.Unshuffle(id); flaker.Shuffle(raw) != id {
+			t.Fatalf("Expected to shuffle the unshuffled %d back but got %d", id, flaker.Shuffle(raw))
+		}
+
+		info, err := flaker.Inspect(id)
+		if flaker.Mode() == flake.ModeRandom {
+			if !errors.Is(err, flake.ErrInvalidConfig) {
+				t.Fatalf("Expected ErrInvalidConfig of inspecting a random ID but got %v", err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("Expected no error inspecting %d but got %v", id, err)
+		}
+		if info.Flake != id || info.MachineId != flaker.MachineId() {
+			t.Fatalf("Expected %d of machine-id %d but got %+v", id, flaker.MachineId(), info)
+		}
+		// Borrowing may run ahead of the clock
+		if length := flaker.Layout().IntervalLength(); info.Time.Before(before.Add(-length)) || info.Time.After(time.Now().Add(time.Minute)) {
+			t.Fatalf("Expected a time of %d around %s but got %s", id, before, info.Time)
+		}
+	}
+}
+
+// TestLayout checks the layout and that generated IDs are within it and valid
+func TestLayout(t *testing.T, flaker flake.Flaker) {
+	layout := flaker.Layout()
+	if err := layout.Validate(); err != nil {
+		t.Fatalf("Expected a valid layout but got %v", err)
+	}
+	if int(flaker.MachineId()) >= layout.MachineIds() {
+		t.Errorf("Expected a machine-id below %d but got %d", layout.MachineIds(), flaker.MachineId())
+	}
+	if end := flaker.EpochEnd(); !end.Equal(flaker.EpochStart().Add(layout.EpochLength())) {
+		t.Errorf("Expected the epoch end %s after the epoch length but got %s", flaker.EpochStart().Add(layout.EpochLength()), end)
+	}
+	for _, id := range flaker.NextN(1000) {
+		if id <= flake.Nil || id > layout.MaxFlake() {
+			t.Fatalf("Expected an ID within 1..%d but got %d", layout.MaxFlake(), id)
+		}
+		if err := flaker.Validate(id, flaker.MachineId()); err != nil {
+			t.Fatalf("Expected %d to be valid but got %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package flaketest
+
+import (
+	"testing"
+
+	"go-flake"
+)
+
+func TestRun(t *testing.T) {
+	highEntropy, err := flake.New(flake.SetLayout(flake.HighEntropyLayout), flake.SetMachineId(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, flaker := range map[string]flake.Flaker{
+		"shuffled":     flake.WithMachineId(1),
+		"raw":          flake.RawWithMachineId(2),
+		"random":       flake.WithMode(flake.ModeRandom),
+		"high-entropy": highEntropy,
+		"shuffle-key":  flake.WithShuffleKey([]byte("secret")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			Run(t, flaker)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package flaketest
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go-flake"
+)
+
+// DeterministicStart is the start of the Clock of NewDeterministic without
+// clock
+var DeterministicStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is a flake.Clock advancing by a fixed step on every reading, which
+// makes the times of generated IDs reproducible.
+type Clock struct {
+	mutex sync.Mutex
+	now   time.Time
+	step  time.Duration
+}
+
+// NewClock returns a Clock reading start first and advancing by step after
+// each reading
+func NewClock(start time.Time, step time.Duration) *Clock {
+	return &Clock{now: start, step: step}
+}
+
+// Now returns the current time of the clock and advances it by the step
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+// Advance moves the clock by d, e.g. into the next interval
+func (c *Clock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// NewDeterministic returns a Flaker generating the same IDs on every run for
+// snapshot tests and golden files: machine-id 0, the default epoch start and
+// shuffle key, random bits of a PCG seeded with seed and the times of clock.
+// A nil clock is a Clock from DeterministicStart advancing by a millisecond.
+// Generating from several goroutines is only reproducible in the order of the
+// calls. Its IDs are not unique to the IDs of other generators, never use it
+// in production.
+func NewDeterministic(seed uint64, clock flake.Clock) flake.Flaker {
+	if clock == nil {
+		clock = NewClock(DeterministicStart, time.Millisecond)
+	}
+	flaker, err := flake.New(
+		flake.SetMachineId(0),
+		flake.SetClock(clock),
+		flake.SetEntropy(&seeded{rand: rand.New(rand.NewPCG(seed, seed))}),
+	)
+	if err != nil {
+		panic(err) // not reached with valid options
+	}
+	return flaker
+}
+
+// seeded is a flake.Entropy of a seeded pseudo-random generator
+type seeded struct {
+	rand *rand.Rand
+}
+
+// Read fills p with pseudo-random bytes
+func (s *seeded) Read(p []byte) (int, error) {
+	var b [8]byte
+	for i := 0; i < len(p); i += 8 {
+		binary.LittleEndian.PutUint64(b[:], s.rand.Uint64())
+		copy(p[i:], b[:])
+	}
+	return len(p), nil
+}
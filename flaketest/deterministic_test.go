@@ -0,0 +1,39 @@
+package flaketest
+
+import (
+	"testing"
+	"time"
+
+	"go-flake"
+)
+
+func TestNewDeterministic(t *testing.T) {
+	a, b := NewDeterministic(42, nil).NextN(1000), NewDeterministic(42, nil).NextN(1000)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Expected the same IDs of the same seed but got %d and %d at %d", a[i], b[i], i)
+		}
+	}
+	if c := NewDeterministic(43, nil).Next(); c == a[0] {
+		t.Errorf("Expected another ID of another seed")
+	}
+
+	g := NewDeterministic(1, nil)
+	info, err := g.Inspect(g.Next())
+	if err != nil || info.MachineId != 0 || info.Time.After(DeterministicStart) || DeterministicStart.Sub(info.Time) > time.Second {
+		t.Errorf("Expected an ID of machine-id 0 at %s but got %+v: %v", DeterministicStart, info, err)
+	}
+
+	clock := NewClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), 0)
+	g = NewDeterministic(1, clock)
+	first := g.Next()
+	clock.Advance(time.Hour)
+	info, _ = g.Inspect(g.Next())
+	if expected := time.Date(2030, 1, 1, 1, 0, 0, 0, time.UTC); info.Time.After(expected) || expected.Sub(info.Time) > time.Second {
+		t.Errorf("Expected an ID of the advanced clock at %s but got %s", expected, info.Time)
+	}
+	if again := NewDeterministic(1, NewClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), 0)).Next(); again != first {
+		t.Errorf("Expected %d of the same clock and seed but got %d", first, again)
+	}
+	Run(t, NewDeterministic(7, NewClock(time.Now(), time.Microsecond)).WithMode(flake.ModeRaw))
+}
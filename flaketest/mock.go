@@ -0,0 +1,235 @@
+package flaketest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go-flake"
+)
+
+// ErrExhausted is returned by the Mock when its script has no IDs left.
+var ErrExhausted = errors.New("mock script exhausted")
+
+// Call is a recorded method call of a Mock
+type Call struct {
+	// Method is the name of the called method, e.g. NextN
+	Method string
+	// N is the count of requested IDs
+	N int
+}
+
+// step is a scripted result of a Mock
+type step struct {
+	id  flake.Flake
+	err error
+}
+
+// Mock is a Flaker returning a scripted sequence of IDs and errors, which
+// records the calls of the generation and With methods. The With methods
+// return the Mock itself to keep the script. Inspect, Validate, Shuffle and
+// the other methods are those of the embedded Flaker, a NewDeterministic one
+// by default.
+type Mock struct {
+	flake.Flaker
+	mutex  sync.Mutex
+	script []step
+	calls  []Call
+}
+
+// NewMock returns a Mock returning the ids in order
+func NewMock(ids ...flake.Flake) *Mock {
+	m := &Mock{Flaker: NewDeterministic(0, nil)}
+	m.Script(ids...)
+	return m
+}
+
+// Script appends the ids to the script
+func (m *Mock) Script(ids ...flake.Flake) *Mock {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, id := range ids {
+		m.script = append(m.script, step{id: id})
+	}
+	return m
+}
+
+// ScriptErr appends an error to the script, which the next generating call
+// returns. Next returns flake.Nil for it.
+func (m *Mock) ScriptErr(err error) *Mock {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.script = append(m.script, step{err: err})
+	return m
+}
+
+// Calls returns the recorded calls in order
+func (m *Mock) Calls() []Call {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// Count returns the count of recorded calls of the method
+func (m *Mock) Count(method string) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	count := 0
+	for _, call := range m.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// Remaining returns the count of scripted steps left
+func (m *Mock) Remaining() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.script)
+}
+
+// record records the call and returns the next n scripted IDs. It stops at
+// and consumes a scripted error.
+func (m *Mock) record(method string, n int) ([]flake.Flake, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.calls = append(m.calls, Call{Method: method, N: n})
+	ids := make([]flake.Flake, 0, n)
+	for len(ids) < n {
+		if len(m.script) == 0 {
+			return ids, ErrExhausted
+		}
+		s := m.script[0]
+		m.script = m.script[1:]
+		if s.err != nil {
+			return ids, s.err
+		}
+		ids = append(ids, s.id)
+	}
+	return ids, nil
+}
+
+// with records the call of a With method and returns the Mock
+func (m *Mock) with(method string) flake.Flaker {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.calls = append(m.calls, Call{Method: method})
+	return m
+}
+
+// Next returns the next scripted ID or flake.Nil for an error
+func (m *Mock) Next() flake.Flake {
+	id, _ := m.next("Next")
+	return id
+}
+
+// NextErr returns the next scripted ID or error, ErrExhausted at the end
+func (m *Mock) NextErr() (flake.Flake, error) {
+	return m.next("NextErr")
+}
+
+// NextKind returns the next scripted ID or error ignoring the kind
+func (m *Mock) NextKind(byte) (flake.Flake, error) {
+	return m.next("NextKind")
+}
+
+// NextContext works like NextErr but returns the error of a done context
+// without consuming the script
+func (m *Mock) NextContext(ctx context.Context) (flake.Flake, error) {
+	if err := ctx.Err(); err != nil {
+		m.with("NextContext")
+		return flake.Nil, err
+	}
+	return m.next("NextContext")
+}
+
+// next returns the next scripted ID recording the method
+func (m *Mock) next(method string) (flake.Flake, error) {
+	ids, err := m.record(method, 1)
+	if err != nil {
+		return flake.Nil, err
+	}
+	return ids[0], nil
+}
+
+// NextN returns the next n scripted IDs, fewer up to a scripted error or the
+// end of the script
+func (m *Mock) NextN(n int) []flake.Flake {
+	ids, _ := m.record("NextN", n)
+	return ids
+}
+
+// AppendNext appends the next n scripted IDs to dst like NextN
+func (m *Mock) AppendNext(dst []flake.Flake, n int) []flake.Flake {
+	ids, _ := m.record("AppendNext", n)
+	return append(dst, ids...)
+}
+
+// NextPair returns the next scripted ID as shuffled ID and its unshuffled
+// form of the embedded Flaker
+func (m *Mock) NextPair() (raw, shuffled flake.Flake) {
+	ids, err := m.record("NextPair", 1)
+	if err != nil {
+		return flake.Nil, flake.Nil
+	}
+	return m.Unshuffle(ids[0]), ids[0]
+}
+
+// The With methods, Clone and Restore record the call and return the Mock.
+
+func (m *Mock) WithMachineId(byte) flake.Flaker       { return m.with("WithMachineId") }
+func (m *Mock) WithEpochStart(time.Time) flake.Flaker { return m.with("WithEpochStart") }
+func (m *Mock) WithMode(flake.Mode) flake.Flaker      { return m.with("WithMode") }
+func (m *Mock) WithClock(flake.Clock) flake.Flaker    { return m.with("WithClock") }
+func (m *Mock) Clone() flake.Flaker                   { return m.with("Clone") }
+
+func (m *Mock) WithClockPolicy(flake.ClockPolicy, func(time.Duration)) flake.Flaker {
+	return m.with("WithClockPolicy")
+}
+
+func (m *Mock) WithEpochWarning(time.Duration, func(time.Duration)) flake.Flaker {
+	return m.with("WithEpochWarning")
+}
+
+func (m *Mock) WithEpochPolicy(flake.EpochPolicy, func(time.Duration)) flake.Flaker {
+	return m.with("WithEpochPolicy")
+}
+
+func (m *Mock) WithSequencePolicy(flake.SequencePolicy, func(time.Duration)) flake.Flaker {
+	return m.with("WithSequencePolicy")
+}
+
+func (m *Mock) WithEntropyPolicy(flake.EntropyPolicy, func(error)) flake.Flaker {
+	return m.with("WithEntropyPolicy")
+}
+
+func (m *Mock) WithEntropySource(flake.EntropySource) flake.Flaker {
+	return m.with("WithEntropySource")
+}
+
+func (m *Mock) WithEntropy(flake.Entropy) flake.Flaker        { return m.with("WithEntropy") }
+func (m *Mock) WithEventHook(func(flake.Event)) flake.Flaker  { return m.with("WithEventHook") }
+func (m *Mock) WithReserved(...flake.Reserved) flake.Flaker   { return m.with("WithReserved") }
+func (m *Mock) WithShuffleKey([]byte) flake.Flaker            { return m.with("WithShuffleKey") }
+func (m *Mock) WithObfuscator(*flake.Obfuscator) flake.Flaker { return m.with("WithObfuscator") }
+
+func (m *Mock) WithStateStore(flake.StateStore) (flake.Flaker, error) {
+	return m.with("WithStateStore"), nil
+}
+
+func (m *Mock) Restore([]byte) (flake.Flaker, error) {
+	return m.with("Restore"), nil
+}
+
+func (m *Mock) WithClockCheck(flake.ClockReference, time.Duration, func(time.Duration)) (flake.Flaker, error) {
+	return m.with("WithClockCheck"), nil
+}
+
+func (m *Mock) WithEncryptionKey([]byte) (flake.Flaker, error) {
+	return m.with("WithEncryptionKey"), nil
+}
+
+var _ flake.Flaker = (*Mock)(nil)
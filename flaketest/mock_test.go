@@ -0,0 +1,52 @@
+package flaketest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-flake"
+)
+
+func TestMock(t *testing.T) {
+	failure := errors.New("failure")
+	m := NewMock(1, 2, 3).ScriptErr(failure).Script(4, 5, 6)
+	var flaker flake.Flaker = m
+	if id := flaker.Next(); id != 1 {
+		t.Errorf("Expected 1 but got %d", id)
+	}
+	if ids := flaker.WithMachineId(3).NextN(5); len(ids) != 2 || ids[1] != 3 {
+		t.Errorf("Expected 2 and 3 up to the error but got %v", ids)
+	}
+	if _, err := flaker.NextErr(); err != nil {
+		t.Errorf("Expected the error to be consumed by NextN but got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := flaker.NextContext(ctx); err != context.Canceled || m.Remaining() != 2 {
+		t.Errorf("Expected context.Canceled without consuming the script but got %v with %d left", err, m.Remaining())
+	}
+	raw, shuffled := flaker.NextPair()
+	if shuffled != 5 || flaker.Shuffle(raw) != 5 {
+		t.Errorf("Expected the pair of 5 but got %d, %d", raw, shuffled)
+	}
+	if ids := flaker.AppendNext(nil, 2); len(ids) != 1 || ids[0] != 6 {
+		t.Errorf("Expected 6 up to the end but got %v", ids)
+	}
+	if _, err := flaker.NextErr(); err != ErrExhausted {
+		t.Errorf("Expected ErrExhausted but got %v", err)
+	}
+
+	calls := m.Calls()
+	if len(calls) != 8 || calls[1] != (Call{Method: "WithMachineId"}) || calls[2] != (Call{Method: "NextN", N: 5}) {
+		t.Errorf("Unexpected calls %v", calls)
+	}
+	if m.Count("NextErr") != 2 {
+		t.Errorf("Expected 2 NextErr calls but got %d", m.Count("NextErr"))
+	}
+
+	m = NewMock(m.Flaker.Next())
+	if info, err := m.Inspect(m.Next()); err != nil || info.MachineId != 0 {
+		t.Errorf("Expected to inspect scripted IDs with the embedded Flaker but got %+v: %v", info, err)
+	}
+}
@@ -0,0 +1,104 @@
+package flake
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+)
+
+// ff1 is the format-preserving FF1 cipher of NIST SP 800-38G with AES for
+// bit strings of 63 digits of radix 2 and an empty tweak. It encrypts the
+// lower 63 bits of a flake to another 63 bit value, the sign bit stays
+// untouched.
+type ff1 struct {
+	block cipher.Block
+	p     [16]byte // encrypted P block of the CBC-MAC
+}
+
+// The 63 bits are split into the upper 31 bits A and the lower 32 bits B
+const (
+	ff1Rounds = 10
+	ff1U      = 31
+	ff1V      = 32
+)
+
+// newFF1 returns the FF1 cipher of an AES key of 16, 24 or 32 bytes
+func newFF1(key []byte) (*ff1, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	// P = [1][2][1][radix]^3[10][u mod 256][n]^4[t]^4
+	f := &ff1{block: block}
+	p := [16]byte{1, 2, 1, 0, 0, 2, ff1Rounds, ff1U, 0, 0, 0, ff1U + ff1V, 0, 0, 0, 0}
+	block.Encrypt(f.p[:], p[:])
+	return f, nil
+}
+
+// round returns the output y of the round function for the round i and the
+// half b. As the tweak is empty, Q = [0]^11[i][b]^4 fills a single block. The
+// block q is reused by the rounds as it escapes to the heap.
+func (f *ff1) round(q []byte, i int, b uint64) uint64 {
+	clear(q[:11])
+	q[11] = byte(i)
+	binary.BigEndian.PutUint32(q[12:], uint32(b))
+	for j := range q {
+		q[j] ^= f.p[j]
+	}
+	f.block.Encrypt(q, q)
+	return binary.BigEndian.Uint64(q[:8])
+}
+
+// encode returns the encrypted form of the raw ID
+func (f *ff1) encode(raw int64) int64 {
+	a, b := uint64(raw)>>ff1V&(1<<ff1U-1), uint64(raw)&(1<<ff1V-1)
+	q := make([]byte, 16)
+	for i := 0; i < ff1Rounds; i++ {
+		m := ff1U + i&1
+		a, b = b, (a+f.round(q, i, b))&(1<<m-1)
+	}
+	return int64(a<<ff1V|b) | raw&(-1<<63)
+}
+
+// decode returns the raw form of the encrypted ID
+func (f *ff1) decode(encrypted int64) int64 {
+	a, b := uint64(encrypted)>>ff1V&(1<<ff1U-1), uint64(encrypted)&(1<<ff1V-1)
+	q := make([]byte, 16)
+	for i := ff1Rounds - 1; i >= 0; i-- {
+		m := ff1U + i&1
+		a, b = (b-f.round(q, i, a))&(1<<m-1), a
+	}
+	return int64(a<<ff1V|b) | encrypted&(-1<<63)
+}
+
+// Returns a new Flaker instance copy encrypting its IDs in ModeShuffled with
+// the format-preserving FF1 cipher under the AES key of 16, 24 or 32 bytes
+// instead of shuffling their bits. The encrypted IDs neither leak their time
+// nor the volume of generated IDs, while the owner of the key recovers the
+// sortable raw form with Unshuffle or Decrypt. Encrypting costs 10 AES
+// blocks per ID. An empty key restores the shuffle. The returned errors wrap
+// ErrInvalidConfig.
+func (g *flaker) WithEncryptionKey(key []byte) (Flaker, error) {
+	c := g.derive()
+	c.codec = nil
+	if len(key) > 0 {
+		cipher, err := newFF1(key)
+		if err != nil {
+			return nil, err
+		}
+		c.codec = cipher
+	}
+	return c, nil
+}
+
+// Decrypt returns the raw form of a flake encrypted under the key by a
+// generator of WithEncryptionKey, e.g. to sort or read the time of IDs
+// without the generator. The returned errors wrap ErrInvalidConfig.
+func Decrypt(key []byte, encrypted Flake) (Flake, error) {
+	cipher, err := newFF1(key)
+	if err != nil {
+		return 0, err
+	}
+	return Flake(cipher.decode(int64(encrypted))), nil
+}
@@ -0,0 +1,69 @@
+package flake
+
+import (
+	"encoding/hex"
+	"errors"
+	mathrand "math/rand"
+	"testing"
+)
+
+func TestFF1(t *testing.T) {
+	// Checked against a generic FF1 implementation passing the NIST samples
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	f, _ := newFF1(key)
+	for raw, encrypted := range map[int64]int64{
+		0:                  7407393923982924685,
+		0x0123456789abcdef: 1600115249852708965,
+	} {
+		if f.encode(raw) != encrypted {
+			t.Errorf("Expected %x to encrypt to %d but got %d", raw, encrypted, f.encode(raw))
+		}
+	}
+
+	r := mathrand.New(mathrand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		raw := int64(r.Uint64())
+		if f.decode(f.encode(raw)) != raw || (raw < 0) != (f.encode(raw) < 0) {
+			t.Errorf("Expected the encryption of %x to be reversible and keep the sign", uint64(raw))
+			return
+		}
+	}
+	if _, err := newFF1([]byte("short")); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for an invalid key length but got %v", err)
+	}
+}
+
+func TestWithEncryptionKey(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	f, err := WithMachineId(5).WithEncryptionKey(key)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	first, second := f.Next(), f.Next()
+	raw, _ := Decrypt(key, first)
+	if raw&0xff != 5 || raw != f.Unshuffle(first) || f.Unshuffle(second) <= raw {
+		t.Errorf("Expected sortable raw IDs of machine-id 5")
+	}
+	if f.Shuffle(raw) != first || f.Validate(first, 5) != nil || first == Shuffle(raw) {
+		t.Errorf("Expected the encryption to be reversible, valid and to replace the shuffle")
+	}
+	if g, _ := f.WithEncryptionKey(nil); g.Shuffle(raw) != Shuffle(raw) {
+		t.Errorf("Expected an empty key to restore the shuffle")
+	}
+	if _, err := Decrypt([]byte("short"), first); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for an invalid key but got %v", err)
+	}
+	if _, err := New(SetEncryptionKey([]byte("short"))); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for an invalid key but got %v", err)
+	}
+	if g, _ := New(SetEncryptionKey(key)); g.Unshuffle(first) != raw {
+		t.Errorf("Expected the same encryption for the same key")
+	}
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	f, _ := newFF1([]byte("0123456789abcdef"))
+	for i := 0; i < b.N; i++ {
+		shuffled = f.encode(int64(i))
+	}
+}
@@ -0,0 +1,115 @@
+package flake
+
+import (
+	"bytes"
+	"testing"
+)
+
+// fuzzFormats returns all formats, new encodings join the fuzz tests and their
+// seed corpus by their name
+func fuzzFormats() (formats []Format) {
+	for format := FormatUnknown + 1; format.String() != FormatUnknown.String(); format++ {
+		formats = append(formats, format)
+	}
+	return formats
+}
+
+// fuzzSeeds are the flakes of the seed corpus
+var fuzzSeeds = []Flake{Nil, MinFlake, 42, 0x0123456789abcdef, MaxFlake, -1, -1 << 63}
+
+func FuzzDecode(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		for _, format := range fuzzFormats() {
+			f.Add(seed.Encode(format))
+		}
+	}
+	for _, seed := range []string{"", "0x", "0X7fffffffffffffff", "9223372036854775808", "ABCDEF0123456789", "===========", "-1", "0O0O0O0O0O0"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		if id, err := Decode(s); err == nil {
+			// Lenient input like upper case hex decodes to the canonical form
+			var canonical string
+			for _, format := range []Format{FormatHex, FormatBase32, FormatBase64} {
+				if encodedLen(format) == len(s) {
+					canonical = id.Encode(format)
+				}
+			}
+			if again, err := Decode(canonical); err != nil || again != id {
+				t.Errorf("Decode(%q) = %d but its canonical %q decodes to %d: %v", s, id, canonical, again, err)
+			}
+		} else if _, ok := err.(*DecodeError); !ok {
+			t.Errorf("Decode(%q) returned %T instead of *DecodeError", s, err)
+		}
+		for _, format := range fuzzFormats() {
+			if format == FormatBytes {
+				continue
+			}
+			// Strict decoding only accepts the canonical encoding
+			if id, err := DecodeFormat(s, format); err == nil && id.Encode(format) != s {
+				t.Errorf("DecodeFormat(%q, %s) = %d encoding to %q", s, format, id, id.Encode(format))
+			}
+		}
+		if id, err := Parse(s); err == nil {
+			if id < Nil {
+				t.Errorf("Parse(%q) = %d out of the 63 bit range", s, id)
+			} else if again, err := Parse(id.Decimal()); err != nil || again != id {
+				t.Errorf("Parse(%q) = %d but its decimal parses to %d: %v", s, id, again, err)
+			}
+		}
+		if id, err := FromBytes([]byte(s)); err == nil && !bytes.Equal(id.Bytes(), []byte(s)) {
+			t.Errorf("FromBytes(%x) = %d encoding to %x", s, id, id.Bytes())
+		}
+		var id Flake
+		if err := id.UnmarshalMsgpack([]byte(s)); err == nil && id != Nil {
+			if b, _ := id.MarshalMsgpack(); !bytes.Equal(b, []byte(s)) {
+				t.Errorf("UnmarshalMsgpack(%x) = %d marshalling to %x", s, id, b)
+			}
+		}
+		if err := id.GobDecode([]byte(s)); err == nil {
+			if b, _ := id.GobEncode(); !bytes.Equal(b, []byte(s)) {
+				t.Errorf("GobDecode(%x) = %d encoding to %x", s, id, b)
+			}
+		}
+	})
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(int64(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, v int64) {
+		id := Flake(v)
+		for _, format := range fuzzFormats() {
+			s := id.Encode(format)
+			var decoded Flake
+			var err error
+			switch {
+			case format == FormatBytes:
+				decoded, err = FromBytes([]byte(s))
+			case (format == FormatDecimal || format == FormatBase58) && id < Nil:
+				// Negative values are outside of the 63 bit range
+				if _, err := DecodeFormat(s, format); err == nil {
+					t.Errorf("DecodeFormat(%q, %s) accepted a negative value", s, format)
+				}
+				continue
+			default:
+				decoded, err = DecodeFormat(s, format)
+			}
+			if err != nil || decoded != id {
+				t.Errorf("%d encoded as %s %q decodes to %d: %v", id, format, s, decoded, err)
+			}
+		}
+		b, _ := id.MarshalMsgpack()
+		var decoded Flake
+		if err := decoded.UnmarshalMsgpack(b); err != nil || decoded != id {
+			t.Errorf("%d round-trips msgpack %x to %d: %v", id, b, decoded, err)
+		}
+		b, _ = id.GobEncode()
+		if err := decoded.GobDecode(b); err != nil || decoded != id {
+			t.Errorf("%d round-trips gob %x to %d: %v", id, b, decoded, err)
+		}
+	})
+}
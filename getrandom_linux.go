@@ -0,0 +1,23 @@
+//go:build linux && (amd64 || arm64)
+
+package flake
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// getrandom fills p by getrandom(2) calls, which block only until the kernel
+// entropy pool is initialized after boot
+func getrandom(p []byte) error {
+	for len(p) > 0 {
+		n, _, errno := syscall.Syscall(sysGetrandom, uintptr(unsafe.Pointer(&p[0])), uintptr(len(p)), 0)
+		if errno == syscall.EINTR {
+			continue
+		} else if errno != 0 {
+			return errno
+		}
+		p = p[n:]
+	}
+	return nil
+}
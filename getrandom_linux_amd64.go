@@ -0,0 +1,5 @@
+package flake
+
+// sysGetrandom is the number of the getrandom system call, which the syscall
+// package lacks for amd64
+const sysGetrandom = 318
@@ -0,0 +1,6 @@
+package flake
+
+import "syscall"
+
+// sysGetrandom is the number of the getrandom system call
+const sysGetrandom = syscall.SYS_GETRANDOM
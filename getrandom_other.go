@@ -0,0 +1,14 @@
+//go:build !linux || !(amd64 || arm64)
+
+package flake
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+// getrandom fills p from crypto/rand where getrandom(2) isn't wired up
+func getrandom(p []byte) error {
+	_, err := io.ReadFull(rand.Reader, p)
+	return err
+}
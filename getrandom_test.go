@@ -0,0 +1,41 @@
+package flake
+
+import (
+	"bytes"
+	"math/bits"
+	"testing"
+)
+
+func TestGetrandomEntropy(t *testing.T) {
+	for _, mix := range []bool{false, true} {
+		e := NewGetrandomEntropy(100, mix)
+		p := make([]byte, 1000)
+		if n, err := e.Read(p); n != len(p) || err != nil {
+			t.Errorf("Expected to read %d bytes but got %d: %v", len(p), n, err)
+		}
+		ones := 0
+		for _, b := range p {
+			ones += bits.OnesCount8(b)
+		}
+		if ones < 3700 || ones > 4300 {
+			t.Errorf("Expected ~4000 bits set of 8000 but got %d", ones)
+		}
+		q := make([]byte, 1000)
+		if e.Read(q); bytes.Equal(p, q) {
+			t.Errorf("Expected other bytes of the next read")
+		}
+	}
+
+	f := WithEntropy(NewGetrandomEntropy(1<<16, true))
+	if _, err := f.NextErr(); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+}
+
+func BenchmarkGetrandomEntropy(b *testing.B) {
+	e := NewGetrandomEntropy(1<<16, false)
+	p := make([]byte, 2)
+	for i := 0; i < b.N; i++ {
+		e.Read(p)
+	}
+}
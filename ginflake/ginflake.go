@@ -0,0 +1,27 @@
+// Package ginflake adapts the request ID middleware of httpflake to Gin.
+//
+//	router.Use(ginflake.RequestID(nil))
+package ginflake
+
+import (
+	"github.com/gin-gonic/gin"
+	"go-flake"
+	"go-flake/httpflake"
+)
+
+// RequestID returns the Gin middleware assigning request IDs as configured, a
+// nil config uses the defaults of httpflake.RequestIDs.
+func RequestID(config *httpflake.RequestIDs) gin.HandlerFunc {
+	if config == nil {
+		config = &httpflake.RequestIDs{}
+	}
+	return func(c *gin.Context) {
+		_, c.Request = config.Assign(c.Writer, c.Request)
+		c.Next()
+	}
+}
+
+// FromContext returns the request ID of the Gin context
+func FromContext(c *gin.Context) (flake.Flake, bool) {
+	return httpflake.FromContext(c.Request.Context())
+}
@@ -0,0 +1,35 @@
+package ginflake
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go-flake"
+	"go-flake/httpflake"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var seen flake.Flake
+	router := gin.New()
+	router.Use(RequestID(&httpflake.RequestIDs{Format: flake.FormatHex}))
+	router.GET("/", func(c *gin.Context) {
+		seen, _ = FromContext(c)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if seen == flake.Nil || w.Header().Get(httpflake.HeaderRequestID) != seen.Hex() {
+		t.Errorf("Expected the ID %d in the header but got %q", seen, w.Header().Get(httpflake.HeaderRequestID))
+	}
+
+	incoming := flake.Next()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(httpflake.HeaderRequestID, incoming.Hex())
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if seen != incoming {
+		t.Errorf("Expected the incoming ID %d but got %d", incoming, seen)
+	}
+}
@@ -1,3 +1,3 @@
 module go-flake
 
-go 1.15
+go 1.22
@@ -0,0 +1,26 @@
+package flake
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+)
+
+func init() {
+	// Register to round-trip flakes held by interface values
+	gob.Register(Nil)
+}
+
+// GobEncode encodes the flake as 8 big endian bytes
+func (f Flake) GobEncode() ([]byte, error) {
+	return f.Bytes(), nil
+}
+
+// GobDecode decodes a flake encoded with GobEncode
+func (f *Flake) GobDecode(b []byte) error {
+	if len(b) != 8 {
+		return fmt.Errorf("%w: gob value of %d bytes", ErrInvalidLength, len(b))
+	}
+	*f = Flake(binary.BigEndian.Uint64(b))
+	return nil
+}
@@ -0,0 +1,36 @@
+package flake
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+)
+
+func TestGob(t *testing.T) {
+	type record struct {
+		ID    Flake
+		Refs  []Flake
+		Value any
+	}
+	r := record{ID: Next(), Refs: []Flake{Next(), Nil}, Value: Next()}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		t.Fatal(err)
+	}
+	var decoded record
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != r.ID || len(decoded.Refs) != 2 || decoded.Refs[0] != r.Refs[0] || decoded.Refs[1] != Nil {
+		t.Errorf("Expected %v but got %v", r, decoded)
+	}
+	if value, ok := decoded.Value.(Flake); !ok || value != r.Value {
+		t.Errorf("Expected the flake %v of the interface value but got %#v", r.Value, decoded.Value)
+	}
+
+	var f Flake
+	if err := f.GobDecode(make([]byte, 7)); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength but got %v", err)
+	}
+}
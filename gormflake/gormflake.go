@@ -0,0 +1,143 @@
+// Package gormflake provides the GORM data type of flake IDs and populates
+// their primary keys on create.
+//
+//	type User struct {
+//		gormflake.Model
+//		Name string
+//	}
+package gormflake
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"go-flake"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ID is a flake stored as 64 bit integer column. Declare primary keys with
+// the autoIncrement:false tag as GORM assumes auto incremented integer keys
+// otherwise.
+type ID flake.Flake
+
+// Flake returns the ID as Flake
+func (id ID) Flake() flake.Flake {
+	return flake.Flake(id)
+}
+
+// GormDataType returns the generic data type of the column
+func (ID) GormDataType() string {
+	return "bigint"
+}
+
+// GormDBDataType returns the data type of the column of the dialect
+func (ID) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "sqlite" {
+		return "integer"
+	}
+	return "bigint"
+}
+
+// Scan implements the sql.Scanner interface
+func (id *ID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*id = 0
+	case int64:
+		*id = ID(v)
+	case []byte:
+		return id.Scan(string(v))
+	case string:
+		f, err := flake.Parse(v)
+		if err != nil {
+			return err
+		}
+		*id = ID(f)
+	default:
+		return fmt.Errorf("gormflake: cannot scan %T into ID", src)
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (id ID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+// Model is an embeddable primary key of a model populated with a new flake
+// of flake.Default before the model is created, unless set already. Replace
+// the generator with flake.SetDefault.
+type Model struct {
+	ID ID `gorm:"primaryKey;autoIncrement:false"`
+}
+
+// BeforeCreate sets the ID to a new flake if zero
+func (m *Model) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == 0 {
+		id, err := flake.NextContext(tx.Statement.Context)
+		if err != nil {
+			return err
+		}
+		m.ID = ID(id)
+	}
+	return nil
+}
+
+// Plugin populates zero ID primary keys of all models with new flakes before
+// they are created, so the models don't need to embed Model.
+//
+//	db.Use(gormflake.Plugin{Flaker: flaker})
+type Plugin struct {
+	// Flaker generates the IDs, flake.Default if nil
+	Flaker flake.Flaker
+}
+
+// Name returns the name of the plugin
+func (p Plugin) Name() string {
+	return "gormflake"
+}
+
+// Initialize registers the callback populating the IDs
+func (p Plugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("gormflake:populate", p.populate)
+}
+
+var idType = reflect.TypeOf(ID(0))
+
+// populate sets the zero ID primary keys of the created models
+func (p Plugin) populate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	next := flake.NextContext
+	if p.Flaker != nil {
+		next = p.Flaker.NextContext
+	}
+	ctx := db.Statement.Context
+	set := func(field *schema.Field, model reflect.Value) {
+		if _, zero := field.ValueOf(ctx, model); zero {
+			id, err := next(ctx)
+			if err == nil {
+				err = field.Set(ctx, model, ID(id))
+			}
+			if err != nil {
+				db.AddError(err)
+			}
+		}
+	}
+	for _, field := range db.Statement.Schema.PrimaryFields {
+		if field.FieldType != idType {
+			continue
+		}
+		switch models := db.Statement.ReflectValue; models.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < models.Len(); i++ {
+				set(field, reflect.Indirect(models.Index(i)))
+			}
+		case reflect.Struct:
+			set(field, models)
+		}
+	}
+}
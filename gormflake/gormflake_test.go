@@ -0,0 +1,83 @@
+package gormflake
+
+import (
+	"testing"
+
+	"go-flake"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+type user struct {
+	Model
+	Name string
+}
+
+type order struct {
+	ID     ID `gorm:"primaryKey;autoIncrement:false"`
+	Amount int
+}
+
+func open(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("Opening the database failed: %v", err)
+	}
+	return db
+}
+
+func TestScanValue(t *testing.T) {
+	f := flake.Next()
+	for _, src := range []any{int64(f), []byte(f.Decimal()), f.Decimal()} {
+		var id ID
+		if err := id.Scan(src); err != nil || id.Flake() != f {
+			t.Errorf("Expected to scan %d from %T but got %d: %v", f, src, id, err)
+		}
+	}
+	var id ID
+	if err := id.Scan(1.5); err == nil {
+		t.Errorf("Expected an error scanning a float")
+	}
+	if err := id.Scan("x"); err == nil {
+		t.Errorf("Expected an error scanning an invalid string")
+	}
+	if v, err := ID(f).Value(); v != int64(f) || err != nil {
+		t.Errorf("Expected the value %d but got %v: %v", f, v, err)
+	}
+	if ID(0).GormDataType() != "bigint" || ID(0).GormDBDataType(open(t), nil) != "bigint" {
+		t.Errorf("Expected a bigint column")
+	}
+}
+
+func TestModel(t *testing.T) {
+	db := open(t)
+	u := user{Name: "gopher"}
+	if err := db.Create(&u).Error; err != nil || u.ID == 0 {
+		t.Errorf("Expected the ID to be populated but got %d: %v", u.ID, err)
+	}
+	set := user{Model: Model{ID: 42}}
+	if db.Create(&set); set.ID != 42 {
+		t.Errorf("Expected the set ID to be kept but got %d", set.ID)
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	db := open(t)
+	f := flake.RawWithMachineId(7)
+	if err := db.Use(Plugin{Flaker: f}); err != nil {
+		t.Fatalf("Registering the plugin failed: %v", err)
+	}
+	orders := []order{{Amount: 1}, {ID: 42, Amount: 2}, {Amount: 3}}
+	stmt := db.Create(&orders).Statement
+	if orders[0].ID == 0 || orders[0].ID&0xff != 7 || orders[1].ID != 42 || orders[2].ID <= orders[0].ID {
+		t.Errorf("Expected the zero IDs to be populated by the flaker but got %v", orders)
+	}
+	if len(stmt.Vars) != 6 || stmt.Vars[0] != orders[0].ID {
+		t.Errorf("Expected the IDs to be inserted but got %v", stmt.Vars)
+	}
+
+	o := order{Amount: 4}
+	if db.Create(&o); o.ID == 0 {
+		t.Errorf("Expected the ID of a single model to be populated")
+	}
+}
@@ -0,0 +1,149 @@
+// Package grpcflake implements the IDService of idservice.proto, which serves
+// flake IDs to clients of any language, and a client of it.
+//
+//	grpcflake.RegisterIDServiceServer(server, &grpcflake.Server{Flaker: flaker})
+package grpcflake
+
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative grpcflake/idservice.proto
+
+import (
+	"context"
+	"errors"
+
+	"go-flake"
+	"go-flake/flakepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultMaxBatch is the largest batch of a Server without MaxBatch
+const DefaultMaxBatch = 1000
+
+// Server implements the IDServiceServer with a Flaker.
+type Server struct {
+	UnimplementedIDServiceServer
+	// Flaker generates the IDs, nil uses the package functions of flake.
+	Flaker flake.Flaker
+	// MaxBatch limits the count of GetIDBatch, zero means DefaultMaxBatch.
+	MaxBatch int
+}
+
+// GetID returns a new ID. Errors of the generator result in Unavailable.
+func (s *Server) GetID(ctx context.Context, _ *GetIDRequest) (*GetIDResponse, error) {
+	var id flake.Flake
+	var err error
+	if s.Flaker == nil {
+		id, err = flake.NextContext(ctx)
+	} else {
+		id, err = s.Flaker.NextContext(ctx)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &GetIDResponse{Id: flakepb.New(id)}, nil
+}
+
+// GetIDBatch returns a batch of new IDs. Counts of zero or beyond the limit
+// result in InvalidArgument, errors of the generator in Unavailable like
+// GetID.
+func (s *Server) GetIDBatch(ctx context.Context, req *GetIDBatchRequest) (*GetIDBatchResponse, error) {
+	maxBatch := s.MaxBatch
+	if maxBatch <= 0 {
+		maxBatch = DefaultMaxBatch
+	}
+	n := int(req.GetCount())
+	if n < 1 || n > maxBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "count %d not within 1 and %d", n, maxBatch)
+	}
+	resp := &GetIDBatchResponse{Ids: make([]*flakepb.Flake, n)}
+	for i := range resp.Ids {
+		var id flake.Flake
+		var err error
+		if s.Flaker == nil {
+			id, err = flake.NextContext(ctx)
+		} else {
+			id, err = s.Flaker.NextContext(ctx)
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		resp.Ids[i] = flakepb.New(id)
+	}
+	return resp, nil
+}
+
+// Inspect decomposes an ID of the generator. Invalid IDs result in
+// InvalidArgument, IDs of ModeRandom in FailedPrecondition.
+func (s *Server) Inspect(_ context.Context, req *InspectRequest) (*InspectResponse, error) {
+	var info flake.Info
+	var err error
+	if s.Flaker == nil {
+		info, err = flake.Inspect(req.GetId().AsFlake())
+	} else {
+		info, err = s.Flaker.Inspect(req.GetId().AsFlake())
+	}
+	if errors.Is(err, flake.ErrInvalidConfig) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &InspectResponse{
+		Raw:       flakepb.New(info.Raw),
+		Time:      timestamppb.New(info.Time),
+		Interval:  info.Interval,
+		Sequence:  info.Sequence,
+		MachineId: uint32(info.MachineId),
+	}, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Client fetches IDs from an IDService.
+type Client struct {
+	client IDServiceClient
+}
+
+// NewClient returns a Client of the IDService of the connection
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{client: NewIDServiceClient(conn)}
+}
+
+// Next returns a new ID of the service
+func (c *Client) Next(ctx context.Context) (flake.Flake, error) {
+	resp, err := c.client.GetID(ctx, &GetIDRequest{})
+	if err != nil {
+		return flake.Nil, err
+	}
+	return resp.GetId().AsFlake(), nil
+}
+
+// NextN returns n new IDs of the service
+func (c *Client) NextN(ctx context.Context, n int) ([]flake.Flake, error) {
+	resp, err := c.client.GetIDBatch(ctx, &GetIDBatchRequest{Count: uint32(n)})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]flake.Flake, len(resp.GetIds()))
+	for i, id := range resp.GetIds() {
+		ids[i] = id.AsFlake()
+	}
+	return ids, nil
+}
+
+// Inspect decomposes an ID of the service into its fields
+func (c *Client) Inspect(ctx context.Context, f flake.Flake) (flake.Info, error) {
+	resp, err := c.client.Inspect(ctx, &InspectRequest{Id: flakepb.New(f)})
+	if err != nil {
+		return flake.Info{}, err
+	}
+	return flake.Info{
+		Flake:     f,
+		Raw:       resp.GetRaw().AsFlake(),
+		Time:      resp.GetTime().AsTime(),
+		Interval:  resp.GetInterval(),
+		Sequence:  resp.GetSequence(),
+		MachineId: byte(resp.GetMachineId()),
+	}, nil
+}
@@ -0,0 +1,97 @@
+package grpcflake
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go-flake"
+	"go-flake/flaketest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func dial(t *testing.T, s *Server, opts ...any) *Client {
+	var serverOpts []grpc.ServerOption
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case grpc.ServerOption:
+			serverOpts = append(serverOpts, opt)
+		case grpc.DialOption:
+			dialOpts = append(dialOpts, opt)
+		}
+	}
+	listener := bufconn.Listen(1 << 16)
+	server := grpc.NewServer(serverOpts...)
+	RegisterIDServiceServer(server, s)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	dialOpts = append(dialOpts, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewClient(conn)
+}
+
+func TestService(t *testing.T) {
+	g := flake.WithMachineId(7)
+	c := dial(t, &Server{Flaker: g, MaxBatch: 10})
+	ctx := context.Background()
+
+	id, err := c.Next(ctx)
+	if err != nil || g.Validate(id, 7) != nil {
+		t.Errorf("Expected a valid ID but got %d: %v", id, err)
+	}
+	ids, err := c.NextN(ctx, 10)
+	if err != nil || len(ids) != 10 || ids[0] == ids[9] {
+		t.Errorf("Expected 10 IDs but got %v: %v", ids, err)
+	}
+	info, err := c.Inspect(ctx, id)
+	expected, _ := g.Inspect(id)
+	if err != nil || info.MachineId != 7 || !info.Time.Equal(expected.Time) || info.Raw != expected.Raw || info.Sequence != expected.Sequence {
+		t.Errorf("Expected %+v but got %+v: %v", expected, info, err)
+	}
+
+	for _, n := range []int{0, 11} {
+		if _, err := c.NextN(ctx, n); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %d IDs but got %v", n, err)
+		}
+	}
+	if _, err := c.Inspect(ctx, flake.Nil); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for Nil but got %v", err)
+	}
+}
+
+func TestServiceRandom(t *testing.T) {
+	c := dial(t, &Server{Flaker: flake.WithMode(flake.ModeRandom)})
+	id, err := c.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Inspect(context.Background(), id); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition but got %v", err)
+	}
+}
+
+func TestServiceError(t *testing.T) {
+	// The batch fails at its third ID like on a clock regression with ClockError
+	s := &Server{Flaker: flaketest.NewMock(1, 2).ScriptErr(flake.ErrClockRegression)}
+	if _, err := s.GetIDBatch(context.Background(), &GetIDBatchRequest{Count: 3}); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable of a failing generator but got %v", err)
+	}
+	s.Flaker = flaketest.NewMock().ScriptErr(flake.ErrClockRegression)
+	if _, err := s.GetID(context.Background(), &GetIDRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable of a failing generator but got %v", err)
+	}
+}
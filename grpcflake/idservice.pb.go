@@ -0,0 +1,412 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: grpcflake/idservice.proto
+
+package grpcflake
+
+import (
+	flakepb "go-flake/flakepb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIDRequest) Reset() {
+	*x = GetIDRequest{}
+	mi := &file_grpcflake_idservice_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIDRequest) ProtoMessage() {}
+
+func (x *GetIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcflake_idservice_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIDRequest.ProtoReflect.Descriptor instead.
+func (*GetIDRequest) Descriptor() ([]byte, []int) {
+	return file_grpcflake_idservice_proto_rawDescGZIP(), []int{0}
+}
+
+type GetIDResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *flakepb.Flake         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIDResponse) Reset() {
+	*x = GetIDResponse{}
+	mi := &file_grpcflake_idservice_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIDResponse) ProtoMessage() {}
+
+func (x *GetIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcflake_idservice_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIDResponse.ProtoReflect.Descriptor instead.
+func (*GetIDResponse) Descriptor() ([]byte, []int) {
+	return file_grpcflake_idservice_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetIDResponse) GetId() *flakepb.Flake {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+type GetIDBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// count is the count of IDs, up to the limit of the service
+	Count         uint32 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIDBatchRequest) Reset() {
+	*x = GetIDBatchRequest{}
+	mi := &file_grpcflake_idservice_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIDBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIDBatchRequest) ProtoMessage() {}
+
+func (x *GetIDBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcflake_idservice_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIDBatchRequest.ProtoReflect.Descriptor instead.
+func (*GetIDBatchRequest) Descriptor() ([]byte, []int) {
+	return file_grpcflake_idservice_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetIDBatchRequest) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GetIDBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []*flakepb.Flake       `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIDBatchResponse) Reset() {
+	*x = GetIDBatchResponse{}
+	mi := &file_grpcflake_idservice_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIDBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIDBatchResponse) ProtoMessage() {}
+
+func (x *GetIDBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcflake_idservice_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIDBatchResponse.ProtoReflect.Descriptor instead.
+func (*GetIDBatchResponse) Descriptor() ([]byte, []int) {
+	return file_grpcflake_idservice_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetIDBatchResponse) GetIds() []*flakepb.Flake {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type InspectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *flakepb.Flake         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectRequest) Reset() {
+	*x = InspectRequest{}
+	mi := &file_grpcflake_idservice_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectRequest) ProtoMessage() {}
+
+func (x *InspectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcflake_idservice_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectRequest.ProtoReflect.Descriptor instead.
+func (*InspectRequest) Descriptor() ([]byte, []int) {
+	return file_grpcflake_idservice_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *InspectRequest) GetId() *flakepb.Flake {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+type InspectResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// raw is the unshuffled form of the ID
+	Raw *flakepb.Flake `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
+	// time is the start of the interval of the ID
+	Time *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	// interval is the count of intervals since the epoch start
+	Interval int64 `protobuf:"varint,3,opt,name=interval,proto3" json:"interval,omitempty"`
+	// sequence is the sequence value within the interval including random bits
+	Sequence int64 `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// machine_id is the machine-id of the generator
+	MachineId     uint32 `protobuf:"varint,5,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectResponse) Reset() {
+	*x = InspectResponse{}
+	mi := &file_grpcflake_idservice_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectResponse) ProtoMessage() {}
+
+func (x *InspectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcflake_idservice_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectResponse.ProtoReflect.Descriptor instead.
+func (*InspectResponse) Descriptor() ([]byte, []int) {
+	return file_grpcflake_idservice_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *InspectResponse) GetRaw() *flakepb.Flake {
+	if x != nil {
+		return x.Raw
+	}
+	return nil
+}
+
+func (x *InspectResponse) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *InspectResponse) GetInterval() int64 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *InspectResponse) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *InspectResponse) GetMachineId() uint32 {
+	if x != nil {
+		return x.MachineId
+	}
+	return 0
+}
+
+var File_grpcflake_idservice_proto protoreflect.FileDescriptor
+
+const file_grpcflake_idservice_proto_rawDesc = "" +
+	"\n" +
+	"\x19grpcflake/idservice.proto\x12\n" +
+	"goflake.v1\x1a\x13flakepb/flake.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x0e\n" +
+	"\fGetIDRequest\"2\n" +
+	"\rGetIDResponse\x12!\n" +
+	"\x02id\x18\x01 \x01(\v2\x11.goflake.v1.FlakeR\x02id\")\n" +
+	"\x11GetIDBatchRequest\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\rR\x05count\"9\n" +
+	"\x12GetIDBatchResponse\x12#\n" +
+	"\x03ids\x18\x01 \x03(\v2\x11.goflake.v1.FlakeR\x03ids\"3\n" +
+	"\x0eInspectRequest\x12!\n" +
+	"\x02id\x18\x01 \x01(\v2\x11.goflake.v1.FlakeR\x02id\"\xbd\x01\n" +
+	"\x0fInspectResponse\x12#\n" +
+	"\x03raw\x18\x01 \x01(\v2\x11.goflake.v1.FlakeR\x03raw\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1a\n" +
+	"\binterval\x18\x03 \x01(\x03R\binterval\x12\x1a\n" +
+	"\bsequence\x18\x04 \x01(\x03R\bsequence\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x05 \x01(\rR\tmachineId2\xda\x01\n" +
+	"\tIDService\x12<\n" +
+	"\x05GetID\x12\x18.goflake.v1.GetIDRequest\x1a\x19.goflake.v1.GetIDResponse\x12K\n" +
+	"\n" +
+	"GetIDBatch\x12\x1d.goflake.v1.GetIDBatchRequest\x1a\x1e.goflake.v1.GetIDBatchResponse\x12B\n" +
+	"\aInspect\x12\x1a.goflake.v1.InspectRequest\x1a\x1b.goflake.v1.InspectResponseB\x1eZ\x1cgo-flake/grpcflake;grpcflakeb\x06proto3"
+
+var (
+	file_grpcflake_idservice_proto_rawDescOnce sync.Once
+	file_grpcflake_idservice_proto_rawDescData []byte
+)
+
+func file_grpcflake_idservice_proto_rawDescGZIP() []byte {
+	file_grpcflake_idservice_proto_rawDescOnce.Do(func() {
+		file_grpcflake_idservice_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_grpcflake_idservice_proto_rawDesc), len(file_grpcflake_idservice_proto_rawDesc)))
+	})
+	return file_grpcflake_idservice_proto_rawDescData
+}
+
+var file_grpcflake_idservice_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_grpcflake_idservice_proto_goTypes = []any{
+	(*GetIDRequest)(nil),          // 0: goflake.v1.GetIDRequest
+	(*GetIDResponse)(nil),         // 1: goflake.v1.GetIDResponse
+	(*GetIDBatchRequest)(nil),     // 2: goflake.v1.GetIDBatchRequest
+	(*GetIDBatchResponse)(nil),    // 3: goflake.v1.GetIDBatchResponse
+	(*InspectRequest)(nil),        // 4: goflake.v1.InspectRequest
+	(*InspectResponse)(nil),       // 5: goflake.v1.InspectResponse
+	(*flakepb.Flake)(nil),         // 6: goflake.v1.Flake
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+}
+var file_grpcflake_idservice_proto_depIdxs = []int32{
+	6, // 0: goflake.v1.GetIDResponse.id:type_name -> goflake.v1.Flake
+	6, // 1: goflake.v1.GetIDBatchResponse.ids:type_name -> goflake.v1.Flake
+	6, // 2: goflake.v1.InspectRequest.id:type_name -> goflake.v1.Flake
+	6, // 3: goflake.v1.InspectResponse.raw:type_name -> goflake.v1.Flake
+	7, // 4: goflake.v1.InspectResponse.time:type_name -> google.protobuf.Timestamp
+	0, // 5: goflake.v1.IDService.GetID:input_type -> goflake.v1.GetIDRequest
+	2, // 6: goflake.v1.IDService.GetIDBatch:input_type -> goflake.v1.GetIDBatchRequest
+	4, // 7: goflake.v1.IDService.Inspect:input_type -> goflake.v1.InspectRequest
+	1, // 8: goflake.v1.IDService.GetID:output_type -> goflake.v1.GetIDResponse
+	3, // 9: goflake.v1.IDService.GetIDBatch:output_type -> goflake.v1.GetIDBatchResponse
+	5, // 10: goflake.v1.IDService.Inspect:output_type -> goflake.v1.InspectResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_grpcflake_idservice_proto_init() }
+func file_grpcflake_idservice_proto_init() {
+	if File_grpcflake_idservice_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_grpcflake_idservice_proto_rawDesc), len(file_grpcflake_idservice_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_grpcflake_idservice_proto_goTypes,
+		DependencyIndexes: file_grpcflake_idservice_proto_depIdxs,
+		MessageInfos:      file_grpcflake_idservice_proto_msgTypes,
+	}.Build()
+	File_grpcflake_idservice_proto = out.File
+	file_grpcflake_idservice_proto_goTypes = nil
+	file_grpcflake_idservice_proto_depIdxs = nil
+}
@@ -0,0 +1,207 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: grpcflake/idservice.proto
+
+package grpcflake
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IDService_GetID_FullMethodName      = "/goflake.v1.IDService/GetID"
+	IDService_GetIDBatch_FullMethodName = "/goflake.v1.IDService/GetIDBatch"
+	IDService_Inspect_FullMethodName    = "/goflake.v1.IDService/Inspect"
+)
+
+// IDServiceClient is the client API for IDService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IDService serves flake IDs to clients of any language.
+type IDServiceClient interface {
+	// GetID returns a new ID.
+	GetID(ctx context.Context, in *GetIDRequest, opts ...grpc.CallOption) (*GetIDResponse, error)
+	// GetIDBatch returns a batch of new IDs.
+	GetIDBatch(ctx context.Context, in *GetIDBatchRequest, opts ...grpc.CallOption) (*GetIDBatchResponse, error)
+	// Inspect decomposes an ID of the service into its fields.
+	Inspect(ctx context.Context, in *InspectRequest, opts ...grpc.CallOption) (*InspectResponse, error)
+}
+
+type iDServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIDServiceClient(cc grpc.ClientConnInterface) IDServiceClient {
+	return &iDServiceClient{cc}
+}
+
+func (c *iDServiceClient) GetID(ctx context.Context, in *GetIDRequest, opts ...grpc.CallOption) (*GetIDResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetIDResponse)
+	err := c.cc.Invoke(ctx, IDService_GetID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iDServiceClient) GetIDBatch(ctx context.Context, in *GetIDBatchRequest, opts ...grpc.CallOption) (*GetIDBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetIDBatchResponse)
+	err := c.cc.Invoke(ctx, IDService_GetIDBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iDServiceClient) Inspect(ctx context.Context, in *InspectRequest, opts ...grpc.CallOption) (*InspectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InspectResponse)
+	err := c.cc.Invoke(ctx, IDService_Inspect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IDServiceServer is the server API for IDService service.
+// All implementations must embed UnimplementedIDServiceServer
+// for forward compatibility.
+//
+// IDService serves flake IDs to clients of any language.
+type IDServiceServer interface {
+	// GetID returns a new ID.
+	GetID(context.Context, *GetIDRequest) (*GetIDResponse, error)
+	// GetIDBatch returns a batch of new IDs.
+	GetIDBatch(context.Context, *GetIDBatchRequest) (*GetIDBatchResponse, error)
+	// Inspect decomposes an ID of the service into its fields.
+	Inspect(context.Context, *InspectRequest) (*InspectResponse, error)
+	mustEmbedUnimplementedIDServiceServer()
+}
+
+// UnimplementedIDServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIDServiceServer struct{}
+
+func (UnimplementedIDServiceServer) GetID(context.Context, *GetIDRequest) (*GetIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetID not implemented")
+}
+func (UnimplementedIDServiceServer) GetIDBatch(context.Context, *GetIDBatchRequest) (*GetIDBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIDBatch not implemented")
+}
+func (UnimplementedIDServiceServer) Inspect(context.Context, *InspectRequest) (*InspectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Inspect not implemented")
+}
+func (UnimplementedIDServiceServer) mustEmbedUnimplementedIDServiceServer() {}
+func (UnimplementedIDServiceServer) testEmbeddedByValue()                   {}
+
+// UnsafeIDServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IDServiceServer will
+// result in compilation errors.
+type UnsafeIDServiceServer interface {
+	mustEmbedUnimplementedIDServiceServer()
+}
+
+func RegisterIDServiceServer(s grpc.ServiceRegistrar, srv IDServiceServer) {
+	// If the following call pancis, it indicates UnimplementedIDServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IDService_ServiceDesc, srv)
+}
+
+func _IDService_GetID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IDServiceServer).GetID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IDService_GetID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IDServiceServer).GetID(ctx, req.(*GetIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IDService_GetIDBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIDBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IDServiceServer).GetIDBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IDService_GetIDBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IDServiceServer).GetIDBatch(ctx, req.(*GetIDBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IDService_Inspect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IDServiceServer).Inspect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IDService_Inspect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IDServiceServer).Inspect(ctx, req.(*InspectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IDService_ServiceDesc is the grpc.ServiceDesc for IDService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IDService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "goflake.v1.IDService",
+	HandlerType: (*IDServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetID",
+			Handler:    _IDService_GetID_Handler,
+		},
+		{
+			MethodName: "GetIDBatch",
+			Handler:    _IDService_GetIDBatch_Handler,
+		},
+		{
+			MethodName: "Inspect",
+			Handler:    _IDService_Inspect_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "grpcflake/idservice.proto",
+}
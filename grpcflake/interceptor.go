@@ -0,0 +1,145 @@
+package grpcflake
+
+import (
+	"context"
+
+	"go-flake"
+	"go-flake/httpflake"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey is the default metadata key of correlation IDs
+const MetadataKey = "x-request-id"
+
+// CorrelationIDs provides interceptors carrying a flake correlation ID along
+// chains of RPCs. The server interceptors take the ID of the incoming metadata
+// or generate a new one, store it in the context and return it as header. The
+// client interceptors send the ID of the context or a new one. The ID shares
+// the context of the request IDs of httpflake, so it carries over from HTTP to
+// gRPC as well.
+//
+//	ids := &grpcflake.CorrelationIDs{}
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(ids.UnaryServerInterceptor()),
+//		grpc.StreamInterceptor(ids.StreamServerInterceptor()))
+type CorrelationIDs struct {
+	// Flaker generates the IDs, nil uses the package functions of flake.
+	Flaker flake.Flaker
+	// Key is the metadata key of the ID, empty means MetadataKey.
+	Key string
+	// Format is the encoding of the ID in the metadata, FormatUnknown means
+	// FormatBase64.
+	Format flake.Format
+	// IgnoreIncoming generates a new ID even if the incoming metadata carries
+	// one, e.g. for public services not trusting their clients.
+	IgnoreIncoming bool
+}
+
+// FromContext returns the correlation ID of the context
+func FromContext(ctx context.Context) (flake.Flake, bool) {
+	return httpflake.FromContext(ctx)
+}
+
+// UnaryServerInterceptor returns the interceptor of unary RPCs of a server
+func (c *CorrelationIDs) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(c.incoming(ctx), req)
+	}
+}
+
+// StreamServerInterceptor returns the interceptor of streaming RPCs of a
+// server
+func (c *CorrelationIDs) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: c.incoming(ss.Context())})
+	}
+}
+
+// UnaryClientInterceptor returns the interceptor of unary RPCs of a client
+func (c *CorrelationIDs) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(c.outgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns the interceptor of streaming RPCs of a
+// client
+func (c *CorrelationIDs) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(c.outgoing(ctx), desc, cc, method, opts...)
+	}
+}
+
+// incoming returns the context of the server carrying the ID of the incoming
+// metadata of the format or a new one, and sets the ID as header
+func (c *CorrelationIDs) incoming(ctx context.Context) context.Context {
+	var id flake.Flake
+	if md, ok := metadata.FromIncomingContext(ctx); ok && !c.IgnoreIncoming {
+		if values := md.Get(c.key()); len(values) > 0 {
+			id, _ = c.decode(values[0])
+		}
+	}
+	if id <= flake.Nil {
+		id = c.next()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(c.key(), c.encode(id)))
+	return httpflake.NewContext(ctx, id)
+}
+
+// outgoing returns the context of the client carrying the ID of the context
+// or a new one in the outgoing metadata
+func (c *CorrelationIDs) outgoing(ctx context.Context) context.Context {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(c.key())) > 0 {
+		return ctx
+	}
+	id, ok := FromContext(ctx)
+	if !ok {
+		id = c.next()
+	}
+	return metadata.AppendToOutgoingContext(ctx, c.key(), c.encode(id))
+}
+
+// key returns the metadata key
+func (c *CorrelationIDs) key() string {
+	if c.Key == "" {
+		return MetadataKey
+	}
+	return c.Key
+}
+
+// encode returns the ID in the metadata format
+func (c *CorrelationIDs) encode(id flake.Flake) string {
+	if c.Format == flake.FormatUnknown {
+		return id.Base64()
+	}
+	return id.Encode(c.Format)
+}
+
+// decode decodes an ID of the metadata format, of any format detected by
+// flake.Decode without one
+func (c *CorrelationIDs) decode(s string) (flake.Flake, error) {
+	if c.Format == flake.FormatUnknown {
+		return flake.Decode(s)
+	}
+	return flake.DecodeFormat(s, c.Format)
+}
+
+// next returns a new ID
+func (c *CorrelationIDs) next() flake.Flake {
+	if c.Flaker == nil {
+		return flake.Next()
+	}
+	return c.Flaker.Next()
+}
+
+// serverStream is a grpc.ServerStream with the context carrying the ID
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the ID
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
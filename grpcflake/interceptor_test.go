@@ -0,0 +1,105 @@
+package grpcflake
+
+import (
+	"context"
+	"testing"
+
+	"go-flake"
+	"go-flake/httpflake"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryInterceptors(t *testing.T) {
+	ids := &CorrelationIDs{Format: flake.FormatHex}
+	var seen flake.Flake
+	record := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		seen, _ = FromContext(ctx)
+		return handler(ctx, req)
+	}
+	c := dial(t, &Server{},
+		grpc.ChainUnaryInterceptor(ids.UnaryServerInterceptor(), record),
+		grpc.WithUnaryInterceptor(ids.UnaryClientInterceptor()))
+
+	var header metadata.MD
+	if _, err := c.client.GetID(context.Background(), &GetIDRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+	if seen == flake.Nil || len(header.Get(MetadataKey)) != 1 || header.Get(MetadataKey)[0] != seen.Hex() {
+		t.Errorf("Expected the new ID %d as header but got %v", seen, header)
+	}
+
+	id := flake.Next()
+	if _, err := c.Next(httpflake.NewContext(context.Background(), id)); err != nil {
+		t.Fatal(err)
+	}
+	if seen != id {
+		t.Errorf("Expected the ID %d of the context but got %d", id, seen)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), MetadataKey, "invalid")
+	if _, err := c.Next(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if seen == flake.Nil || seen == id {
+		t.Errorf("Expected a new ID for an invalid incoming one but got %d", seen)
+	}
+}
+
+// stream is a grpc.ServerStream of a context
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	id := flake.Next()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, id.Base64()))
+	for _, ids := range []*CorrelationIDs{{}, {IgnoreIncoming: true}} {
+		var seen flake.Flake
+		err := ids.StreamServerInterceptor()(nil, &stream{ctx: ctx}, nil, func(_ any, ss grpc.ServerStream) error {
+			seen, _ = FromContext(ss.Context())
+			return nil
+		})
+		if err != nil || seen == flake.Nil || (seen == id) == ids.IgnoreIncoming {
+			t.Errorf("Unexpected ID %d of incoming %d ignoring %t: %v", seen, id, ids.IgnoreIncoming, err)
+		}
+	}
+}
+
+func TestServerInterceptorFormat(t *testing.T) {
+	id := flake.Flake(5356559267517187318)
+	for _, format := range []flake.Format{flake.FormatBase58, flake.FormatDecimal} {
+		ids := &CorrelationIDs{Format: format}
+		for encoded, echoed := range map[string]bool{id.Encode(format): true, id.Base64(): false} {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, encoded))
+			var seen flake.Flake
+			ids.StreamServerInterceptor()(nil, &stream{ctx: ctx}, nil, func(_ any, ss grpc.ServerStream) error {
+				seen, _ = FromContext(ss.Context())
+				return nil
+			})
+			if seen == flake.Nil || (seen == id) != echoed {
+				t.Errorf("Unexpected ID %d of incoming %q of the %s format", seen, encoded, format)
+			}
+		}
+	}
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	ids := &CorrelationIDs{Key: "x-correlation-id"}
+	id := flake.Next()
+	var sent []string
+	streamer := func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		sent = md.Get("x-correlation-id")
+		return nil, nil
+	}
+	ids.StreamClientInterceptor()(httpflake.NewContext(context.Background(), id), nil, nil, "", streamer)
+	if len(sent) != 1 || sent[0] != id.Base64() {
+		t.Errorf("Expected the ID %s in the metadata but got %v", id.Base64(), sent)
+	}
+}
@@ -0,0 +1,209 @@
+package flake
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// HealthThresholds are the limits of Check
+type HealthThresholds struct {
+	// MaxBorrowDepth is the count of intervals the generator may run ahead of
+	// the clock by borrowing future intervals
+	MaxBorrowDepth int64 `json:"maxBorrowDepth"`
+	// MinRemainingEpoch is the time left until the end of the epoch below
+	// which the generator is unhealthy
+	MinRemainingEpoch time.Duration `json:"minRemainingEpoch"`
+	// Reference is an optional trusted time source the clock must not skew
+	// from by more than MaxSkew, e.g. NTPReference
+	Reference ClockReference `json:"-"`
+	MaxSkew   time.Duration  `json:"maxSkew"`
+}
+
+// DefaultHealthThresholds are the thresholds of generators without
+// WithHealthThresholds: one borrowed interval and 30 days of epoch left.
+var DefaultHealthThresholds = HealthThresholds{
+	MaxBorrowDepth:    1,
+	MinRemainingEpoch: 30 * 24 * time.Hour,
+}
+
+// HealthCheck is the result of a single check of Check
+type HealthCheck struct {
+	// Name is the checked aspect: clock, entropy, epoch, sequence or store
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	// Detail describes the result, the problem if unhealthy
+	Detail string `json:"detail,omitempty"`
+}
+
+// Health is the result of Check, e.g. to respond to /healthz requests
+type Health struct {
+	Healthy bool          `json:"healthy"`
+	Checks  []HealthCheck `json:"checks"`
+}
+
+// HealthChecker is implemented by the flakers of this package. It's an
+// optional interface of Flaker, so other implementations don't have to
+// provide it. Detect it with a type assertion:
+//
+//	if checker, ok := flaker.(flake.HealthChecker); ok {
+//		health := checker.Check(ctx)
+//	}
+type HealthChecker interface {
+	WithHealthThresholds(thresholds HealthThresholds) Flaker
+	Check(ctx context.Context) Health
+	Healthy() bool
+}
+
+// WithHealthThresholds is a shorthand for Default.WithHealthThresholds(thresholds).
+// It returns Default unchanged if it isn't a HealthChecker.
+func WithHealthThresholds(thresholds HealthThresholds) Flaker {
+	f := getDefault()
+	if checker, ok := f.(HealthChecker); ok {
+		return checker.WithHealthThresholds(thresholds)
+	}
+	return f
+}
+
+// Check is a shorthand for Default.Check(ctx). It's unhealthy if Default
+// isn't a HealthChecker.
+func Check(ctx context.Context) Health {
+	f := getDefault()
+	if checker, ok := f.(HealthChecker); ok {
+		return checker.Check(ctx)
+	}
+	return Health{Checks: []HealthCheck{{Name: "flaker", Detail: fmt.Sprintf("%T is no HealthChecker", f)}}}
+}
+
+// Healthy is a shorthand for Default.Healthy()
+func Healthy() bool {
+	return Check(context.Background()).Healthy
+}
+
+// Returns a new Flaker instance copy checked against the specified thresholds
+// instead of DefaultHealthThresholds by Check.
+func (g *flaker) WithHealthThresholds(thresholds HealthThresholds) Flaker {
+	c := g.derive()
+	c.health = &thresholds
+	return c
+}
+
+// Healthy reports whether all checks of Check pass
+func (g *flaker) Healthy() bool {
+	return g.Check(context.Background()).Healthy
+}
+
+// Check evaluates the health of the generator:
+//   - clock: the clock isn't behind the last issued interval and, with a
+//     reference, within the maximum skew
+//   - entropy: reading random bytes succeeds, sources of WithEntropy aren't
+//     read to keep their sequence, e.g. of a deterministic source
+//   - epoch: the time is within the epoch with the minimum remaining
+//   - sequence: the borrow depth of Stats is within the maximum
+//   - store: the state store, if any, loads the state
+//
+// Checks not run before the context is done fail with its error. Check
+// doesn't generate IDs, count entropy reads or fire events.
+func (g *flaker) Check(ctx context.Context) Health {
+	thresholds := DefaultHealthThresholds
+	if g.health != nil {
+		thresholds = *g.health
+	}
+	checks := []struct {
+		name  string
+		check func() (string, error)
+	}{
+		{"clock", func() (string, error) { return g.checkClock(thresholds) }},
+		{"entropy", g.checkEntropy},
+		{"epoch", func() (string, error) { return g.checkEpoch(thresholds) }},
+		{"sequence", func() (string, error) { return g.checkSequence(thresholds) }},
+		{"store", g.checkStore},
+	}
+	health := Health{Healthy: true}
+	for _, c := range checks {
+		detail, err := "", ctx.Err()
+		if err == nil {
+			detail, err = c.check()
+		}
+		if err != nil {
+			detail = err.Error()
+			health.Healthy = false
+		}
+		health.Checks = append(health.Checks, HealthCheck{Name: c.name, Healthy: err == nil, Detail: detail})
+	}
+	return health
+}
+
+// checkClock checks the clock against the last issued interval and the
+// reference
+func (g *flaker) checkClock(thresholds HealthThresholds) (string, error) {
+	detail := "monotonic"
+	if g.mode != ModeRandom {
+		l := &g.layout
+		current, _ := unpack(atomic.LoadUint64(&g.state))
+		now := g.now()
+		interval := ((now - g.epochStart) >> l.ResolutionBits) & (1<<l.IntervalBits - 1)
+		if interval < current {
+			lag := time.Duration(g.epochStart + current<<l.ResolutionBits - now)
+			return "", fmt.Errorf("%w by %s", ErrClockRegression, lag)
+		}
+	}
+	if thresholds.Reference != nil {
+		skew, err := thresholds.Reference(g.clock)
+		if err != nil {
+			return "", fmt.Errorf("checking clock: %w", err)
+		}
+		if skew > thresholds.MaxSkew || skew < -thresholds.MaxSkew {
+			return "", fmt.Errorf("%w: %s", ErrClockSkew, skew)
+		}
+		detail = fmt.Sprintf("skew %s", skew)
+	}
+	return detail, nil
+}
+
+// checkEntropy reads random bytes without the fallback of the entropy policy.
+// Entropy supplied by the caller isn't read since each read would shift the
+// IDs of deterministic sources.
+func (g *flaker) checkEntropy() (string, error) {
+	if g.entropy != nil {
+		return "supplied, not read", nil
+	}
+	if _, err := g.reader()(4); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrEntropy, err)
+	}
+	return "available", nil
+}
+
+// checkEpoch checks the remaining time of the epoch
+func (g *flaker) checkEpoch(thresholds HealthThresholds) (string, error) {
+	now := g.now()
+	if now < g.epochStart {
+		return "", fmt.Errorf("%w: before the epoch start", ErrEpochOverflow)
+	}
+	remaining := g.remainingEpoch(now)
+	if remaining < thresholds.MinRemainingEpoch {
+		return "", fmt.Errorf("%s of the epoch remaining, below %s", remaining, thresholds.MinRemainingEpoch)
+	}
+	return fmt.Sprintf("%s remaining", remaining), nil
+}
+
+// checkSequence checks the borrow depth
+func (g *flaker) checkSequence(thresholds HealthThresholds) (string, error) {
+	s := g.Stats()
+	if s.BorrowDepth > thresholds.MaxBorrowDepth {
+		return "", fmt.Errorf("%w: %d intervals borrowed, above %d", ErrSequenceExhausted, s.BorrowDepth, thresholds.MaxBorrowDepth)
+	}
+	return fmt.Sprintf("%d intervals borrowed", s.BorrowDepth), nil
+}
+
+// checkStore loads the state from the store
+func (g *flaker) checkStore() (string, error) {
+	if g.store == nil {
+		return "none", nil
+	}
+	if _, err := g.store.Load(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrStateSave, err)
+	}
+	return "loaded", nil
+}
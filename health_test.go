@@ -0,0 +1,122 @@
+package flake
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type unreachableStore struct {
+	unreachable bool
+}
+
+func (s *unreachableStore) Load() (State, error) {
+	if s.unreachable {
+		return State{}, errors.New("unreachable")
+	}
+	return State{}, nil
+}
+
+func (s *unreachableStore) Save(State) error {
+	return nil
+}
+
+// failed returns the names of the failed checks
+func failed(h Health) string {
+	var names []string
+	for _, c := range h.Checks {
+		if !c.Healthy {
+			names = append(names, c.Name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// checker returns the HealthChecker of a flaker of this package
+func checker(f Flaker) HealthChecker {
+	return f.(HealthChecker)
+}
+
+func TestCheck(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f, err := New(SetClock(clock), SetMachineId(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Next()
+	h := checker(f).Check(context.Background())
+	if !h.Healthy || len(h.Checks) != 5 || failed(h) != "" || !checker(f).Healthy() {
+		t.Errorf("Expected a healthy generator but got %+v", h)
+	}
+	if c := h.Checks[4]; c.Name != "store" || c.Detail != "none" {
+		t.Errorf("Expected no store but got %+v", c)
+	}
+
+	clock.now = clock.now.Add(-time.Minute)
+	if h := checker(f).Check(context.Background()); h.Healthy || failed(h) != "clock,sequence" || !strings.Contains(h.Checks[0].Detail, ErrClockRegression.Error()) {
+		t.Errorf("Expected a failed clock check but got %+v", h)
+	}
+	clock.now = clock.now.Add(time.Minute)
+
+	f.NextN(3 * SequenceCapacity)
+	if h := checker(f).Check(context.Background()); failed(h) != "sequence" {
+		t.Errorf("Expected a failed sequence check but got %+v", h)
+	}
+	if checker(checker(f).WithHealthThresholds(HealthThresholds{MaxBorrowDepth: 10})).Healthy() != true {
+		t.Errorf("Expected a healthy generator with a higher borrow depth")
+	}
+	clock.now = clock.now.Add(time.Minute)
+
+	reference := TimeReference(func() (time.Time, error) { return clock.now.Add(time.Hour), nil })
+	skewed := checker(f).WithHealthThresholds(HealthThresholds{MaxBorrowDepth: 1, Reference: reference, MaxSkew: time.Minute})
+	if h := checker(skewed).Check(context.Background()); failed(h) != "clock" || !strings.Contains(h.Checks[0].Detail, ErrClockSkew.Error()) {
+		t.Errorf("Expected a failed clock skew check but got %+v", h)
+	}
+	g, _ := New(SetClock(clock), SetHealthThresholds(HealthThresholds{MinRemainingEpoch: 200 * 365 * 24 * time.Hour}))
+	if h := checker(g).Check(context.Background()); failed(h) != "epoch" {
+		t.Errorf("Expected a failed epoch check but got %+v", h)
+	}
+	randReader = failingReader{}
+	h = checker(f).Check(context.Background())
+	randReader = rand.Reader
+	if failed(h) != "entropy" {
+		t.Errorf("Expected a failed entropy check but got %+v", h)
+	}
+	// Entropy of the caller isn't read, the IDs of deterministic sources
+	// don't depend on checks
+	supplied := &countingReader{sizes: make(map[int]int)}
+	if h := checker(f.WithEntropy(supplied)).Check(context.Background()); !h.Healthy || supplied.reads != 0 {
+		t.Errorf("Expected a healthy check without reading the entropy but got %+v after %d reads", h, supplied.reads)
+	}
+	store := &unreachableStore{}
+	stored, err := f.WithStateStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := checker(stored).Check(context.Background()); failed(h) != "" || h.Checks[4].Detail != "loaded" {
+		t.Errorf("Expected a healthy store but got %+v", h)
+	}
+	store.unreachable = true
+	if h := checker(stored).Check(context.Background()); failed(h) != "store" {
+		t.Errorf("Expected a failed store check but got %+v", h)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if h := checker(f).Check(ctx); h.Healthy || failed(h) != "clock,entropy,epoch,sequence,store" || h.Checks[0].Detail != context.Canceled.Error() {
+		t.Errorf("Expected failed checks with a canceled context but got %+v", h)
+	}
+}
+
+func TestCheckDefault(t *testing.T) {
+	if h := Check(context.Background()); len(h.Checks) != 5 {
+		t.Errorf("Expected the checks of Default but got %+v", h)
+	}
+	defer SetDefault(SetDefault(struct{ Flaker }{Default}))
+	if h := Check(context.Background()); h.Healthy || failed(h) != "flaker" || Healthy() {
+		t.Errorf("Expected an unhealthy Default without HealthChecker but got %+v", h)
+	}
+}
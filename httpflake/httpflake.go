@@ -0,0 +1,141 @@
+// Package httpflake serves flakes over HTTP for a tiny ID service:
+//
+//	http.Handle("/ids", &httpflake.Handler{Flaker: flaker})
+//
+// GET /ids returns a new ID, GET /ids?n=100 a batch of 100 IDs. The format
+// parameter selects the encoding, e.g. ?format=base64. IDs are served as
+// plaintext, one per line, or as JSON when the request accepts
+// application/json.
+//
+// The RequestID middleware assigns a flake to every request:
+//
+//	http.ListenAndServe(":8080", httpflake.RequestID(mux))
+package httpflake
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-flake"
+)
+
+// DefaultMaxBatch is the largest batch of a Handler without MaxBatch
+const DefaultMaxBatch = 1000
+
+// ErrLimited is returned by Limit functions rejecting a request.
+var ErrLimited = errors.New("rate limit exceeded")
+
+// Handler is a http.Handler serving new IDs of a Flaker.
+type Handler struct {
+	// Flaker generates the IDs, nil uses the package functions of flake.
+	Flaker flake.Flaker
+	// Format is the default encoding, FormatUnknown serves decimal IDs.
+	Format flake.Format
+	// MaxBatch limits the IDs of a request, zero means DefaultMaxBatch.
+	MaxBatch int
+	// Limit is called with the count of requested IDs before generating them,
+	// e.g. to consult a rate limiter. An error rejects the request with 429
+	// Too Many Requests.
+	Limit func(r *http.Request, n int) error
+}
+
+// single is the JSON response of a single ID
+type single struct {
+	ID string `json:"id"`
+}
+
+// batch is the JSON response of a batch of IDs
+type batch struct {
+	IDs []string `json:"ids"`
+}
+
+// ServeHTTP serves new IDs to GET and POST requests
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	n, batched := 1, query.Has("n")
+	if batched {
+		var err error
+		if n, err = strconv.Atoi(query.Get("n")); err != nil || n < 1 || n > h.maxBatch() {
+			http.Error(w, "invalid count n: "+query.Get("n"), http.StatusBadRequest)
+			return
+		}
+	}
+	format := h.Format
+	if format == flake.FormatUnknown {
+		format = flake.FormatDecimal
+	}
+	if name := query.Get("format"); name != "" {
+		if err := format.UnmarshalText([]byte(name)); err != nil {
+			http.Error(w, "invalid format: "+name, http.StatusBadRequest)
+			return
+		}
+	}
+	asJSON := strings.Contains(r.Header.Get("Accept"), "application/json")
+	if asJSON && format == flake.FormatBytes {
+		http.Error(w, "bytes format not available as JSON", http.StatusNotAcceptable)
+		return
+	}
+	if h.Limit != nil {
+		if err := h.Limit(r, n); err != nil {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	ids, err := h.next(r, n)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	switch {
+	case asJSON:
+		w.Header().Set("Content-Type", "application/json")
+		strs := flake.EncodeAll(ids, format)
+		if batched {
+			json.NewEncoder(w).Encode(batch{IDs: strs})
+		} else {
+			json.NewEncoder(w).Encode(single{ID: strs[0]})
+		}
+	case format == flake.FormatBytes:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte(strings.Join(flake.EncodeAll(ids, format), "")))
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(strings.Join(flake.EncodeAll(ids, format), "\n") + "\n"))
+	}
+}
+
+// maxBatch returns the largest batch
+func (h *Handler) maxBatch() int {
+	if h.MaxBatch > 0 {
+		return h.MaxBatch
+	}
+	return DefaultMaxBatch
+}
+
+// next returns n new IDs respecting the policies and the context of the
+// request, unlike NextN batches fail on the first error
+func (h *Handler) next(r *http.Request, n int) ([]flake.Flake, error) {
+	ids := make([]flake.Flake, n)
+	for i := range ids {
+		var err error
+		if h.Flaker == nil {
+			ids[i], err = flake.NextContext(r.Context())
+		} else {
+			ids[i], err = h.Flaker.NextContext(r.Context())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
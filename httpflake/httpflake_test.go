@@ -0,0 +1,106 @@
+package httpflake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-flake"
+	"go-flake/flaketest"
+)
+
+func serve(h http.Handler, method, target, accept string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	h := &Handler{Flaker: flake.Clone()}
+
+	w := serve(h, http.MethodGet, "/", "")
+	if _, err := flake.DecodeDecimal(strings.TrimSpace(w.Body.String())); w.Code != http.StatusOK || err != nil {
+		t.Errorf("Expected a decimal ID but got %d %q: %v", w.Code, w.Body, err)
+	}
+
+	w = serve(h, http.MethodGet, "/?n=5&format=hex", "")
+	lines := strings.Fields(w.Body.String())
+	if len(lines) != 5 || len(lines[0]) != 16 {
+		t.Errorf("Expected 5 hex IDs but got %q", w.Body)
+	}
+
+	w = serve(h, http.MethodPost, "/?n=3&format=base64", "application/json")
+	var b batch
+	if err := json.Unmarshal(w.Body.Bytes(), &b); err != nil || len(b.IDs) != 3 || len(b.IDs[0]) != 11 {
+		t.Errorf("Expected a JSON batch of 3 base64 IDs but got %q: %v", w.Body, err)
+	}
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON content type but got %q", w.Header().Get("Content-Type"))
+	}
+
+	w = serve(h, http.MethodGet, "/", "application/json")
+	var s single
+	if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil || s.ID == "" {
+		t.Errorf("Expected a single JSON ID but got %q: %v", w.Body, err)
+	}
+
+	w = serve(h, http.MethodGet, "/?n=2&format=bytes", "")
+	if w.Body.Len() != 16 {
+		t.Errorf("Expected 16 bytes but got %d", w.Body.Len())
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	h := &Handler{MaxBatch: 10}
+	for _, tc := range []struct {
+		method, target, accept string
+		code                   int
+	}{
+		{http.MethodDelete, "/", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/?n=0", "", http.StatusBadRequest},
+		{http.MethodGet, "/?n=11", "", http.StatusBadRequest},
+		{http.MethodGet, "/?n=x", "", http.StatusBadRequest},
+		{http.MethodGet, "/?format=base16", "", http.StatusBadRequest},
+		{http.MethodGet, "/?format=bytes", "application/json", http.StatusNotAcceptable},
+		{http.MethodGet, "/?n=10", "", http.StatusOK},
+	} {
+		if w := serve(h, tc.method, tc.target, tc.accept); w.Code != tc.code {
+			t.Errorf("Expected %d for %s %s but got %d", tc.code, tc.method, tc.target, w.Code)
+		}
+	}
+}
+
+func TestHandlerGeneratorError(t *testing.T) {
+	// The batch fails at its third ID like on a clock regression with ClockError
+	for target, mock := range map[string]*flaketest.Mock{
+		"/":     flaketest.NewMock().ScriptErr(flake.ErrClockRegression),
+		"/?n=3": flaketest.NewMock(1, 2).ScriptErr(flake.ErrClockRegression),
+	} {
+		if w := serve(&Handler{Flaker: mock}, http.MethodGet, target, ""); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected %d of a failing generator for %s but got %d %q", http.StatusServiceUnavailable, target, w.Code, w.Body)
+		}
+	}
+}
+
+func TestHandlerLimit(t *testing.T) {
+	var requested int
+	h := &Handler{Limit: func(r *http.Request, n int) error {
+		requested += n
+		if requested > 10 {
+			return ErrLimited
+		}
+		return nil
+	}}
+	if w := serve(h, http.MethodGet, "/?n=10", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 within the limit but got %d", w.Code)
+	}
+	if w := serve(h, http.MethodGet, "/", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 beyond the limit but got %d", w.Code)
+	}
+}
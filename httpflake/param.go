@@ -0,0 +1,77 @@
+package httpflake
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-flake"
+)
+
+// ErrMissingParam is returned for missing or empty parameters.
+var ErrMissingParam = errors.New("missing parameter")
+
+// ParamError describes an invalid flake parameter of a request, the client
+// is to blame. Err is ErrMissingParam or a *flake.DecodeError.
+type ParamError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+// Error returns the error message
+func (e *ParamError) Error() string {
+	if errors.Is(e.Err, ErrMissingParam) {
+		return fmt.Sprintf("%v %q", e.Err, e.Name)
+	}
+	return fmt.Sprintf("invalid flake %q of parameter %q", e.Value, e.Name)
+}
+
+// Unwrap returns the cause
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status of the error, 400 Bad Request
+func (e *ParamError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ParamFunc returns the value of a named path parameter of a router, e.g.
+// chi.URLParam or a function of mux.Vars of gorilla:
+//
+//	id, err := httpflake.ParamFunc(chi.URLParam).Flake(r, "id")
+type ParamFunc func(r *http.Request, name string) string
+
+// Flake decodes the parameter like flake.Flake.Set: digits only as decimal
+// number of flake.Parse, other values in any format detected by flake.Decode.
+// The errors are of type *ParamError.
+func (p ParamFunc) Flake(r *http.Request, name string) (flake.Flake, error) {
+	value := p(r, name)
+	if value == "" {
+		return flake.Nil, &ParamError{Name: name, Err: ErrMissingParam}
+	}
+	var f flake.Flake
+	if err := f.Set(value); err != nil {
+		return flake.Nil, &ParamError{Name: name, Value: value, Err: err}
+	}
+	return f, nil
+}
+
+// PathValue is the ParamFunc of the patterns of http.ServeMux
+func PathValue(r *http.Request, name string) string {
+	return r.PathValue(name)
+}
+
+// PathParam decodes the path parameter of a http.ServeMux pattern like
+// GET /users/{id}. The errors are of type *ParamError.
+func PathParam(r *http.Request, name string) (flake.Flake, error) {
+	return ParamFunc(PathValue).Flake(r, name)
+}
+
+// QueryParam decodes the query parameter. The errors are of type *ParamError.
+func QueryParam(r *http.Request, name string) (flake.Flake, error) {
+	return ParamFunc(func(r *http.Request, name string) string {
+		return r.URL.Query().Get(name)
+	}).Flake(r, name)
+}
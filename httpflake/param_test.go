@@ -0,0 +1,66 @@
+package httpflake
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-flake"
+)
+
+func TestPathParam(t *testing.T) {
+	id := flake.Next()
+	var got flake.Flake
+	var err error
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		got, err = PathParam(r, "id")
+	})
+	for _, format := range []flake.Format{flake.FormatBase64, flake.FormatHex, flake.FormatDecimal} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/"+id.Encode(format), nil))
+		if err != nil || got != id {
+			t.Errorf("Expected %d of the %s path but got %d: %v", id, format, got, err)
+		}
+	}
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/invalid", nil))
+	var paramErr *ParamError
+	if !errors.As(err, &paramErr) || paramErr.Name != "id" || paramErr.Value != "invalid" || paramErr.StatusCode() != http.StatusBadRequest {
+		t.Errorf("Expected a ParamError but got %v", err)
+	}
+	var decodeErr *flake.DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Errorf("Expected a DecodeError as cause but got %v", err)
+	}
+}
+
+func TestParamFunc(t *testing.T) {
+	id := flake.Next()
+	vars := map[string]string{"id": id.Base32()}
+	param := ParamFunc(func(r *http.Request, name string) string {
+		return vars[name]
+	})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if got, err := param.Flake(r, "id"); err != nil || got != id {
+		t.Errorf("Expected %d but got %d: %v", id, got, err)
+	}
+	if _, err := param.Flake(r, "other"); !errors.Is(err, ErrMissingParam) || err.Error() != `missing parameter "other"` {
+		t.Errorf("Expected ErrMissingParam but got %v", err)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?parent="+id.Hex(), nil)
+	if got, err := QueryParam(r, "parent"); err != nil || got != id {
+		t.Errorf("Expected %d of the query but got %d: %v", id, got, err)
+	}
+
+	// Digits are decimal even of the length of base64, hex values must not
+	// decode to negative flakes
+	r = httptest.NewRequest(http.MethodGet, "/?id=12345678901&hex=ffffffffffffffff", nil)
+	if got, err := QueryParam(r, "id"); err != nil || got != 12345678901 {
+		t.Errorf("Expected the decimal 12345678901 but got %d: %v", got, err)
+	}
+	if got, err := QueryParam(r, "hex"); !errors.Is(err, flake.ErrOutOfRange) {
+		t.Errorf("Expected a hex flake out of range but got %d: %v", got, err)
+	}
+}
@@ -0,0 +1,86 @@
+package httpflake
+
+import (
+	"context"
+	"net/http"
+
+	"go-flake"
+)
+
+// HeaderRequestID is the default header of request IDs
+const HeaderRequestID = "X-Request-ID"
+
+// RequestIDs is a middleware assigning a flake to every request. The ID is
+// stored in the context of the request and set as response header.
+type RequestIDs struct {
+	// Flaker generates the IDs, nil uses the package functions of flake.
+	Flaker flake.Flaker
+	// Header is the request and response header of the ID, empty means
+	// HeaderRequestID.
+	Header string
+	// Format is the encoding of the response header, FormatUnknown means
+	// FormatBase64.
+	Format flake.Format
+	// IgnoreIncoming generates a new ID even if the request carries one,
+	// e.g. for public endpoints not trusting their clients.
+	IgnoreIncoming bool
+}
+
+// RequestID wraps the handler with the RequestIDs middleware of the defaults
+func RequestID(next http.Handler) http.Handler {
+	return (&RequestIDs{}).Wrap(next)
+}
+
+// Wrap returns the handler assigning request IDs before calling next
+func (m *RequestIDs) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, r = m.Assign(w, r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Assign returns the ID of the request and the request with the ID stored in
+// its context, and sets the response header. Incoming IDs of the Format, or
+// of any format detected by flake.Decode without one, are honored unless
+// IgnoreIncoming is set, others are replaced by a new ID.
+func (m *RequestIDs) Assign(w http.ResponseWriter, r *http.Request) (flake.Flake, *http.Request) {
+	header := m.Header
+	if header == "" {
+		header = HeaderRequestID
+	}
+	format := m.Format
+	var id flake.Flake
+	if incoming := r.Header.Get(header); incoming != "" && !m.IgnoreIncoming {
+		if format == flake.FormatUnknown {
+			id, _ = flake.Decode(incoming)
+		} else {
+			id, _ = flake.DecodeFormat(incoming, format)
+		}
+	}
+	if id <= flake.Nil {
+		if m.Flaker == nil {
+			id = flake.Next()
+		} else {
+			id = m.Flaker.Next()
+		}
+	}
+	if format == flake.FormatUnknown {
+		format = flake.FormatBase64
+	}
+	w.Header().Set(header, id.Encode(format))
+	return id, r.WithContext(NewContext(r.Context(), id))
+}
+
+// contextKey is the key of the request ID in contexts
+type contextKey struct{}
+
+// NewContext returns a copy of the context carrying the request ID
+func NewContext(ctx context.Context, id flake.Flake) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID of the context
+func FromContext(ctx context.Context) (flake.Flake, bool) {
+	id, ok := ctx.Value(contextKey{}).(flake.Flake)
+	return id, ok
+}
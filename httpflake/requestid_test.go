@@ -0,0 +1,72 @@
+package httpflake
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-flake"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen flake.Flake
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = FromContext(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	header := w.Header().Get(HeaderRequestID)
+	if id, err := flake.DecodeBase64(header); err != nil || id != seen || seen == flake.Nil {
+		t.Errorf("Expected the ID %d of the context in the header but got %q: %v", seen, header, err)
+	}
+
+	incoming := flake.Next()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderRequestID, incoming.Hex())
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if seen != incoming {
+		t.Errorf("Expected the incoming ID %d but got %d", incoming, seen)
+	}
+
+	r.Header.Set(HeaderRequestID, "not a flake")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if seen == incoming || seen == flake.Nil {
+		t.Errorf("Expected a new ID for an invalid incoming one but got %d", seen)
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	m := &RequestIDs{Flaker: flake.WithMachineId(9), Header: "X-Correlation-ID", Format: flake.FormatHex, IgnoreIncoming: true}
+	incoming := flake.Next()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Correlation-ID", incoming.Hex())
+	w := httptest.NewRecorder()
+	id, r := m.Assign(w, r)
+	if id == incoming || w.Header().Get("X-Correlation-ID") != id.Hex() {
+		t.Errorf("Expected a new ID in hex but got %d of header %q", id, w.Header().Get("X-Correlation-ID"))
+	}
+	if fromContext, ok := FromContext(r.Context()); !ok || fromContext != id {
+		t.Errorf("Expected the ID %d in the context but got %d", id, fromContext)
+	}
+	if _, ok := FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()); ok {
+		t.Errorf("Expected no ID in a plain context")
+	}
+}
+
+func TestRequestIDsFormat(t *testing.T) {
+	incoming := flake.Flake(5356559267517187318)
+	for _, format := range []flake.Format{flake.FormatBase58, flake.FormatDecimal, flake.FormatHex} {
+		m := &RequestIDs{Format: format}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(HeaderRequestID, incoming.Encode(format))
+		w := httptest.NewRecorder()
+		if id, _ := m.Assign(w, r); id != incoming || w.Header().Get(HeaderRequestID) != incoming.Encode(format) {
+			t.Errorf("Expected the incoming %s ID %d echoed but got %d", format, incoming, id)
+		}
+		r.Header.Set(HeaderRequestID, incoming.Base64())
+		if id, _ := m.Assign(httptest.NewRecorder(), r); id == incoming {
+			t.Errorf("Expected a new ID for a base64 ID of the %s format", format)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package flake
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// ID is a flake typed by the entity T it identifies, so an ID[User] can't be
+// passed where an ID[Order] is expected:
+//
+//	type UserID = flake.ID[User]
+//
+// It's text and JSON encoded as decimal string, which JavaScript clients
+// can't round to 53 bits, decodes JSON numbers as well and is stored as 64
+// bit integer column. Use Flake for the other encodings.
+type ID[T any] Flake
+
+// NextID is a shorthand for NewID[T](Default)
+func NextID[T any]() ID[T] {
+	return NewID[T](getDefault())
+}
+
+// NewID returns a new ID of the entity T generated by the flaker
+func NewID[T any](flaker Flaker) ID[T] {
+	return ID[T](flaker.Next())
+}
+
+// ParseID parses an ID of the entity T like Parse
+func ParseID[T any](s string) (ID[T], error) {
+	f, err := Parse(s)
+	return ID[T](f), err
+}
+
+// Flake returns the ID as untyped Flake
+func (id ID[T]) Flake() Flake {
+	return Flake(id)
+}
+
+// IsZero reports whether the ID is Nil
+func (id ID[T]) IsZero() bool {
+	return Flake(id) == Nil
+}
+
+// String returns the ID as decimal number
+func (id ID[T]) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// MarshalText returns the ID as decimal number
+func (id ID[T]) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(id), 10), nil
+}
+
+// UnmarshalText parses the ID like Parse
+func (id *ID[T]) UnmarshalText(text []byte) error {
+	f, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*id = ID[T](f)
+	return nil
+}
+
+// MarshalJSON returns the ID as decimal string
+func (id ID[T]) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, id.String()), nil
+}
+
+// UnmarshalJSON parses the ID from a decimal string or number, null is Nil
+func (id *ID[T]) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*id = 0
+		return nil
+	}
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		b = b[1 : len(b)-1]
+	}
+	if bytes.ContainsAny(b, `"\`) {
+		return fmt.Errorf("%w: JSON %s", ErrInvalidEncoding, b)
+	}
+	return id.UnmarshalText(b)
+}
+
+// Scan implements the sql.Scanner interface for integer and text columns
+func (id *ID[T]) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*id = 0
+	case int64:
+		*id = ID[T](v)
+	case []byte:
+		return id.UnmarshalText(v)
+	case string:
+		return id.UnmarshalText([]byte(v))
+	default:
+		return fmt.Errorf("%w: cannot scan %T into ID", ErrInvalidEncoding, src)
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface storing the ID as integer
+func (id ID[T]) Value() (driver.Value, error) {
+	return int64(id), nil
+}
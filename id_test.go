@@ -0,0 +1,53 @@
+package flake
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type user struct{}
+
+type order struct{}
+
+func TestID(t *testing.T) {
+	id := NextID[user]()
+	if id.IsZero() || NewID[order](Raw).IsZero() {
+		t.Errorf("Expected new IDs")
+	}
+	var _ ID[order] = ID[order](id.Flake()) // explicit conversion between entities only
+
+	b, err := json.Marshal(struct{ ID ID[user] }{id})
+	if err != nil || string(b) != `{"ID":"`+id.String()+`"}` {
+		t.Errorf("Expected the ID as JSON string but got %s, %v", b, err)
+	}
+	for _, input := range []string{`"` + id.String() + `"`, id.String()} {
+		var decoded ID[user]
+		if err := json.Unmarshal([]byte(input), &decoded); err != nil || decoded != id {
+			t.Errorf("Expected %d from JSON %s but got %d, %v", id, input, decoded, err)
+		}
+	}
+	var decoded ID[user]
+	if err := json.Unmarshal([]byte(`null`), &decoded); err != nil || !decoded.IsZero() {
+		t.Errorf("Expected Nil from JSON null but got %d, %v", decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`"abc"`), &decoded); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("Expected an invalid encoding but got %v", err)
+	}
+
+	if parsed, err := ParseID[user](id.String()); err != nil || parsed != id {
+		t.Errorf("Expected %d parsed but got %d, %v", id, parsed, err)
+	}
+	if v, err := id.Value(); err != nil || v != int64(id) {
+		t.Errorf("Expected the integer value %d but got %v, %v", id, v, err)
+	}
+	for _, src := range []any{int64(id), id.String(), []byte(id.String())} {
+		var scanned ID[user]
+		if err := scanned.Scan(src); err != nil || scanned != id {
+			t.Errorf("Expected %d scanned from %T but got %d, %v", id, src, scanned, err)
+		}
+	}
+	if err := decoded.Scan(1.5); err == nil {
+		t.Errorf("Expected scanning a float to fail")
+	}
+}
@@ -0,0 +1,60 @@
+package flake
+
+import (
+	"fmt"
+	"time"
+)
+
+// Info holds the fields of a flake decomposed by Inspect.
+type Info struct {
+	// Flake is the inspected flake
+	Flake Flake `json:"flake"`
+	// Raw is the unshuffled form of the flake
+	Raw Flake `json:"raw"`
+	// Time is the start of the interval of the flake. Borrowing IDs of the
+	// following intervals results in later times than the generation time.
+	Time time.Time `json:"time"`
+	// Interval is the count of intervals since the epoch start
+	Interval int64 `json:"interval"`
+	// Sequence is the sequence value within the interval including the random
+	// bits
+	Sequence int64 `json:"sequence"`
+	// MachineId is the machine-id of the generator
+	MachineId byte `json:"machineId"`
+	// Kind is the entity kind of NextKind in the KindBits of the layout
+	Kind byte `json:"kind,omitempty"`
+}
+
+// Inspect is a shorthand for Default.Inspect(f)
+func Inspect(f Flake) (Info, error) {
+	return getDefault().Inspect(f)
+}
+
+// Inspect decomposes the flake into its fields according to the mode, epoch
+// start and layout of the generator. It works for flakes of any time while
+// Validate rejects implausible ones. Nil and negative flakes result in
+// ErrInvalidFlake, flakes of ModeRandom have no fields to inspect and result in
+// ErrInvalidConfig.
+func (g *flaker) Inspect(f Flake) (Info, error) {
+	if f <= Nil {
+		return Info{}, fmt.Errorf("%w: %d", ErrInvalidFlake, f)
+	} else if g.mode == ModeRandom {
+		return Info{}, fmt.Errorf("%w: no fields in %s mode", ErrInvalidConfig, g.mode)
+	}
+	raw := int64(f)
+	if g.mode == ModeShuffled {
+		raw = g.unshuffle(raw)
+	}
+	l := &g.layout
+	interval := raw >> (l.SequenceBits + l.MachineIdBits)
+	sequence := raw >> l.MachineIdBits & (1<<l.SequenceBits - 1)
+	return Info{
+		Flake:     f,
+		Raw:       Flake(raw),
+		Time:      time.Unix(0, g.epochStart+interval<<l.ResolutionBits),
+		Interval:  interval,
+		Sequence:  sequence,
+		MachineId: byte(raw & (1<<l.MachineIdBits - 1)),
+		Kind:      byte(sequence & (1<<l.KindBits - 1)),
+	}, nil
+}
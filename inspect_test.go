@@ -0,0 +1,46 @@
+package flake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	at := time.Now().Add(-time.Hour)
+	for _, mode := range []Mode{ModeShuffled, ModeRaw} {
+		g := WithMachineId(42).WithMode(mode)
+		f, err := g.GenerateAt(at, 12345)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := g.Inspect(f)
+		if err != nil {
+			t.Errorf("Expected no error but got %v", err)
+		}
+		if info.Flake != f || info.MachineId != 42 || info.Sequence != 12345 {
+			t.Errorf("Unexpected %s info %+v", mode, info)
+		}
+		if d := at.Sub(info.Time); d < 0 || d >= g.Layout().IntervalLength() {
+			t.Errorf("Expected the interval of %s but got %s", at, info.Time)
+		}
+		if mode == ModeRaw && info.Raw != f || mode == ModeShuffled && info.Raw != g.Unshuffle(f) {
+			t.Errorf("Unexpected raw flake %d of %s flake %d", info.Raw, mode, f)
+		}
+	}
+
+	g := WithMachineId(3).WithMode(ModeRaw)
+	f := g.Next()
+	if info, _ := g.Inspect(f); info.MachineId != 3 || time.Since(info.Time) > 2*time.Second {
+		t.Errorf("Unexpected info %+v of a new flake", info)
+	}
+	if _, err := g.Inspect(Nil); !errors.Is(err, ErrInvalidFlake) {
+		t.Errorf("Expected ErrInvalidFlake but got %v", err)
+	}
+	if _, err := WithMode(ModeRandom).Inspect(f); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig in random mode but got %v", err)
+	}
+	if _, err := Inspect(Next()); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+}
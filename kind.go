@@ -0,0 +1,39 @@
+package flake
+
+import (
+	"context"
+	"fmt"
+)
+
+// KindFlaker is implemented by the flakers of this package. It's an optional
+// interface of Flaker, so other implementations don't have to provide it.
+type KindFlaker interface {
+	NextKind(kind byte) (Flake, error)
+}
+
+// NextKind is a shorthand for Default.NextKind(kind). It fails with
+// ErrInvalidConfig if Default isn't a KindFlaker.
+func NextKind(kind byte) (Flake, error) {
+	return nextKind(getDefault(), kind)
+}
+
+// nextKind returns an ID of the kind of the flaker if it's a KindFlaker
+func nextKind(f Flaker, kind byte) (Flake, error) {
+	if k, ok := f.(KindFlaker); ok {
+		return k.NextKind(kind)
+	}
+	return 0, fmt.Errorf("%w: %T is no KindFlaker", ErrInvalidConfig, f)
+}
+
+// NextKind works like NextErr but tags the ID with the entity kind in the
+// KindBits of the layout, e.g. to tell users from invoices by their IDs
+// alone. Inspect reports the kind. It fails with ErrInvalidConfig if the kind
+// exceeds the kind bits or in ModeRandom.
+func (g *flaker) NextKind(kind byte) (Flake, error) {
+	if g.mode == ModeRandom {
+		return 0, fmt.Errorf("%w: no kind in %s mode", ErrInvalidConfig, g.mode)
+	} else if int(kind) >= 1<<g.layout.KindBits {
+		return 0, fmt.Errorf("%w: kind %d exceeds %d bits", ErrInvalidConfig, kind, g.layout.KindBits)
+	}
+	return g.emit(context.Background(), true, kind)
+}
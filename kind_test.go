@@ -0,0 +1,58 @@
+package flake
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNextKind(t *testing.T) {
+	layout := HighEntropyLayout
+	layout.KindBits = 3
+	for _, mode := range []Mode{ModeShuffled, ModeRaw} {
+		f, err := New(SetLayout(layout), SetMode(mode), SetMachineId(5))
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[Flake]bool)
+		for i := 0; i < 400; i++ {
+			kind := byte(i % 8)
+			id, err := nextKind(f, kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := f.Inspect(id)
+			if err != nil || info.Kind != kind || info.MachineId != 5 || seen[id] {
+				t.Fatalf("Expected a unique %s ID of kind %d but got %+v, %v", mode, kind, info, err)
+			}
+			seen[id] = true
+		}
+		if info, _ := f.Inspect(f.Next()); info.Kind != 0 {
+			t.Errorf("Expected kind 0 of Next but got %d", info.Kind)
+		}
+		if _, err := nextKind(f, 8); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected kind 8 to exceed 3 bits but got %v", err)
+		}
+	}
+
+	if _, err := NextKind(1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected no kind bits in the default layout but got %v", err)
+	}
+	if _, err := nextKind(struct{ Flaker }{Default}, 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected no kinds of a flaker without KindFlaker but got %v", err)
+	}
+	if _, err := nextKind(WithMode(ModeRandom), 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected no kind in random mode but got %v", err)
+	}
+	layout.KindBits = layout.RandomBits + 1
+	if err := layout.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected more kind than random bits to be invalid but got %v", err)
+	}
+	if r, _ := Audit(Config{Layout: &HighEntropyLayout}, 1); r.MinRandomBits != 18 {
+		t.Errorf("Expected 18 random bits without kind bits but got %d", r.MinRandomBits)
+	}
+	highKind := HighEntropyLayout
+	highKind.KindBits = 3
+	if r, _ := Audit(Config{Layout: &highKind}, 1); r.MinRandomBits != 15 {
+		t.Errorf("Expected 15 random bits besides 3 kind bits but got %d", r.MinRandomBits)
+	}
+}
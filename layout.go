@@ -0,0 +1,162 @@
+package flake
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layout defines the bit widths of the fields of a flake:
+// [interval][sequence][machine-id]. The widths must add up to 63 bits.
+type Layout struct {
+	// IntervalBits is the width of the count of intervals since the epoch
+	// start. It defines the epoch length together with ResolutionBits.
+	IntervalBits int `json:"intervalBits" yaml:"intervalBits"`
+	// SequenceBits is the width of the sequence within an interval including
+	// its random bits. It must be between 18 and 29 bits.
+	SequenceBits int `json:"sequenceBits" yaml:"sequenceBits"`
+	// MachineIdBits is the width of the machine-id of up to 8 bits.
+	MachineIdBits int `json:"machineIdBits" yaml:"machineIdBits"`
+	// ResolutionBits is the count of nanosecond bits ignored by the interval,
+	// an interval lasts 2^ResolutionBits ns.
+	ResolutionBits int `json:"resolutionBits" yaml:"resolutionBits"`
+	// RandomBits is the count of lower sequence bits filled with random bits
+	// for each ID, the upper bits hold the counter. Zero keeps the default
+	// split, which fills up to 16 bits with randomness in the first IDs of an
+	// interval and uses all bits for the counter from the second quarter on.
+	RandomBits int `json:"randomBits,omitempty" yaml:"randomBits,omitempty"`
+	// KindBits is the count of the lowest random bits carrying the entity
+	// kind of NextKind instead, up to 8 and RandomBits. The counter keeps the
+	// IDs of all kinds unique.
+	KindBits int `json:"kindBits,omitempty" yaml:"kindBits,omitempty"`
+}
+
+// DefaultLayout is the layout of the Default and Raw flakers: 32 bit intervals
+// of ~1.07 s lasting ~146 years, a 23 bit sequence and 256 machine-ids.
+var DefaultLayout = Layout{
+	IntervalBits:   intervalBits,
+	SequenceBits:   sequenceBits,
+	MachineIdBits:  machineIdBits,
+	ResolutionBits: ignoredTimeBits,
+}
+
+// HighEntropyLayout is a preset for public IDs where enumeration resistance
+// matters more than throughput: 18 random bits in every ID besides a 9 bit
+// counter of 512 IDs per interval of ~1.07 s and machine-id, 16 machine-ids
+// and an epoch of ~146 years. The counter keeps the IDs unique, they never
+// collide. Knowing an ID reveals its interval and machine-id, but a guess of
+// another ID of them only hits with a chance of 1 in 2^18 even for a counter
+// surely issued. Bursts beyond 512 IDs borrow the following intervals
+// according to the sequence policy. See Audit for other layouts and rates.
+var HighEntropyLayout = Layout{
+	IntervalBits:   32,
+	SequenceBits:   27,
+	MachineIdBits:  4,
+	ResolutionBits: 30,
+	RandomBits:     18,
+}
+
+// Validate checks the bit widths of the layout. The returned errors wrap
+// ErrInvalidConfig.
+func (l Layout) Validate() error {
+	switch {
+	case l.IntervalBits+l.SequenceBits+l.MachineIdBits != 63:
+		return fmt.Errorf("%w: layout of %d instead of 63 bits", ErrInvalidConfig, l.IntervalBits+l.SequenceBits+l.MachineIdBits)
+	case l.IntervalBits < 1 || l.IntervalBits > 32:
+		return fmt.Errorf("%w: %d interval bits", ErrInvalidConfig, l.IntervalBits)
+	case l.SequenceBits < 18 || l.SequenceBits > 29:
+		return fmt.Errorf("%w: %d sequence bits", ErrInvalidConfig, l.SequenceBits)
+	case l.MachineIdBits < 0 || l.MachineIdBits > 8:
+		return fmt.Errorf("%w: %d machine-id bits", ErrInvalidConfig, l.MachineIdBits)
+	case l.ResolutionBits < 0 || l.ResolutionBits+l.IntervalBits > 62:
+		return fmt.Errorf("%w: %d resolution bits", ErrInvalidConfig, l.ResolutionBits)
+	case l.RandomBits < 0 || l.RandomBits >= l.SequenceBits:
+		return fmt.Errorf("%w: %d random bits", ErrInvalidConfig, l.RandomBits)
+	case l.KindBits < 0 || l.KindBits > 8 || l.KindBits > l.RandomBits:
+		return fmt.Errorf("%w: %d kind bits of %d random bits", ErrInvalidConfig, l.KindBits, l.RandomBits)
+	}
+	return nil
+}
+
+// IntervalLength returns the time span of an interval
+func (l Layout) IntervalLength() time.Duration {
+	return time.Duration(1) << l.ResolutionBits
+}
+
+// EpochLength returns the time span of an epoch after which the interval wraps
+func (l Layout) EpochLength() time.Duration {
+	return l.IntervalLength() << l.IntervalBits
+}
+
+// MachineIds returns the count of distinct machine-ids
+func (l Layout) MachineIds() int {
+	return 1 << l.MachineIdBits
+}
+
+// SequenceCapacity returns the count of IDs per interval and machine-id before
+// the sequence is exhausted
+func (l Layout) SequenceCapacity() int {
+	if l.RandomBits > 0 {
+		return 1 << (l.SequenceBits - l.RandomBits)
+	}
+	return 1<<(l.SequenceBits-1) + 1<<(l.SequenceBits-10) + 1<<(l.SequenceBits-18)
+}
+
+// Throughput returns the count of IDs per second and machine-id before the
+// sequence is exhausted
+func (l Layout) Throughput() float64 {
+	return float64(l.SequenceCapacity()) * float64(time.Second) / float64(l.IntervalLength())
+}
+
+// MaxFlake returns the largest flake of the layout
+func (l Layout) MaxFlake() Flake {
+	return 1<<(l.IntervalBits+l.SequenceBits+l.MachineIdBits) - 1
+}
+
+// ----------------------------------------------------------------------------
+
+// The sequence value space is split in three phases: the first quarter holds
+// small counters with 16 random bits, the second quarter larger counters with
+// 8 random bits and the upper half uses all space for the counter. Layouts
+// with RandomBits use a single phase of the counter and the random bits.
+
+// sequenceOf returns the sequence value of the counter and the count of lower
+// random bits to fill.
+func (l *Layout) sequenceOf(counter int32) (sequence int64, randomBits int) {
+	if l.RandomBits > 0 {
+		return int64(counter) << l.RandomBits, l.RandomBits
+	}
+	small, large := int32(1)<<(l.SequenceBits-18), int32(1)<<(l.SequenceBits-10)
+	if counter < small {
+		// Small counter and 2 random bytes
+		return int64(counter) << 16, 16
+	} else if counter < small+large {
+		// Enlarge the counter
+		return 1<<(l.SequenceBits-2) - int64(small)<<8 + int64(counter)<<8, 8
+	}
+	// Use all space for the counter
+	return l.offset() + int64(counter), 0
+}
+
+// offset returns the distance of the counter to the sequence value when all
+// space is used for the counter
+func (l *Layout) offset() int64 {
+	return 1<<(l.SequenceBits-1) - 1<<(l.SequenceBits-10) - 1<<(l.SequenceBits-18)
+}
+
+// borrowed returns the count of future intervals the sequence counter has
+// reached into after the counter space of the current interval is exhausted.
+func (l *Layout) borrowed(counter int32) int64 {
+	if l.RandomBits > 0 {
+		return int64(counter) >> (l.SequenceBits - l.RandomBits)
+	}
+	return (int64(counter) + l.offset()) >> l.SequenceBits
+}
+
+// lastCounter returns the last counter within the given count of borrowed
+// intervals
+func (l *Layout) lastCounter(borrowed int64) int64 {
+	if l.RandomBits > 0 {
+		return (borrowed+1)<<(l.SequenceBits-l.RandomBits) - 1
+	}
+	return (borrowed+1)<<l.SequenceBits - l.offset() - 1
+}
@@ -0,0 +1,108 @@
+package flake
+
+import (
+	"errors"
+	"math/bits"
+	"testing"
+	"time"
+)
+
+func TestLayout(t *testing.T) {
+	l := DefaultLayout
+	if err := l.Validate(); err != nil {
+		t.Errorf("Expected a valid default layout but got %v", err)
+	}
+	if l.IntervalLength() != IntervalLength || l.EpochLength() != EpochLength || l.MachineIds() != MachineIds ||
+		l.SequenceCapacity() != SequenceCapacity || l.Throughput() != Throughput || l.MaxFlake() != MaxFlake {
+		t.Errorf("Expected the default layout to match the constants")
+	}
+
+	// The phases of the default layout
+	for _, c := range []struct {
+		counter    int32
+		sequence   int64
+		randomBits int
+	}{{0, 0, 16}, {0x1f, 0x1f0000, 16}, {0x20, 0x200000, 8}, {0x201f, 0x3fff00, 8}, {0x2020, 0x400000, 0}, {SequenceCapacity - 1, 0x7fffff, 0}} {
+		if sequence, randomBits := l.sequenceOf(c.counter); sequence != c.sequence || randomBits != c.randomBits {
+			t.Errorf("Expected sequence %x with %d random bits for counter %x but got %x with %d", c.sequence, c.randomBits, c.counter, sequence, randomBits)
+		}
+	}
+
+	for _, invalid := range []Layout{
+		{IntervalBits: 32, SequenceBits: 23, MachineIdBits: 7, ResolutionBits: 30},
+		{IntervalBits: 36, SequenceBits: 19, MachineIdBits: 8, ResolutionBits: 20},
+		{IntervalBits: 38, SequenceBits: 17, MachineIdBits: 8, ResolutionBits: 20},
+		{IntervalBits: 28, SequenceBits: 25, MachineIdBits: 10, ResolutionBits: 30},
+		{IntervalBits: 32, SequenceBits: 23, MachineIdBits: 8, ResolutionBits: 31},
+		{IntervalBits: 32, SequenceBits: 23, MachineIdBits: 8, ResolutionBits: 30, RandomBits: 23},
+	} {
+		if err := invalid.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for layout %+v but got %v", invalid, err)
+		}
+	}
+}
+
+func TestCustomLayout(t *testing.T) {
+	// 4 machine-ids, a 29 bit sequence and ~8 ms intervals lasting ~1.1 years
+	l := Layout{IntervalBits: 32, SequenceBits: 29, MachineIdBits: 2, ResolutionBits: 23}
+	if err := l.Validate(); err != nil {
+		t.Errorf("Expected a valid layout but got %v", err)
+	}
+	clock := &manualClock{now: time.Now()}
+	f, err := New(SetLayout(l), SetMode(ModeRaw), SetMachineId(3), SetClock(clock))
+	if err != nil {
+		t.Errorf("Creating flaker failed: %v", err)
+		return
+	}
+	ids := f.NextN(100000)
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Errorf("Expected ascending IDs but got %d after %d", ids[i], ids[i-1])
+			return
+		}
+	}
+	// The epoch wrapped several times since 2020
+	elapsed := clock.now.UnixNano() - f.(*flaker).epochStart
+	expected := elapsed >> l.ResolutionBits & (1<<l.IntervalBits - 1)
+	if interval := int64(ids[0]) >> (l.SequenceBits + l.MachineIdBits); interval != expected {
+		t.Errorf("Expected interval %d but got %d", expected, interval)
+	}
+	if machineId := ids[0] & 3; machineId != 3 {
+		t.Errorf("Expected machine-id 3 but got %d", machineId)
+	}
+	if err := f.Validate(ids[0], 3); err != nil {
+		t.Errorf("Expected a valid flake but got %v", err)
+	}
+	if end := f.EpochEnd().Sub(clock.now); end <= 0 || end > l.EpochLength() {
+		t.Errorf("Unexpected epoch end in %s", end)
+	}
+}
+
+func TestHighEntropyLayout(t *testing.T) {
+	l := HighEntropyLayout
+	if err := l.Validate(); err != nil {
+		t.Errorf("Expected a valid layout but got %v", err)
+	}
+	if l.SequenceCapacity() != 512 || l.borrowed(511) != 0 || l.borrowed(512) != 1 || l.lastCounter(1) != 1023 {
+		t.Errorf("Expected a sequence of 512 IDs per interval")
+	}
+	f, err := New(SetLayout(l), SetMode(ModeRaw), SetMachineId(9))
+	if err != nil {
+		t.Fatalf("Creating flaker failed: %v", err)
+	}
+	ids := f.NextN(2000)
+	randomBits := 0
+	for i, id := range ids {
+		if i > 0 && id <= ids[i-1] {
+			t.Errorf("Expected ascending IDs but got %d after %d", id, ids[i-1])
+			return
+		}
+		if id&0xf != 9 || f.Validate(id, 9) != nil {
+			t.Errorf("Expected a valid ID of machine-id 9 but got %x", id)
+		}
+		randomBits += bits.OnesCount64(uint64(id >> 4 & (1<<18 - 1)))
+	}
+	if mean := float64(randomBits) / float64(len(ids)); mean < 8.5 || mean > 9.5 {
+		t.Errorf("Expected ~9 of 18 random bits set per ID but got %.1f", mean)
+	}
+}
@@ -0,0 +1,27 @@
+package flake
+
+import "log/slog"
+
+// SlogAttr returns the slog attribute of a flake of Default: a group of the
+// base64 encoded id and the time and machine-id decoded by Inspect, e.g.
+//
+//	logger.Info("created", flake.SlogAttr("user", id))
+//	// user.id=ASNFZ4mrze8 user.time=2023-11-14T22:13:20Z user.machine=42
+//
+// Flakes Inspect fails on, e.g. of ModeRandom, only log the id. Use SlogAttrOf
+// for the flakes of other generators, Default decodes a wrong time and
+// machine-id of another epoch, mode or layout.
+func SlogAttr(key string, f Flake) slog.Attr {
+	return SlogAttrOf(getDefault(), key, f)
+}
+
+// SlogAttrOf returns the slog attribute of a flake of the flaker like
+// SlogAttr, with the time and machine-id decoded by flaker.Inspect.
+func SlogAttrOf(flaker Flaker, key string, f Flake) slog.Attr {
+	id := slog.String("id", f.Base64())
+	info, err := flaker.Inspect(f)
+	if err != nil {
+		return slog.Group(key, id)
+	}
+	return slog.Group(key, id, slog.Time("time", info.Time), slog.Int("machine", int(info.MachineId)))
+}
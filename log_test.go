@@ -0,0 +1,41 @@
+package flake
+
+import (
+	"bytes"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSlogAttr(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	f, _ := GenerateAt(time.Now().Add(-time.Hour), 1)
+	info, _ := Inspect(f)
+	logger.Info("created", SlogAttr("user", f))
+	expected := "level=INFO msg=created user.id=" + f.Base64() + " user.time=" + info.Time.Format("2006-01-02T15:04:05.000Z07:00") +
+		" user.machine=" + strconv.Itoa(int(info.MachineId)) + "\n"
+	if line := buf.String(); line != expected {
+		t.Errorf("Expected %q but got %q", expected, line)
+	}
+
+	// The time of a flake of another epoch start
+	other := WithEpochStart(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)).WithMachineId(3)
+	f = other.Next()
+	info, _ = other.Inspect(f)
+	if attrs := SlogAttrOf(other, "user", f).Value.Group(); len(attrs) != 3 || !attrs[1].Value.Time().Equal(info.Time) || attrs[2].Value.Int64() != 3 {
+		t.Errorf("Expected the time %s and machine-id 3 of the flaker but got %v", info.Time, attrs)
+	}
+
+	if attr := SlogAttr("id", Nil); len(attr.Value.Group()) != 1 {
+		t.Errorf("Expected only the id of Nil but got %v", attr)
+	}
+}
@@ -0,0 +1,71 @@
+package flake
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Metrics are the counters of a generator since its creation and the use of
+// the sequence of the current interval, e.g. for monitoring. Copies of the
+// With methods and Clone start with zero counters.
+type Metrics struct {
+	// IDs is the count of generated IDs
+	IDs uint64 `json:"ids"`
+	// Exhausted is the count of exhausted sequences, which borrowed future
+	// intervals, spun or failed according to the sequence policy
+	Exhausted uint64 `json:"exhausted"`
+	// ClockRegressions is the count of detected clock regressions
+	ClockRegressions uint64 `json:"clockRegressions"`
+	// EntropyReads is the count of random reads, EntropyErrors the count of
+	// the failed ones
+	EntropyReads  uint64 `json:"entropyReads"`
+	EntropyErrors uint64 `json:"entropyErrors"`
+	// LockWait is the accumulated time waiting for the lock serializing the
+	// saves of the state store
+	LockWait time.Duration `json:"lockWait"`
+	// Utilization is the share of the sequence capacity of the current
+	// interval in use, above 1 while borrowing future intervals
+	Utilization float64 `json:"utilization"`
+}
+
+// MetricsReporter is implemented by the flakers of this package. It's an
+// optional interface of Flaker, so other implementations don't have to
+// provide it.
+type MetricsReporter interface {
+	Metrics() Metrics
+}
+
+// counters are the atomic counters of Metrics
+type counters struct {
+	ids, exhausted, regressions, entropyReads, entropyErrors atomic.Uint64
+	lockWait                                                 atomic.Int64
+}
+
+// Metrics returns the counters of the generator and the utilization of the
+// sequence of the current interval
+func (g *flaker) Metrics() Metrics {
+	return Metrics{
+		IDs:              g.counters.ids.Load(),
+		Exhausted:        g.counters.exhausted.Load(),
+		ClockRegressions: g.counters.regressions.Load(),
+		EntropyReads:     g.counters.entropyReads.Load(),
+		EntropyErrors:    g.counters.entropyErrors.Load(),
+		LockWait:         time.Duration(g.counters.lockWait.Load()),
+		Utilization:      g.utilization(),
+	}
+}
+
+// utilization returns the share of the sequence capacity of the current
+// interval in use
+func (g *flaker) utilization() float64 {
+	if g.mode == ModeRandom {
+		return 0
+	}
+	l := &g.layout
+	current, counter := unpack(atomic.LoadUint64(&g.state))
+	interval := ((g.now() - g.epochStart) >> l.ResolutionBits) & (1<<l.IntervalBits - 1)
+	if current+l.borrowed(counter) < interval {
+		return 0
+	}
+	return float64(counter-(g.shard-g.shards)) / float64(l.SequenceCapacity())
+}
@@ -0,0 +1,52 @@
+package flake
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// metricsOf returns the Metrics of a flaker of this package
+func metricsOf(f Flaker) Metrics {
+	return f.(MetricsReporter).Metrics()
+}
+
+func TestMetrics(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f, err := New(SetClock(clock), SetMachineId(1), SetStateStore(FileStore(filepath.Join(t.TempDir(), "state"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := metricsOf(f); m.IDs != 0 || m.Utilization != 0 {
+		t.Errorf("Expected no IDs of a new generator but got %+v", m)
+	}
+	f.NextN(SequenceCapacity / 2)
+	f.Next()
+	m := metricsOf(f)
+	if m.IDs != SequenceCapacity/2+1 || m.EntropyReads == 0 || m.Exhausted != 0 {
+		t.Errorf("Expected %d IDs with entropy reads but got %+v", SequenceCapacity/2+1, m)
+	}
+	if m.Utilization < 0.5 || m.Utilization > 0.51 {
+		t.Errorf("Expected a utilization of 0.5 but got %g", m.Utilization)
+	}
+
+	f.NextN(SequenceCapacity)
+	if m := metricsOf(f); m.Exhausted != 1 || m.Utilization <= 1 {
+		t.Errorf("Expected an exhausted sequence borrowing the next interval but got %+v", m)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if m := metricsOf(f); m.Utilization != 0 {
+		t.Errorf("Expected no utilization of a new interval but got %g", m.Utilization)
+	}
+
+	g := regress(WithMode(ModeRaw), time.Minute)
+	g.Next()
+	if m := metricsOf(g); m.ClockRegressions != 1 || m.IDs != 1 {
+		t.Errorf("Expected a clock regression but got %+v", m)
+	}
+	random := WithMode(ModeRandom).WithEntropy(failingReader{}).WithEntropyPolicy(EntropyFallback, nil)
+	random.NextN(3)
+	if m := metricsOf(random); m.IDs != 3 || m.EntropyErrors == 0 || m.Utilization != 0 {
+		t.Errorf("Expected 3 random IDs with entropy errors but got %+v", m)
+	}
+}
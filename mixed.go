@@ -0,0 +1,73 @@
+package flake
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	randv2 "math/rand/v2"
+	"sync"
+	"time"
+)
+
+// mixedEntropy xors the bytes of several sources
+type mixedEntropy struct {
+	mutex   sync.Mutex
+	prng    *randv2.ChaCha8
+	sources []Entropy
+	buf     []byte
+	reads   uint64
+}
+
+// NewMixedEntropy returns an Entropy xoring the bytes of crypto/rand, a
+// process-local ChaCha8 generator, time jitter and the optional additional
+// sources, so a failure or weakness of a single source doesn't make the bytes
+// predictable. Failing sources are left out of a read; as the generator and
+// the jitter never fail, neither does the mix. The generator is seeded from
+// crypto/rand, if available, and the time. It's safe for concurrent use.
+func NewMixedEntropy(additional ...Entropy) Entropy {
+	var seed [32]byte
+	_, _ = io.ReadFull(randReader, seed[:])
+	seed = sha256.Sum256(binary.BigEndian.AppendUint64(seed[:], uint64(time.Now().UnixNano())))
+	return &mixedEntropy{
+		prng:    randv2.NewChaCha8(seed),
+		sources: append([]Entropy{randReader}, additional...),
+	}
+}
+
+// Read fills p with the mix of the sources
+func (e *mixedEntropy) Read(p []byte) (int, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.reads++
+	jitter := randv2.NewChaCha8(e.jitter())
+	for i := 0; i < len(p); i += 8 {
+		var word [8]byte
+		binary.LittleEndian.PutUint64(word[:], e.prng.Uint64()^jitter.Uint64())
+		copy(p[i:], word[:])
+	}
+	if cap(e.buf) < len(p) {
+		e.buf = make([]byte, len(p))
+	}
+	buf := e.buf[:len(p)]
+	for _, source := range e.sources {
+		if _, err := io.ReadFull(source, buf); err != nil {
+			continue
+		}
+		for i, b := range buf {
+			p[i] ^= b
+		}
+	}
+	return len(p), nil
+}
+
+// jitter returns a digest of the timing noise of consecutive clock readings
+func (e *mixedEntropy) jitter() [32]byte {
+	var samples [8 * 18]byte
+	binary.LittleEndian.PutUint64(samples[:], e.reads)
+	start := time.Now()
+	binary.LittleEndian.PutUint64(samples[8:], uint64(start.UnixNano()))
+	for i := 16; i < len(samples); i += 8 {
+		binary.LittleEndian.PutUint64(samples[i:], uint64(time.Since(start)))
+	}
+	return sha256.Sum256(samples[:])
+}
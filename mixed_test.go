@@ -0,0 +1,40 @@
+package flake
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/bits"
+	"testing"
+)
+
+func TestMixedEntropy(t *testing.T) {
+	defer func() { randReader = rand.Reader }()
+	for _, reader := range []Entropy{rand.Reader, failingReader{}} {
+		randReader = reader
+		e := NewMixedEntropy(NewDeterministicEntropy(1), failingReader{})
+		p, q := make([]byte, 1001), make([]byte, 1001)
+		if n, err := e.Read(p); n != len(p) || err != nil {
+			t.Errorf("Expected to read %d bytes but got %d: %v", len(p), n, err)
+		}
+		ones := 0
+		for _, b := range p {
+			ones += bits.OnesCount8(b)
+		}
+		if ones < 3700 || ones > 4300 {
+			t.Errorf("Expected ~4000 bits set of 8008 but got %d", ones)
+		}
+		NewDeterministicEntropy(1).Read(q)
+		if bytes.Equal(p, q) {
+			t.Errorf("Expected the mix to differ from the deterministic source")
+		}
+		if NewMixedEntropy(NewDeterministicEntropy(1)).Read(q); bytes.Equal(p, q) {
+			t.Errorf("Expected another mix of another mixer")
+		}
+	}
+
+	randReader = failingReader{}
+	if _, err := WithEntropy(NewMixedEntropy()).NextErr(); errors.Is(err, ErrEntropy) {
+		t.Errorf("Expected the mix to survive failing reads of crypto/rand but got %v", err)
+	}
+}
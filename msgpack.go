@@ -0,0 +1,40 @@
+package flake
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MsgpackExt is the MessagePack extension type of flakes. Change it before
+// encoding anything if it collides with other extensions of an application.
+var MsgpackExt int8 = 70
+
+const (
+	msgpackNil     = 0xc0
+	msgpackFixExt8 = 0xd7
+)
+
+// MarshalMsgpack encodes the flake as MessagePack fixext 8 value of the type
+// MsgpackExt holding the 8 big endian bytes. It implements the Marshaler of
+// github.com/vmihailenco/msgpack, which writes the returned bytes as they are.
+func (f Flake) MarshalMsgpack() ([]byte, error) {
+	b := make([]byte, 10)
+	b[0], b[1] = msgpackFixExt8, byte(MsgpackExt)
+	binary.BigEndian.PutUint64(b[2:], uint64(f))
+	return b, nil
+}
+
+// UnmarshalMsgpack decodes a flake encoded with MarshalMsgpack. A MessagePack
+// nil results in Nil. It implements the Unmarshaler of
+// github.com/vmihailenco/msgpack, which passes the complete encoded value.
+func (f *Flake) UnmarshalMsgpack(b []byte) error {
+	switch {
+	case len(b) == 1 && b[0] == msgpackNil:
+		*f = Nil
+	case len(b) == 10 && b[0] == msgpackFixExt8 && int8(b[1]) == MsgpackExt:
+		*f = Flake(binary.BigEndian.Uint64(b[2:]))
+	default:
+		return fmt.Errorf("%w: msgpack value %x", ErrInvalidEncoding, b)
+	}
+	return nil
+}
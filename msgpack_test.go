@@ -0,0 +1,28 @@
+package flake
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestMsgpack(t *testing.T) {
+	f := Flake(0x0123456789abcdef)
+	b, err := f.MarshalMsgpack()
+	expected := []byte{0xd7, 70, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	if err != nil || !bytes.Equal(b, expected) {
+		t.Errorf("Expected %x but got %x: %v", expected, b, err)
+	}
+	var g Flake
+	if err := g.UnmarshalMsgpack(b); err != nil || g != f {
+		t.Errorf("Expected %d but got %d: %v", f, g, err)
+	}
+	if err := g.UnmarshalMsgpack([]byte{0xc0}); err != nil || g != Nil {
+		t.Errorf("Expected Nil of msgpack nil but got %d: %v", g, err)
+	}
+	for _, invalid := range [][]byte{nil, b[:9], {0xd7, 71, 0, 0, 0, 0, 0, 0, 0, 1}, {0xcf, 0, 0, 0, 0, 0, 0, 0, 1}} {
+		if err := g.UnmarshalMsgpack(invalid); !errors.Is(err, ErrInvalidEncoding) {
+			t.Errorf("Expected ErrInvalidEncoding for %x but got %v", invalid, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package flake
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+// ntpEpochOffset is the number of seconds between 1/1/1900 and 1/1/1970
+const ntpEpochOffset = 2208988800
+
+// ErrInvalidNTPResponse is returned when a NTP server sends an unusable answer.
+var ErrInvalidNTPResponse = errors.New("invalid NTP response")
+
+// NTPReference returns a ClockReference querying the specified NTP server
+// (host or host:port) with a single SNTP request.
+func NTPReference(server string, timeout time.Duration) ClockReference {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	return func(clock Clock) (time.Duration, error) {
+		conn, err := net.DialTimeout("udp", server, timeout)
+		if err != nil {
+			return 0, err
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+
+		packet := make([]byte, 48, 48)
+		packet[0] = 0x1b // no leap indicator, version 3, client mode
+		sent := clock.Now()
+		if _, err = conn.Write(packet); err != nil {
+			return 0, err
+		}
+		if n, err := conn.Read(packet); err != nil {
+			return 0, err
+		} else if n < 48 || packet[0]&0x07 != 4 || packet[1] == 0 {
+			// Not a server response or a kiss-o'-death packet
+			return 0, ErrInvalidNTPResponse
+		}
+		received := clock.Now()
+
+		serverReceived := ntpTime(packet[32:40])
+		serverSent := ntpTime(packet[40:48])
+		offset := (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2
+		return -offset, nil
+	}
+}
+
+// ntpTime decodes a 64 bit NTP timestamp
+func ntpTime(b []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint32(b[0:4])) - ntpEpochOffset
+	fraction := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(seconds, (fraction*1e9)>>32)
+}
@@ -0,0 +1,54 @@
+package flake
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// serveNTP answers NTP requests with the local time shifted by offset.
+func serveNTP(t *testing.T, offset time.Duration) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listening failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		packet := make([]byte, 48)
+		for {
+			_, addr, err := conn.ReadFrom(packet)
+			if err != nil {
+				return
+			}
+			now := time.Now().Add(offset)
+			seconds := uint32(now.Unix() + ntpEpochOffset)
+			fraction := uint32((int64(now.Nanosecond()) << 32) / 1e9)
+			packet[0], packet[1] = 0x1c, 1 // version 3, server mode, stratum 1
+			for _, i := range []int{32, 40} {
+				binary.BigEndian.PutUint32(packet[i:], seconds)
+				binary.BigEndian.PutUint32(packet[i+4:], fraction)
+			}
+			_, _ = conn.WriteTo(packet, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestNTPReference(t *testing.T) {
+	server := serveNTP(t, -time.Hour)
+	skew, err := NTPReference(server, time.Second)(SystemClock)
+	if err != nil {
+		t.Errorf("Querying NTP server failed: %v", err)
+	}
+	if diff := skew - time.Hour; diff > time.Second || diff < -time.Second {
+		t.Errorf("Expected a skew of 1h but got %s", skew)
+	}
+
+	if _, err := WithClockCheck(NTPReference(server, time.Second), time.Minute, nil); err == nil {
+		t.Errorf("Expected the clock check to fail with a skew of %s", skew)
+	}
+	if _, err := WithClockCheck(NTPReference(serveNTP(t, 0), time.Second), time.Minute, nil); err != nil {
+		t.Errorf("Expected the clock check to pass but got %v", err)
+	}
+}
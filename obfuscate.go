@@ -0,0 +1,72 @@
+package flake
+
+import "fmt"
+
+// Obfuscator is a cheap keyed and reversible mapping of the 63 bit IDs, the
+// alternative to the encryption of Flaker.WithEncryptionKey where the IDs
+// only need to look random. It multiplies by an odd multiplier modulo 2^63,
+// xor-shifts the upper bits into the lower ones, multiplies again and xors
+// the result with a key. The sign bit stays untouched. Unlike the encryption it doesn't
+// withstand an analysis of known pairs of raw and obfuscated IDs.
+type Obfuscator struct {
+	multiplier, inverse, xor uint64
+}
+
+// bits63 masks the lower 63 bits
+const bits63 = 1<<63 - 1
+
+// NewObfuscator returns an Obfuscator of the odd multiplier, e.g. a large
+// prime, and the xor key, both to be kept secret. The returned errors wrap
+// ErrInvalidConfig.
+func NewObfuscator(multiplier, xor uint64) (*Obfuscator, error) {
+	if multiplier&1 == 0 {
+		return nil, fmt.Errorf("%w: even obfuscator multiplier %d", ErrInvalidConfig, multiplier)
+	}
+	// Newton's iteration doubles the correct low bits of the inverse
+	inverse := multiplier
+	for i := 0; i < 5; i++ {
+		inverse *= 2 - multiplier*inverse
+	}
+	return &Obfuscator{
+		multiplier: multiplier & bits63,
+		inverse:    inverse & bits63,
+		xor:        xor & bits63,
+	}, nil
+}
+
+// Encode returns the obfuscated form of the raw flake
+func (o *Obfuscator) Encode(raw Flake) Flake {
+	return Flake(o.encode(int64(raw)))
+}
+
+// Decode returns the raw form of the obfuscated flake
+func (o *Obfuscator) Decode(obfuscated Flake) Flake {
+	return Flake(o.decode(int64(obfuscated)))
+}
+
+func (o *Obfuscator) encode(raw int64) int64 {
+	x := (uint64(raw) * o.multiplier) & bits63
+	x ^= x >> 32
+	x = (x*o.multiplier)&bits63 ^ o.xor
+	return int64(x) | raw&(-1<<63)
+}
+
+func (o *Obfuscator) decode(obfuscated int64) int64 {
+	x := ((uint64(obfuscated) ^ o.xor) * o.inverse) & bits63
+	x ^= x >> 32
+	x = (x * o.inverse) & bits63
+	return int64(x) | obfuscated&(-1<<63)
+}
+
+// Returns a new Flaker instance copy obfuscating its IDs in ModeShuffled with
+// the obfuscator instead of shuffling their bits. The owner of the obfuscator
+// recovers the sortable raw form with Unshuffle or Obfuscator.Decode. A nil
+// obfuscator restores the shuffle.
+func (g *flaker) WithObfuscator(obfuscator *Obfuscator) Flaker {
+	c := g.derive()
+	c.codec = nil
+	if obfuscator != nil {
+		c.codec = obfuscator
+	}
+	return c
+}
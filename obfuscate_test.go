@@ -0,0 +1,54 @@
+package flake
+
+import (
+	"errors"
+	mathrand "math/rand"
+	"testing"
+)
+
+func TestObfuscator(t *testing.T) {
+	o, err := NewObfuscator(0x9e3779b97f4a7c15, 0x5bd1e9955bd1e995)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	r := mathrand.New(mathrand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		raw := Flake(r.Uint64())
+		if o.Decode(o.Encode(raw)) != raw || (raw < 0) != (o.Encode(raw) < 0) {
+			t.Errorf("Expected the obfuscation of %x to be reversible and keep the sign", uint64(raw))
+			return
+		}
+	}
+	if a, b := o.Encode(0x1234_0000_0105), o.Encode(0x1234_0000_0205); a&0xff == b&0xff || a>>32 == b>>32 {
+		t.Errorf("Expected consecutive IDs to differ in the lower and upper bits but got %x and %x", a, b)
+	}
+	if _, err := NewObfuscator(2, 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for an even multiplier but got %v", err)
+	}
+}
+
+func TestWithObfuscator(t *testing.T) {
+	o, _ := NewObfuscator(0x9e3779b97f4a7c15, 42)
+	f := WithMachineId(5).WithObfuscator(o)
+	first, second := f.Next(), f.Next()
+	raw := o.Decode(first)
+	if raw&0xff != 5 || raw != f.Unshuffle(first) || f.Unshuffle(second) <= raw {
+		t.Errorf("Expected sortable raw IDs of machine-id 5")
+	}
+	if f.Shuffle(raw) != first || f.Validate(first, 5) != nil || first == Shuffle(raw) {
+		t.Errorf("Expected the obfuscation to be reversible, valid and to replace the shuffle")
+	}
+	if g := f.WithObfuscator(nil); g.Shuffle(raw) != Shuffle(raw) {
+		t.Errorf("Expected a nil obfuscator to restore the shuffle")
+	}
+	if g, _ := New(SetObfuscator(o)); g.Unshuffle(first) != raw {
+		t.Errorf("Expected the same obfuscation for the same obfuscator")
+	}
+}
+
+func BenchmarkObfuscate(b *testing.B) {
+	o, _ := NewObfuscator(0x9e3779b97f4a7c15, 42)
+	for i := 0; i < b.N; i++ {
+		shuffled = o.encode(int64(i))
+	}
+}
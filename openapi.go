@@ -0,0 +1,96 @@
+package flake
+
+// Schema is the JSON Schema of the flakes of a format, usable as OpenAPI
+// schema object. Marshal it to JSON or copy its values into the annotations
+// of a generator, e.g. the swaggertype, format and example tags of swaggo.
+type Schema struct {
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Pattern     string `json:"pattern,omitempty"`
+	MinLength   int    `json:"minLength,omitempty"`
+	MaxLength   int    `json:"maxLength,omitempty"`
+	Minimum     int64  `json:"minimum,omitempty"`
+	Description string `json:"description,omitempty"`
+	Example     any    `json:"example,omitempty"`
+}
+
+// exampleFlake is the flake of the examples
+const exampleFlake Flake = 0x0123456789abcdef
+
+// Example returns an example flake encoded in the format for API docs. It
+// returns the same flake for all formats.
+func Example(format Format) string {
+	return exampleFlake.Encode(format)
+}
+
+// base58Pattern matches the base58 flakes up to MaxFlake "NQm6nKp8qFC" digit
+// by digit, the alphabet is in ASCII order
+const base58Pattern = "^([1-9A-HJ-M][1-9A-HJ-NP-Za-km-z]{10}|N[1-9A-HJ-NP][1-9A-HJ-NP-Za-km-z]{9}|" +
+	"NQ[1-9A-HJ-NP-Za-k][1-9A-HJ-NP-Za-km-z]{8}|NQm[1-5][1-9A-HJ-NP-Za-km-z]{7}|" +
+	"NQm6[1-9A-HJ-NP-Za-km][1-9A-HJ-NP-Za-km-z]{6}|NQm6n[1-9A-HJ][1-9A-HJ-NP-Za-km-z]{5}|" +
+	"NQm6nK[1-9A-HJ-NP-Za-km-o][1-9A-HJ-NP-Za-km-z]{4}|NQm6nKp[1-7][1-9A-HJ-NP-Za-km-z]{3}|" +
+	"NQm6nKp8[1-9A-HJ-NP-Za-km-p][1-9A-HJ-NP-Za-km-z]{2}|NQm6nKp8q[1-9A-E][1-9A-HJ-NP-Za-km-z]|" +
+	"NQm6nKp8qF[1-9AB]|NQm6nKp8qFC)$"
+
+// decimalPattern matches the decimal flakes of up to 18 digits and those of
+// 19 digits up to MaxFlake 9223372036854775807 digit by digit
+const decimalPattern = "^([1-9][0-9]{0,17}|[1-8][0-9]{18}|9[01][0-9]{17}|92[01][0-9]{16}|922[0-2][0-9]{15}|" +
+	"9223[0-2][0-9]{14}|92233[0-6][0-9]{13}|922337[01][0-9]{12}|92233720[0-2][0-9]{10}|" +
+	"922337203[0-5][0-9]{9}|9223372036[0-7][0-9]{8}|92233720368[0-4][0-9]{7}|" +
+	"922337203685[0-3][0-9]{6}|9223372036854[0-6][0-9]{5}|92233720368547[0-6][0-9]{4}|" +
+	"922337203685477[0-4][0-9]{3}|9223372036854775[0-7][0-9]{2}|922337203685477580[0-6]|" +
+	"9223372036854775807)$"
+
+// Schema returns the JSON Schema of the string encoding of the format. The
+// patterns only accept the canonical encodings of DecodeFormat within the 63
+// bit range of flakes. FormatBytes describes the standard base64 encoding of
+// the 8 bytes of encoding/json.
+func (f Format) Schema() Schema {
+	s := Schema{Type: "string", Format: "flake-" + f.String(), Example: Example(f)}
+	switch f {
+	case FormatHex:
+		s.Pattern = "^[0-7][0-9a-f]{15}$"
+		s.Description = "flake ID of 16 lower case hex digits"
+	case FormatBase32:
+		s.Pattern = "^[0-9A-F][0-9A-V]{11}[02468ACEGIKMOQSU]$"
+		s.Description = "flake ID of 13 characters of the base32 extended hex alphabet"
+	case FormatBase64:
+		s.Pattern = "^[A-Za-f][A-Za-z0-9_-]{9}[AEIMQUYcgkosw048]$"
+		s.Description = "flake ID of 11 characters of the URL safe base64 alphabet"
+	case FormatBase58:
+		s.Pattern = base58Pattern
+		s.Description = "flake ID of 11 characters of the bitcoin base58 alphabet"
+	case FormatDecimal:
+		s.Pattern = decimalPattern
+		s.Description = "flake ID as decimal number"
+	case FormatBytes:
+		s.Format = "byte"
+		s.Pattern = "^[A-Za-z0-9+/]{11}=$"
+		s.Description = "flake ID of 8 big endian bytes in standard base64"
+		s.Example = "ASNFZ4mrze8="
+	default:
+		return Schema{}
+	}
+	s.MinLength, s.MaxLength = len(s.Example.(string)), encodedLen(f)
+	switch f {
+	case FormatBytes:
+		s.MaxLength = s.MinLength
+	case FormatDecimal:
+		// 1 to MaxFlake of 19 digits
+		s.MinLength, s.MaxLength = 1, 19
+	}
+	return s
+}
+
+// IntegerSchema returns the JSON Schema of flakes marshaled as JSON numbers,
+// the default of Flake. Note that JavaScript numbers lose precision beyond
+// 2^53, prefer string formats for browsers.
+func IntegerSchema() Schema {
+	return Schema{
+		Type:        "integer",
+		Format:      "int64",
+		Minimum:     1,
+		Description: "flake ID",
+		Example:     int64(exampleFlake),
+	}
+}
@@ -0,0 +1,64 @@
+package flake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	mathrand "math/rand"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	flakes := []Flake{1, 255, MaxFlake, exampleFlake, Next(), NextRaw()}
+	for format := FormatHex; format <= FormatDecimal; format++ {
+		s := format.Schema()
+		pattern := regexp.MustCompile(s.Pattern)
+		for _, f := range flakes {
+			encoded := f.Encode(format)
+			if format == FormatBytes {
+				encoded = base64.StdEncoding.EncodeToString(f.Bytes())
+			}
+			if !pattern.MatchString(encoded) {
+				t.Errorf("Expected the %s pattern %s to match %q", format, s.Pattern, encoded)
+			}
+			if len(encoded) > s.MaxLength || len(encoded) < s.MinLength {
+				t.Errorf("Expected the length of %q within %d and %d", encoded, s.MinLength, s.MaxLength)
+			}
+		}
+		if format != FormatBytes && pattern.MatchString(Flake(-1).Encode(format)) {
+			t.Errorf("Expected the %s pattern to reject negative flakes", format)
+		}
+	}
+	// Base58 and decimal flakes beyond MaxFlake of the same length
+	for _, n := range []uint64{1 << 63, 1<<63 + 1, 1<<64 - 1, 9999999999999999999} {
+		for _, format := range []Format{FormatBase58, FormatDecimal} {
+			encoded := string(appendBase58(nil, Flake(n)))
+			if format == FormatDecimal {
+				encoded = strconv.FormatUint(n, 10)
+			}
+			if regexp.MustCompile(format.Schema().Pattern).MatchString(encoded) {
+				t.Errorf("Expected the %s pattern to reject %q beyond MaxFlake", format, encoded)
+			}
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		f := Flake(mathrand.Int63n(int64(MaxFlake)) + 1)
+		for _, format := range []Format{FormatBase58, FormatDecimal} {
+			if !regexp.MustCompile(format.Schema().Pattern).MatchString(f.Encode(format)) {
+				t.Errorf("Expected the %s pattern to match %q", format, f.Encode(format))
+			}
+		}
+	}
+	if s := FormatUnknown.Schema(); s.Type != "" {
+		t.Errorf("Expected no schema of an unknown format but got %+v", s)
+	}
+
+	b, _ := json.Marshal(IntegerSchema())
+	if expected := `{"type":"integer","format":"int64","minimum":1,"description":"flake ID","example":81985529216486895}`; string(b) != expected {
+		t.Errorf("Expected %s but got %s", expected, b)
+	}
+	if Example(FormatHex) != "0123456789abcdef" {
+		t.Errorf("Unexpected example %s", Example(FormatHex))
+	}
+}
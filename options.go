@@ -0,0 +1,287 @@
+package flake
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Mode defines the representation of the generated flakes.
+type Mode int
+
+const (
+	// ModeShuffled shuffles the bits of the IDs so they neither reveal their
+	// order nor look alike. This is the mode of Default.
+	ModeShuffled Mode = iota
+	// ModeRaw keeps the IDs sortable by their generation time. This is the
+	// mode of Raw.
+	ModeRaw
+	// ModeRandom ignores the clock and the sequence and returns 63 random
+	// bits for unpredictable IDs. Their uniqueness is probabilistic only: the
+	// chance of any collision reaches 50% after ~3 billion IDs.
+	ModeRandom
+)
+
+// String returns the name of the mode
+func (m Mode) String() string {
+	switch m {
+	case ModeShuffled:
+		return "shuffled"
+	case ModeRaw:
+		return "raw"
+	case ModeRandom:
+		return "random"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the name of the mode
+func (m Mode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText sets the mode of the given name
+func (m *Mode) UnmarshalText(text []byte) error {
+	return unmarshalName(m, text, ModeRandom, "mode")
+}
+
+// Option configures a Flaker created with New.
+type Option func(o *options)
+
+// options collects the configuration of New
+type options struct {
+	config
+	machineId int // -1 to derive it from the local IPv4 address
+	store     StateStore
+	expvar    string // name to publish the generator under
+	err       error  // first error of an option
+}
+
+// SetMachineId sets the machine-id, which must fit into the machine-id bits
+// of the layout. It defaults to the lower bits of the first private IPv4
+// address.
+func SetMachineId(machineId byte) Option {
+	return func(o *options) {
+		o.machineId = int(machineId)
+	}
+}
+
+// SetEpochStart sets the epoch start, which defaults to 1/1/2020.
+func SetEpochStart(time time.Time) Option {
+	return func(o *options) {
+		o.epochStart = time.UnixNano()
+	}
+}
+
+// SetLayout sets the bit layout, which defaults to DefaultLayout.
+func SetLayout(layout Layout) Option {
+	return func(o *options) {
+		o.layout = layout
+	}
+}
+
+// SetMode sets the representation of the IDs, which defaults to ModeShuffled.
+func SetMode(mode Mode) Option {
+	return func(o *options) {
+		o.mode = mode
+	}
+}
+
+// SetClock sets the clock, which defaults to SystemClock.
+func SetClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// SetEntropySource sets the source of the random bits, which defaults to
+// EntropyCrypto.
+func SetEntropySource(source EntropySource) Option {
+	return func(o *options) {
+		o.entropySource = source
+	}
+}
+
+// SetEntropy sets the entropy like Flaker.WithEntropy.
+func SetEntropy(entropy Entropy) Option {
+	return func(o *options) {
+		o.entropy = newEntropyReader(entropy)
+	}
+}
+
+// SetReserved adds reserved IDs like Flaker.WithReserved.
+func SetReserved(reserved ...Reserved) Option {
+	return func(o *options) {
+		o.reserved = append(o.reserved[:len(o.reserved):len(o.reserved)], reserved...)
+	}
+}
+
+// SetEncryptionKey sets the key of the encryption like
+// Flaker.WithEncryptionKey.
+func SetEncryptionKey(key []byte) Option {
+	return func(o *options) {
+		o.codec = nil
+		if len(key) > 0 {
+			cipher, err := newFF1(key)
+			if err != nil && o.err == nil {
+				o.err = err
+			}
+			if err == nil {
+				o.codec = cipher
+			}
+		}
+	}
+}
+
+// SetObfuscator sets the obfuscator like Flaker.WithObfuscator.
+func SetObfuscator(obfuscator *Obfuscator) Option {
+	return func(o *options) {
+		o.codec = nil
+		if obfuscator != nil {
+			o.codec = obfuscator
+		}
+	}
+}
+
+// SetShuffleKey sets the key of the shuffle like Flaker.WithShuffleKey.
+func SetShuffleKey(key []byte) Option {
+	return func(o *options) {
+		o.permutation = nil
+		if len(key) > 0 {
+			o.permutation = newPermutation(key)
+		}
+	}
+}
+
+// SetClockPolicy sets the clock policy like Flaker.WithClockPolicy.
+func SetClockPolicy(policy ClockPolicy, onRegression func(lag time.Duration)) Option {
+	return func(o *options) {
+		o.clockPolicy, o.onRegression = policy, onRegression
+	}
+}
+
+// SetEpochPolicy sets the epoch policy like Flaker.WithEpochPolicy.
+func SetEpochPolicy(policy EpochPolicy, onOverflow func(elapsed time.Duration)) Option {
+	return func(o *options) {
+		o.epochPolicy, o.onEpochOverflow = policy, onOverflow
+	}
+}
+
+// SetEpochWarning sets the epoch warning like Flaker.WithEpochWarning.
+func SetEpochWarning(margin time.Duration, onWarning func(remaining time.Duration)) Option {
+	return func(o *options) {
+		o.epochMargin, o.onEpochWarning = margin, onWarning
+	}
+}
+
+// SetSequencePolicy sets the sequence policy like Flaker.WithSequencePolicy.
+func SetSequencePolicy(policy SequencePolicy, onExhausted func(ahead time.Duration)) Option {
+	return func(o *options) {
+		o.sequencePolicy, o.onExhausted = policy, onExhausted
+	}
+}
+
+// SetEntropyPolicy sets the entropy policy like Flaker.WithEntropyPolicy.
+func SetEntropyPolicy(policy EntropyPolicy, onError func(err error)) Option {
+	return func(o *options) {
+		o.entropyPolicy, o.onEntropyError = policy, onError
+	}
+}
+
+// SetEventHook adds a hook called on notable events like WithEventHook
+func SetEventHook(hook func(event Event)) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, hook)
+	}
+}
+
+// SetHealthThresholds sets the thresholds of Check like
+// HealthChecker.WithHealthThresholds.
+func SetHealthThresholds(thresholds HealthThresholds) Option {
+	return func(o *options) {
+		o.health = &thresholds
+	}
+}
+
+// SetStateStore sets the state store like Flaker.WithStateStore.
+func SetStateStore(store StateStore) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// New returns a new Flaker configured by the options. Unlike chaining the
+// With* methods it validates the combination of the options. The returned
+// errors wrap ErrInvalidConfig or result from loading the state store.
+func New(opts ...Option) (Flaker, error) {
+	o := options{
+		config: config{
+			shards:     1,
+			clock:      SystemClock,
+			layout:     DefaultLayout,
+			epochStart: 1577833200000000000, // 1/1/2020
+		},
+		machineId: -1,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	g := &flaker{mutex: &sync.Mutex{}, config: o.config}
+	g.anchor = g.clock.Now()
+	if o.machineId < 0 {
+		g.machineId = byte(getLocalIPv4() & (1<<g.layout.MachineIdBits - 1))
+		g.derived = true
+	} else {
+		g.machineId = byte(o.machineId)
+	}
+	var f Flaker = g
+	if o.store != nil {
+		var err error
+		if f, err = g.WithStateStore(o.store); err != nil {
+			return nil, err
+		}
+	}
+	if o.expvar != "" {
+		if err := PublishExpvar(o.expvar, f); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// validate checks the combination of the options
+func (o *options) validate() error {
+	if o.err != nil {
+		return o.err
+	}
+	if err := o.layout.Validate(); err != nil {
+		return err
+	}
+	switch {
+	case o.machineId >= o.layout.MachineIds():
+		return fmt.Errorf("%w: machine-id %d exceeds %d bits", ErrInvalidConfig, o.machineId, o.layout.MachineIdBits)
+	case o.clock == nil:
+		return fmt.Errorf("%w: no clock", ErrInvalidConfig)
+	case o.epochStart > o.clock.Now().UnixNano():
+		return fmt.Errorf("%w: epoch start in the future", ErrInvalidConfig)
+	case o.mode < ModeShuffled || o.mode > ModeRandom:
+		return fmt.Errorf("%w: %s mode", ErrInvalidConfig, o.mode)
+	case o.entropySource != EntropyCrypto && o.entropySource != EntropyChaCha8:
+		return fmt.Errorf("%w: %s entropy source", ErrInvalidConfig, o.entropySource)
+	case o.clockPolicy < ClockBorrow || o.clockPolicy > ClockError:
+		return fmt.Errorf("%w: %s clock policy", ErrInvalidConfig, o.clockPolicy)
+	case o.epochPolicy < EpochWrap || o.epochPolicy > EpochPanic:
+		return fmt.Errorf("%w: %s epoch policy", ErrInvalidConfig, o.epochPolicy)
+	case o.sequencePolicy < SequenceBorrow || o.sequencePolicy > SequenceError:
+		return fmt.Errorf("%w: %s sequence policy", ErrInvalidConfig, o.sequencePolicy)
+	case o.entropyPolicy < EntropyError || o.entropyPolicy > EntropyFallback:
+		return fmt.Errorf("%w: %s entropy policy", ErrInvalidConfig, o.entropyPolicy)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package flake
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	f, err := New()
+	if err != nil {
+		t.Errorf("Creating flaker failed: %v", err)
+		return
+	}
+	if g, d := f.(*flaker), Default.(*flaker); g.machineId != d.machineId || g.epochStart != d.epochStart || g.mode != ModeShuffled || g.layout != DefaultLayout {
+		t.Errorf("Expected the configuration of Default")
+	}
+	if Default.Mode() != ModeShuffled || Raw.Mode() != ModeRaw || Default.MachineId() != f.MachineId() {
+		t.Errorf("Expected the getters to report the configuration of Default and Raw")
+	}
+	m := make(map[Flake]int)
+	generate(t, f, m, 1000)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := FileStore(filepath.Join(t.TempDir(), "flake.state"))
+	f, err = New(SetMachineId(7), SetEpochStart(start), SetMode(ModeRaw), SetEntropySource(EntropyChaCha8),
+		SetClockPolicy(ClockError, nil), SetSequencePolicy(SequenceSpin, nil), SetStateStore(store), nil)
+	if err != nil {
+		t.Errorf("Creating flaker failed: %v", err)
+		return
+	}
+	g := f.(*flaker)
+	if g.machineId != 7 || g.epochStart != start.UnixNano() || g.mode != ModeRaw || g.entropySource != EntropyChaCha8 ||
+		g.clockPolicy != ClockError || g.sequencePolicy != SequenceSpin || g.store != store {
+		t.Errorf("Expected the configured options to be set")
+	}
+	if f.MachineId() != 7 || !f.EpochStart().Equal(start) || f.Mode() != ModeRaw || f.Layout() != DefaultLayout {
+		t.Errorf("Expected the getters to report the configuration")
+	}
+	if id := f.Next(); id&0xff != 7 {
+		t.Errorf("Expected a raw ID of machine-id 7 but got %d", id)
+	}
+
+	for _, opts := range [][]Option{
+		{SetLayout(Layout{IntervalBits: 32, SequenceBits: 23, MachineIdBits: 4, ResolutionBits: 30})},
+		{SetLayout(Layout{IntervalBits: 32, SequenceBits: 27, MachineIdBits: 4, ResolutionBits: 30}), SetMachineId(16)},
+		{SetEpochStart(time.Now().Add(time.Hour))},
+		{SetClock(nil)},
+		{SetMode(Mode(9))},
+		{SetClockPolicy(ClockPolicy(9), nil)},
+		{SetEntropyPolicy(EntropyPolicy(-1), nil)},
+	} {
+		if _, err := New(opts...); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig but got %v", err)
+		}
+	}
+}
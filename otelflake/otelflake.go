@@ -0,0 +1,217 @@
+// Package otelflake records the generation of flakes as OpenTelemetry
+// metrics: the generated IDs, the borrowing of future intervals on exhausted
+// sequences and the generation latency, attributed by machine-id.
+//
+//	flaker, err := otelflake.Instrument(flake.Default, nil)
+package otelflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-flake"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ScopeName is the instrumentation scope of the meter
+const ScopeName = "go-flake/otelflake"
+
+// MachineIdKey is the attribute key of the machine-id
+const MachineIdKey = attribute.Key("flake.machine_id")
+
+// Flaker is a flake.Flaker recording the latency of the generation methods.
+// The generators derived by the With methods aren't instrumented, Instrument
+// them separately.
+type Flaker struct {
+	flake.Flaker
+	duration     metric.Float64Histogram
+	registration metric.Registration
+	attributes   metric.MeasurementOption
+}
+
+// Instrument returns the generator recording its metrics with a meter of the
+// provider, the global one if nil:
+//   - flake.ids.generated: count of generated IDs, the generation rate
+//   - flake.sequence.exhausted: count of exhausted sequences borrowing future
+//     intervals, spinning or failing
+//   - flake.sequence.utilization: share of the sequence capacity in use
+//   - flake.clock.regressions: count of detected clock regressions
+//   - flake.entropy.reads and flake.entropy.errors: count of random reads
+//   - flake.lock.wait: time spent waiting for the state save lock
+//   - flake.generate.duration: latency of the generation methods, with the
+//     error.type attribute on errors
+//
+// All but the latency are zero for generators which aren't a
+// flake.MetricsReporter.
+func Instrument(flaker flake.Flaker, provider metric.MeterProvider) (*Flaker, error) {
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(ScopeName)
+	f := &Flaker{
+		Flaker:     flaker,
+		attributes: metric.WithAttributes(MachineIdKey.Int(int(flaker.MachineId()))),
+	}
+	var err error
+	if f.duration, err = meter.Float64Histogram("flake.generate.duration",
+		metric.WithDescription("Duration of generating IDs."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	ids, err := meter.Int64ObservableCounter("flake.ids.generated",
+		metric.WithDescription("Count of generated IDs."), metric.WithUnit("{id}"))
+	if err != nil {
+		return nil, err
+	}
+	exhausted, err := meter.Int64ObservableCounter("flake.sequence.exhausted",
+		metric.WithDescription("Count of exhausted sequences borrowing future intervals, spinning or failing."), metric.WithUnit("{event}"))
+	if err != nil {
+		return nil, err
+	}
+	utilization, err := meter.Float64ObservableGauge("flake.sequence.utilization",
+		metric.WithDescription("Share of the sequence capacity of the current interval in use, above 1 while borrowing future intervals."), metric.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+	regressions, err := meter.Int64ObservableCounter("flake.clock.regressions",
+		metric.WithDescription("Count of detected clock regressions."), metric.WithUnit("{event}"))
+	if err != nil {
+		return nil, err
+	}
+	entropyReads, err := meter.Int64ObservableCounter("flake.entropy.reads",
+		metric.WithDescription("Count of random reads."), metric.WithUnit("{read}"))
+	if err != nil {
+		return nil, err
+	}
+	entropyErrors, err := meter.Int64ObservableCounter("flake.entropy.errors",
+		metric.WithDescription("Count of failed random reads."), metric.WithUnit("{error}"))
+	if err != nil {
+		return nil, err
+	}
+	lockWait, err := meter.Float64ObservableCounter("flake.lock.wait",
+		metric.WithDescription("Time spent waiting for the lock serializing state saves."), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	attributes := f.attributes
+	f.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		m := f.Metrics()
+		o.ObserveInt64(ids, int64(m.IDs), attributes)
+		o.ObserveInt64(exhausted, int64(m.Exhausted), attributes)
+		o.ObserveFloat64(utilization, m.Utilization, attributes)
+		o.ObserveInt64(regressions, int64(m.ClockRegressions), attributes)
+		o.ObserveInt64(entropyReads, int64(m.EntropyReads), attributes)
+		o.ObserveInt64(entropyErrors, int64(m.EntropyErrors), attributes)
+		o.ObserveFloat64(lockWait, m.LockWait.Seconds(), attributes)
+		return nil
+	}, ids, exhausted, utilization, regressions, entropyReads, entropyErrors, lockWait)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Close stops observing the metrics of the generator
+func (f *Flaker) Close() error {
+	return f.registration.Unregister()
+}
+
+// record records the duration of a generation since start
+func (f *Flaker) record(ctx context.Context, start time.Time, err error) {
+	if err == nil {
+		f.duration.Record(ctx, time.Since(start).Seconds(), f.attributes)
+		return
+	}
+	f.duration.Record(ctx, time.Since(start).Seconds(), f.attributes,
+		metric.WithAttributes(attribute.String("error.type", errorType(err))))
+}
+
+// errorType returns the error.type attribute value of a generation error
+func errorType(err error) string {
+	for _, sentinel := range []struct {
+		err  error
+		name string
+	}{
+		{flake.ErrClockRegression, "clock_regression"},
+		{flake.ErrSequenceExhausted, "sequence_exhausted"},
+		{flake.ErrEpochOverflow, "epoch_overflow"},
+		{flake.ErrStateSave, "state_save"},
+		{flake.ErrEntropy, "entropy"},
+		{context.Canceled, "canceled"},
+		{context.DeadlineExceeded, "deadline_exceeded"},
+	} {
+		if errors.Is(err, sentinel.err) {
+			return sentinel.name
+		}
+	}
+	return "_OTHER"
+}
+
+// Next generates an ID recording the latency
+func (f *Flaker) Next() flake.Flake {
+	defer f.record(context.Background(), time.Now(), nil)
+	return f.Flaker.Next()
+}
+
+// NextErr generates an ID recording the latency and the error
+func (f *Flaker) NextErr() (id flake.Flake, err error) {
+	defer func(start time.Time) { f.record(context.Background(), start, err) }(time.Now())
+	return f.Flaker.NextErr()
+}
+
+// NextContext generates an ID recording the latency and the error
+func (f *Flaker) NextContext(ctx context.Context) (id flake.Flake, err error) {
+	defer func(start time.Time) { f.record(ctx, start, err) }(time.Now())
+	return f.Flaker.NextContext(ctx)
+}
+
+// Metrics returns the Metrics of the generator, zero if it isn't a
+// flake.MetricsReporter
+func (f *Flaker) Metrics() flake.Metrics {
+	if r, ok := f.Flaker.(flake.MetricsReporter); ok {
+		return r.Metrics()
+	}
+	return flake.Metrics{}
+}
+
+// Stats returns the Stats of the generator, zero if it isn't a
+// flake.StatsReporter
+func (f *Flaker) Stats() flake.Stats {
+	if r, ok := f.Flaker.(flake.StatsReporter); ok {
+		return r.Stats()
+	}
+	return flake.Stats{}
+}
+
+// NextKind generates an ID of the kind recording the latency and the error.
+// It fails with flake.ErrInvalidConfig if the wrapped Flaker isn't a
+// flake.KindFlaker.
+func (f *Flaker) NextKind(kind byte) (id flake.Flake, err error) {
+	defer func(start time.Time) { f.record(context.Background(), start, err) }(time.Now())
+	k, ok := f.Flaker.(flake.KindFlaker)
+	if !ok {
+		return flake.Nil, fmt.Errorf("%w: %T is no flake.KindFlaker", flake.ErrInvalidConfig, f.Flaker)
+	}
+	return k.NextKind(kind)
+}
+
+// NextN generates n IDs recording the latency
+func (f *Flaker) NextN(n int) []flake.Flake {
+	defer f.record(context.Background(), time.Now(), nil)
+	return f.Flaker.NextN(n)
+}
+
+// AppendNext appends n IDs recording the latency
+func (f *Flaker) AppendNext(dst []flake.Flake, n int) []flake.Flake {
+	defer f.record(context.Background(), time.Now(), nil)
+	return f.Flaker.AppendNext(dst, n)
+}
+
+// NextPair generates an ID pair recording the latency
+func (f *Flaker) NextPair() (raw, shuffled flake.Flake) {
+	defer f.record(context.Background(), time.Now(), nil)
+	return f.Flaker.NextPair()
+}
@@ -0,0 +1,78 @@
+package otelflake
+
+import (
+	"context"
+	"testing"
+
+	"go-flake"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestInstrument(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	generator, err := flake.New(flake.SetMachineId(5))
+	if err != nil {
+		t.Fatalf("flake.New() error = %v", err)
+	}
+	flaker, err := Instrument(generator, provider)
+	if err != nil {
+		t.Fatalf("Instrument() error = %v", err)
+	}
+	defer flaker.Close()
+
+	flaker.Next()
+	flaker.NextN(10)
+	if _, err := flaker.NextErr(); err != nil {
+		t.Fatalf("NextErr() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := flaker.NextContext(ctx); err == nil {
+		t.Fatalf("NextContext() with a canceled context succeeded")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		if sm.Scope.Name != ScopeName {
+			t.Errorf("scope = %q, want %q", sm.Scope.Name, ScopeName)
+		}
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+
+	ids, ok := metrics["flake.ids.generated"].(metricdata.Sum[int64])
+	if !ok || len(ids.DataPoints) != 1 || ids.DataPoints[0].Value != 12 || !ids.IsMonotonic {
+		t.Errorf("flake.ids.generated = %+v, want a counter of 12 IDs", metrics["flake.ids.generated"])
+	} else if v, _ := ids.DataPoints[0].Attributes.Value(MachineIdKey); v.AsInt64() != 5 {
+		t.Errorf("flake.ids.generated machine-id = %v, want 5", v)
+	}
+	for _, name := range []string{"flake.sequence.exhausted", "flake.sequence.utilization", "flake.clock.regressions", "flake.entropy.reads", "flake.entropy.errors", "flake.lock.wait"} {
+		if metrics[name] == nil {
+			t.Errorf("metric %s missing", name)
+		}
+	}
+
+	duration, ok := metrics["flake.generate.duration"].(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("flake.generate.duration = %+v, want a histogram", metrics["flake.generate.duration"])
+	}
+	var count uint64
+	canceled := false
+	for _, p := range duration.DataPoints {
+		count += p.Count
+		if v, ok := p.Attributes.Value(attribute.Key("error.type")); ok && v.AsString() == "canceled" {
+			canceled = true
+		}
+	}
+	if count != 4 || !canceled {
+		t.Errorf("flake.generate.duration = %+v, want 4 recordings and a canceled one", duration.DataPoints)
+	}
+}
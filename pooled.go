@@ -0,0 +1,98 @@
+package flake
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PooledFlaker keeps a buffer of pre-generated IDs filled by a background
+// goroutine, so latency critical paths only pay for a channel receive. The
+// buffer is refilled in a batch as soon as it drops to the low watermark. Note
+// that the IDs carry the time of their generation, not of their use, so they
+// only sort roughly by the time they are handed out.
+type PooledFlaker struct {
+	base    Flaker
+	ids     chan Flake
+	low     int
+	refill  chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewPooled returns a PooledFlaker buffering up to size IDs generated by base
+// and refilling the buffer when at most low IDs are left. Call Close() to stop
+// the background generation.
+func NewPooled(base Flaker, size, low int) (*PooledFlaker, error) {
+	if base == nil {
+		return nil, fmt.Errorf("%w: pooling without a base", ErrInvalidConfig)
+	} else if size < 1 || low < 0 || low >= size {
+		return nil, fmt.Errorf("%w: pool size %d with low watermark %d", ErrInvalidConfig, size, low)
+	}
+	p := &PooledFlaker{
+		base:    base,
+		ids:     make(chan Flake, size),
+		low:     low,
+		refill:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go p.fill()
+	return p, nil
+}
+
+// fill generates IDs into the buffer until the pool is closed
+func (p *PooledFlaker) fill() {
+	defer close(p.stopped)
+	batch := make([]Flake, 0, cap(p.ids))
+	for {
+		// Only this goroutine sends, so the free space can only grow meanwhile
+		batch = p.base.AppendNext(batch[:0], cap(p.ids)-len(p.ids))
+		for _, id := range batch {
+			p.ids <- id
+		}
+		select {
+		case <-p.refill:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Next returns a pre-generated ID. It never blocks but generates the ID with
+// the base Flaker when the buffer is empty, e.g. after Close().
+func (p *PooledFlaker) Next() Flake {
+	select {
+	case id := <-p.ids:
+		if len(p.ids) <= p.low {
+			p.wake()
+		}
+		return id
+	default:
+		p.wake()
+		return p.base.Next()
+	}
+}
+
+// wake requests a refill without blocking
+func (p *PooledFlaker) wake() {
+	select {
+	case p.refill <- struct{}{}:
+	default:
+	}
+}
+
+// Len returns the count of buffered IDs
+func (p *PooledFlaker) Len() int {
+	return len(p.ids)
+}
+
+// Close stops the background generation and waits for it to finish. The IDs
+// left in the buffer are still handed out by Next().
+func (p *PooledFlaker) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+	})
+	<-p.stopped
+	return nil
+}
@@ -0,0 +1,78 @@
+package flake
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPooledFlaker(t *testing.T) {
+	base := Raw.WithClock(&manualClock{now: time.Now()})
+	p, err := NewPooled(base, 1000, 100)
+	if err != nil {
+		t.Errorf("Creating pool failed: %v", err)
+		return
+	}
+	for start := time.Now(); p.Len() < 1000 && time.Since(start) < time.Second; {
+		time.Sleep(time.Millisecond)
+	}
+	if n := p.Len(); n != 1000 {
+		t.Errorf("Expected a filled buffer of 1000 IDs but got %d", n)
+	}
+
+	ids := make(chan Flake, 8*10000)
+	w := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		w.Add(1)
+		go func() {
+			defer w.Done()
+			for i := 0; i < 10000; i++ {
+				ids <- p.Next()
+			}
+		}()
+	}
+	w.Wait()
+	_ = p.Close()
+	_ = p.Close()
+	close(ids)
+
+	m := make(map[Flake]int)
+	for id := range ids {
+		if _, ok := m[id]; ok {
+			t.Errorf("doubble with %d", id)
+			return
+		}
+		m[id] = 0
+	}
+	for i := 0; i < 3000; i++ {
+		id := p.Next()
+		if _, ok := m[id]; ok {
+			t.Errorf("doubble after close with %d", id)
+			return
+		}
+		m[id] = i
+	}
+	generate(t, base, m, 1000)
+}
+
+func TestNewPooled(t *testing.T) {
+	for _, c := range []struct{ size, low int }{{0, 0}, {10, 10}, {10, -1}} {
+		if _, err := NewPooled(Default, c.size, c.low); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for size %d and low %d but got %v", c.size, c.low, err)
+		}
+	}
+	if _, err := NewPooled(nil, 10, 1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig without base but got %v", err)
+	}
+}
+
+func BenchmarkPooledParallel(b *testing.B) {
+	p, _ := NewPooled(WithMachineId(1), 4096, 1024)
+	defer p.Close()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.Next()
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package flake
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownPrefix is returned when decoding an ID of an unregistered prefix
+var ErrUnknownPrefix = errors.New("unknown prefix")
+
+// Prefixes maps the display prefixes of IDs to entity kinds, e.g. "usr" of
+// usr_2CvBM5Hs1Aa to the kind of users, so a single endpoint can accept the
+// IDs of all entities and dispatch on their kind. Prefixed IDs are the prefix,
+// an underscore and the base58 flake like the IDs of flake gen. The kinds may
+// be those of NextKind. It's safe for concurrent use.
+type Prefixes struct {
+	mutex    sync.RWMutex
+	kinds    map[string]byte
+	prefixes map[byte]string
+}
+
+// DefaultPrefixes are the prefixes of the RegisterPrefix and ParseAny
+// shorthands
+var DefaultPrefixes = NewPrefixes()
+
+// NewPrefixes returns an empty registry of prefixes
+func NewPrefixes() *Prefixes {
+	return &Prefixes{kinds: make(map[string]byte), prefixes: make(map[byte]string)}
+}
+
+// RegisterPrefix is a shorthand for DefaultPrefixes.Register(prefix, kind)
+func RegisterPrefix(prefix string, kind byte) error {
+	return DefaultPrefixes.Register(prefix, kind)
+}
+
+// ParseAny is a shorthand for DefaultPrefixes.ParseAny(s)
+func ParseAny(s string) (kind byte, f Flake, err error) {
+	return DefaultPrefixes.ParseAny(s)
+}
+
+// Register maps the prefix of lower case letters and digits to the kind. A
+// prefix and a kind can be registered once, it fails with ErrInvalidConfig
+// otherwise.
+func (p *Prefixes) Register(prefix string, kind byte) error {
+	if prefix == "" || strings.IndexFunc(prefix, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}) >= 0 {
+		return fmt.Errorf("%w: prefix %q", ErrInvalidConfig, prefix)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if other, ok := p.kinds[prefix]; ok {
+		return fmt.Errorf("%w: prefix %q registered for kind %d", ErrInvalidConfig, prefix, other)
+	} else if other, ok := p.prefixes[kind]; ok {
+		return fmt.Errorf("%w: kind %d registered with prefix %q", ErrInvalidConfig, kind, other)
+	}
+	p.kinds[prefix] = kind
+	p.prefixes[kind] = prefix
+	return nil
+}
+
+// Prefix returns the prefix of the kind
+func (p *Prefixes) Prefix(kind byte) (prefix string, ok bool) {
+	p.mutex.RLock()
+	prefix, ok = p.prefixes[kind]
+	p.mutex.RUnlock()
+	return
+}
+
+// Encode returns the ID of the kind with its prefix. It fails with
+// ErrUnknownPrefix for kinds without a prefix.
+func (p *Prefixes) Encode(kind byte, f Flake) (string, error) {
+	prefix, ok := p.Prefix(kind)
+	if !ok {
+		return "", fmt.Errorf("%w of kind %d", ErrUnknownPrefix, kind)
+	}
+	return string(appendBase58(append([]byte(prefix), '_'), f)), nil
+}
+
+// ParseAny decodes a prefixed ID of any registered prefix and returns its
+// kind. Errors are of type *DecodeError wrapping ErrUnknownPrefix for IDs of
+// other prefixes.
+func (p *Prefixes) ParseAny(s string) (kind byte, f Flake, err error) {
+	prefix, encoded, ok := strings.Cut(s, "_")
+	if !ok {
+		return 0, 0, &DecodeError{Input: s, Format: FormatBase58, Err: ErrUnknownPrefix}
+	}
+	p.mutex.RLock()
+	kind, ok = p.kinds[prefix]
+	p.mutex.RUnlock()
+	if !ok {
+		return 0, 0, &DecodeError{Input: s, Format: FormatBase58, Err: ErrUnknownPrefix}
+	}
+	if f, err = DecodeFormat(encoded, FormatBase58); err != nil {
+		return 0, 0, &DecodeError{Input: s, Format: FormatBase58, Err: err.(*DecodeError).Err}
+	}
+	return kind, f, nil
+}
@@ -0,0 +1,62 @@
+package flake
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	kindUser byte = iota + 1
+	kindInvoice
+)
+
+func TestPrefixes(t *testing.T) {
+	p := NewPrefixes()
+	if err := p.Register("usr", kindUser); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Register("inv", kindInvoice); err != nil {
+		t.Fatal(err)
+	}
+
+	id := Next()
+	s, err := p.Encode(kindInvoice, id)
+	if err != nil || s != "inv_"+id.Base58() {
+		t.Errorf("Expected inv_%s but got %s, %v", id.Base58(), s, err)
+	}
+	kind, f, err := p.ParseAny(s)
+	if err != nil || kind != kindInvoice || f != id {
+		t.Errorf("Expected invoice %d of %s but got kind %d, %d, %v", id, s, kind, f, err)
+	}
+	if kind, f, err := p.ParseAny("usr_111112t6V2H"); err != nil || kind != kindUser || f != 1234567890 {
+		t.Errorf("Expected user 1234567890 but got kind %d, %d, %v", kind, f, err)
+	}
+	if prefix, ok := p.Prefix(kindUser); !ok || prefix != "usr" {
+		t.Errorf("Expected prefix usr but got %q", prefix)
+	}
+	if _, err := p.Encode(7, id); !errors.Is(err, ErrUnknownPrefix) {
+		t.Errorf("Expected an unknown prefix of kind 7 but got %v", err)
+	}
+
+	for s, expected := range map[string]error{
+		"ord_111112t6V2H": ErrUnknownPrefix,
+		"111112t6V2H":     ErrUnknownPrefix,
+		"usr_111112t6V20": ErrInvalidEncoding,
+		"usr_":            ErrInvalidLength,
+		"usr_usr_112t6V2": ErrInvalidEncoding,
+	} {
+		var de *DecodeError
+		if _, _, err := p.ParseAny(s); !errors.As(err, &de) || de.Input != s || !errors.Is(err, expected) {
+			t.Errorf("Expected %v decoding %q but got %v", expected, s, err)
+		}
+	}
+
+	for _, args := range []struct {
+		prefix string
+		kind   byte
+	}{{"", 3}, {"Usr", 3}, {"us_r", 3}, {"usr", 3}, {"ord", kindUser}} {
+		if err := p.Register(args.prefix, args.kind); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected registering %q as %d to fail but got %v", args.prefix, args.kind, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package flake
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Preflighter is implemented by the flakers of this package. It's an optional
+// interface of Flaker, so other implementations don't have to provide it.
+type Preflighter interface {
+	Preflight() error
+}
+
+// Preflight is a shorthand for Default.Preflight(). It's nil if Default isn't
+// a Preflighter.
+func Preflight() error {
+	if p, ok := getDefault().(Preflighter); ok {
+		return p.Preflight()
+	}
+	return nil
+}
+
+// Preflight validates the effective configuration at startup to fail fast
+// instead of generating colliding IDs. It reports all problems joined:
+//   - ErrInvalidConfig when the machine-id derived from the local IPv4
+//     address fell back to zero since no private address was found
+//   - ErrEpochOverflow when the epoch starts in the future or has ended
+//   - ErrEntropy when reading random bytes fails, entropy of WithEntropy
+//     isn't read
+//   - ErrStateSave when the state store fails to load or save the state
+//
+// To probe the store it saves the later of the loaded and the current state
+// of the generator while holding the lock of the generator's saves, so it
+// never writes an older state back and may run while IDs are generated.
+func (g *flaker) Preflight() error {
+	var errs []error
+	if g.derived && getLocalIPv4() == 0 {
+		errs = append(errs, fmt.Errorf("%w: machine-id 0 derived without a private IPv4 address, set it explicitly", ErrInvalidConfig))
+	}
+	now := g.now()
+	if now < g.epochStart {
+		errs = append(errs, fmt.Errorf("%w: epoch start %s is in the future", ErrEpochOverflow, g.EpochStart().UTC()))
+	} else if end := g.epochStart + int64(g.layout.EpochLength()); now >= end {
+		errs = append(errs, fmt.Errorf("%w: epoch ended at %s", ErrEpochOverflow, time.Unix(0, end).UTC()))
+	}
+	if _, err := g.checkEntropy(); err != nil {
+		errs = append(errs, err)
+	}
+	if g.store != nil {
+		if err := g.probeStore(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// probeStore loads the state and saves it again, or the current state of the
+// generator if that's later. The mutex serializes it with persist.
+func (g *flaker) probeStore() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	state, err := g.store.Load()
+	if err != nil {
+		return fmt.Errorf("%w: loading: %v", ErrStateSave, err)
+	}
+	l := &g.layout
+	interval, counter := unpack(atomic.LoadUint64(&g.state))
+	reached := interval + l.borrowed(counter)
+	if reached > state.Interval+l.borrowed(state.Sequence) {
+		state = State{Interval: interval, Sequence: counter}
+	}
+	if err := g.store.Save(state); err != nil {
+		return fmt.Errorf("%w: %v", ErrStateSave, err)
+	}
+	if reached > atomic.LoadInt64(&g.stored) {
+		atomic.StoreInt64(&g.stored, reached)
+	}
+	return nil
+}
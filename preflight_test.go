@@ -0,0 +1,105 @@
+package flake
+
+import (
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// preflight runs the Preflight of a flaker of this package
+func preflight(f Flaker) error {
+	return f.(Preflighter).Preflight()
+}
+
+func TestPreflight(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f, err := New(SetClock(clock), SetMachineId(1), SetStateStore(FileStore(filepath.Join(t.TempDir(), "state"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := preflight(f); err != nil {
+		t.Errorf("Expected no preflight error but got %v", err)
+	}
+
+	derived, err := New(SetClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := preflight(derived); getLocalIPv4() == 0 && !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected an unresolved machine-id but got %v", err)
+	} else if getLocalIPv4() != 0 && err != nil {
+		t.Errorf("Expected a resolved machine-id but got %v", err)
+	}
+	if err := preflight(derived.WithMachineId(0)); err != nil {
+		t.Errorf("Expected no error with an explicit machine-id but got %v", err)
+	}
+
+	future := f.WithEpochStart(clock.now.Add(time.Hour))
+	if err := preflight(future); !errors.Is(err, ErrEpochOverflow) {
+		t.Errorf("Expected an epoch start in the future but got %v", err)
+	}
+	ended := f.WithEpochStart(clock.now.Add(-DefaultLayout.EpochLength() - time.Hour))
+	if err := preflight(ended); !errors.Is(err, ErrEpochOverflow) {
+		t.Errorf("Expected an ended epoch but got %v", err)
+	}
+
+	failing, err := f.WithStateStore(failingStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	randReader = failingReader{}
+	err = preflight(failing)
+	randReader = rand.Reader
+	if !errors.Is(err, ErrEntropy) || !errors.Is(err, ErrStateSave) {
+		t.Errorf("Expected joined entropy and state errors but got %v", err)
+	}
+}
+
+// memoryStore keeps the state in memory
+type memoryStore struct {
+	state State
+	saves int
+}
+
+func (s *memoryStore) Load() (State, error) {
+	return s.state, nil
+}
+
+func (s *memoryStore) Save(state State) error {
+	s.state = state
+	s.saves++
+	return nil
+}
+
+func TestPreflightKeepsLaterState(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	store := &memoryStore{}
+	f, err := New(SetClock(clock), SetMachineId(1), SetStateStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.NextN(10)
+	generated := store.state
+
+	// The current state of the generator is saved if it's later
+	store.state = State{}
+	if err := preflight(f); err != nil || store.state != generated {
+		t.Errorf("Expected the current state %+v saved but got %+v: %v", generated, store.state, err)
+	}
+
+	// A later state isn't overwritten by the older current state
+	later := State{Interval: generated.Interval + 100, Sequence: 5}
+	store.state = later
+	if err := preflight(f); err != nil || store.state != later {
+		t.Errorf("Expected the later state %+v kept but got %+v: %v", later, store.state, err)
+	}
+}
+
+func TestPreflightDefault(t *testing.T) {
+	defer SetDefault(SetDefault(struct{ Flaker }{Default}))
+	if err := Preflight(); err != nil {
+		t.Errorf("Expected nothing to check of a Default without Preflighter but got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Package promflake exposes the Metrics of flake generators as Prometheus
+// metrics labeled by machine-id:
+//
+//	prometheus.MustRegister(promflake.NewCollector(flaker))
+package promflake
+
+import (
+	"strconv"
+
+	"go-flake"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace is the prefix of the metric names
+const Namespace = "flake"
+
+var (
+	idsDesc = prometheus.NewDesc(Namespace+"_ids_generated_total",
+		"Count of generated IDs.", []string{"machine_id"}, nil)
+	utilizationDesc = prometheus.NewDesc(Namespace+"_sequence_utilization_ratio",
+		"Share of the sequence capacity of the current interval in use, above 1 while borrowing future intervals.", []string{"machine_id"}, nil)
+	exhaustedDesc = prometheus.NewDesc(Namespace+"_sequence_exhausted_total",
+		"Count of exhausted sequences borrowing future intervals, spinning or failing.", []string{"machine_id"}, nil)
+	regressionsDesc = prometheus.NewDesc(Namespace+"_clock_regressions_total",
+		"Count of detected clock regressions.", []string{"machine_id"}, nil)
+	entropyReadsDesc = prometheus.NewDesc(Namespace+"_entropy_reads_total",
+		"Count of random reads.", []string{"machine_id"}, nil)
+	entropyErrorsDesc = prometheus.NewDesc(Namespace+"_entropy_errors_total",
+		"Count of failed random reads.", []string{"machine_id"}, nil)
+	lockWaitDesc = prometheus.NewDesc(Namespace+"_lock_wait_seconds_total",
+		"Time spent waiting for the lock serializing state saves.", []string{"machine_id"}, nil)
+)
+
+// Collector is a prometheus.Collector of the Metrics of generators
+type Collector struct {
+	flakers []flake.Flaker
+}
+
+// NewCollector returns a Collector of the generators, which must have
+// distinct machine-ids. Without generators it collects flake.Default. It skips
+// generators which aren't a flake.MetricsReporter.
+func NewCollector(flakers ...flake.Flaker) *Collector {
+	return &Collector{flakers: flakers}
+}
+
+// Describe sends the descriptors of the metrics
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{idsDesc, utilizationDesc, exhaustedDesc, regressionsDesc, entropyReadsDesc, entropyErrorsDesc, lockWaitDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the metrics of the generators
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	flakers := c.flakers
+	if len(flakers) == 0 {
+		flakers = []flake.Flaker{flake.Default}
+	}
+	for _, flaker := range flakers {
+		r, ok := flaker.(flake.MetricsReporter)
+		if !ok {
+			continue
+		}
+		m := r.Metrics()
+		machineId := strconv.Itoa(int(flaker.MachineId()))
+		ch <- prometheus.MustNewConstMetric(idsDesc, prometheus.CounterValue, float64(m.IDs), machineId)
+		ch <- prometheus.MustNewConstMetric(utilizationDesc, prometheus.GaugeValue, m.Utilization, machineId)
+		ch <- prometheus.MustNewConstMetric(exhaustedDesc, prometheus.CounterValue, float64(m.Exhausted), machineId)
+		ch <- prometheus.MustNewConstMetric(regressionsDesc, prometheus.CounterValue, float64(m.ClockRegressions), machineId)
+		ch <- prometheus.MustNewConstMetric(entropyReadsDesc, prometheus.CounterValue, float64(m.EntropyReads), machineId)
+		ch <- prometheus.MustNewConstMetric(entropyErrorsDesc, prometheus.CounterValue, float64(m.EntropyErrors), machineId)
+		ch <- prometheus.MustNewConstMetric(lockWaitDesc, prometheus.CounterValue, m.LockWait.Seconds(), machineId)
+	}
+}
@@ -0,0 +1,36 @@
+package promflake
+
+import (
+	"strings"
+	"testing"
+
+	"go-flake"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	a, _ := flake.New(flake.SetMachineId(1))
+	b, _ := flake.New(flake.SetMachineId(2))
+	a.NextN(10)
+	b.Next()
+	registry := prometheus.NewPedanticRegistry()
+	// Generators without Metrics are skipped
+	registry.MustRegister(NewCollector(a, b, struct{ flake.Flaker }{a}))
+
+	expected := `
+# HELP flake_ids_generated_total Count of generated IDs.
+# TYPE flake_ids_generated_total counter
+flake_ids_generated_total{machine_id="1"} 10
+flake_ids_generated_total{machine_id="2"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "flake_ids_generated_total"); err != nil {
+		t.Error(err)
+	}
+	if count, err := testutil.GatherAndCount(registry); err != nil || count != 14 {
+		t.Errorf("Expected 14 metrics but got %d: %v", count, err)
+	}
+	if problems, err := testutil.CollectAndLint(NewCollector()); err != nil || len(problems) > 0 {
+		t.Errorf("Expected no lint problems but got %v: %v", problems, err)
+	}
+}
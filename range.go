@@ -0,0 +1,110 @@
+package flake
+
+import (
+	"fmt"
+	"time"
+)
+
+// Range is the range of IDs from From to To, both inclusive, e.g. to
+// partition backfill jobs or to express retention windows of flake keyed
+// data. It's empty if From is greater than To. Ranges of raw IDs are time
+// ranges, see RangeForTime.
+type Range struct {
+	From Flake `json:"from"`
+	To   Flake `json:"to"`
+}
+
+// RangeForTime returns the range of the raw IDs of the flaker created from
+// the interval of from to the one of to, both inclusive
+func RangeForTime(flaker Flaker, from, to time.Time) (Range, error) {
+	start, err := MinForTime(flaker, from)
+	if err != nil {
+		return Range{}, fmt.Errorf("range start: %w", err)
+	}
+	end, err := MaxForTime(flaker, to)
+	if err != nil {
+		return Range{}, fmt.Errorf("range end: %w", err)
+	}
+	return Range{From: start, To: end}, nil
+}
+
+// String returns the range as [From, To]
+func (r Range) String() string {
+	return fmt.Sprintf("[%d, %d]", r.From, r.To)
+}
+
+// IsEmpty reports whether the range contains no IDs
+func (r Range) IsEmpty() bool {
+	return r.From > r.To
+}
+
+// Len returns the count of IDs in the range
+func (r Range) Len() uint64 {
+	if r.IsEmpty() {
+		return 0
+	}
+	return uint64(r.To-r.From) + 1
+}
+
+// Contains reports whether the flake is within the range. A Range is
+// Reserved like a ReservedRange.
+func (r Range) Contains(f Flake) bool {
+	return f >= r.From && f <= r.To
+}
+
+// Overlaps reports whether the ranges have IDs in common
+func (r Range) Overlaps(other Range) bool {
+	return !r.IsEmpty() && !other.IsEmpty() && r.From <= other.To && other.From <= r.To
+}
+
+// Split splits the range into n consecutive ranges of equal length up to one,
+// fewer if it has less than n IDs
+func (r Range) Split(n int) []Range {
+	length := r.Len()
+	if n < 1 || length == 0 {
+		return nil
+	}
+	if uint64(n) > length {
+		n = int(length)
+	}
+	size, rest := length/uint64(n), length%uint64(n)
+	ranges := make([]Range, n)
+	from := r.From
+	for i := range ranges {
+		l := size
+		if uint64(i) < rest {
+			l++
+		}
+		ranges[i] = Range{From: from, To: from + Flake(l-1)}
+		from += Flake(l)
+	}
+	return ranges
+}
+
+// Chunks calls yield with consecutive ranges of size IDs covering the range,
+// the last one possibly shorter, until yield returns false
+func (r Range) Chunks(size uint64, yield func(chunk Range) bool) {
+	if size == 0 {
+		return
+	}
+	for from := r.From; from <= r.To; {
+		to := r.To
+		if uint64(r.To-from) >= size {
+			to = from + Flake(size-1)
+		}
+		if !yield(Range{From: from, To: to}) || to == r.To {
+			return
+		}
+		from = to + 1
+	}
+}
+
+// All calls yield with the IDs of the range in ascending order until yield
+// returns false
+func (r Range) All(yield func(f Flake) bool) {
+	for f := r.From; f <= r.To; f++ {
+		if !yield(f) || f == r.To {
+			return
+		}
+	}
+}
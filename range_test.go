@@ -0,0 +1,80 @@
+package flake
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRange(t *testing.T) {
+	r := Range{From: 10, To: 19}
+	if r.Len() != 10 || r.IsEmpty() || !r.Contains(10) || !r.Contains(19) || r.Contains(20) || r.String() != "[10, 19]" {
+		t.Errorf("Expected 10 IDs in %s", r)
+	}
+	if empty := (Range{From: 2, To: 1}); !empty.IsEmpty() || empty.Len() != 0 || empty.Split(2) != nil || empty.Overlaps(r) {
+		t.Errorf("Expected %s to be empty", empty)
+	}
+	for other, overlaps := range map[Range]bool{{0, 9}: false, {0, 10}: true, {12, 14}: true, {19, 30}: true, {20, 30}: false} {
+		if r.Overlaps(other) != overlaps || other.Overlaps(r) != overlaps {
+			t.Errorf("Expected %s overlapping %s to be %t", r, other, overlaps)
+		}
+	}
+
+	if split := r.Split(3); !reflect.DeepEqual(split, []Range{{10, 13}, {14, 16}, {17, 19}}) {
+		t.Errorf("Expected 3 ranges of %s but got %v", r, split)
+	}
+	if split := (Range{From: 1, To: 2}).Split(5); len(split) != 2 {
+		t.Errorf("Expected 2 ranges of single IDs but got %v", split)
+	}
+	full := Range{From: 0, To: MaxFlake}
+	if split := full.Split(4); len(split) != 4 || split[0].From != 0 || split[3].To != MaxFlake || split[1].From != split[0].To+1 {
+		t.Errorf("Expected 4 consecutive ranges of all IDs but got %v", split)
+	}
+
+	var chunks []Range
+	r.Chunks(4, func(chunk Range) bool {
+		chunks = append(chunks, chunk)
+		return true
+	})
+	if !reflect.DeepEqual(chunks, []Range{{10, 13}, {14, 17}, {18, 19}}) {
+		t.Errorf("Expected chunks of 4 IDs but got %v", chunks)
+	}
+	var ids []Flake
+	Range{From: MaxFlake - 2, To: MaxFlake}.All(func(f Flake) bool {
+		ids = append(ids, f)
+		return true
+	})
+	if !reflect.DeepEqual(ids, []Flake{MaxFlake - 2, MaxFlake - 1, MaxFlake}) {
+		t.Errorf("Expected the last 3 IDs but got %v", ids)
+	}
+	ids = nil
+	r.All(func(f Flake) bool {
+		ids = append(ids, f)
+		return len(ids) < 2
+	})
+	if len(ids) != 2 {
+		t.Errorf("Expected to stop after 2 IDs but got %v", ids)
+	}
+}
+
+func TestRangeForTime(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f, err := New(SetClock(clock), SetMode(ModeRaw), SetMachineId(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := RangeForTime(f, clock.now.Add(-time.Hour), clock.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := f.Next(); !r.Contains(id) {
+		t.Errorf("Expected %d in %s", id, r)
+	}
+	old, _ := f.GenerateAt(clock.now.Add(-2*time.Hour), 0)
+	if r.Contains(old) {
+		t.Errorf("Expected %d outside of %s", old, r)
+	}
+	if _, err := RangeForTime(f, time.Unix(0, 0), clock.now); err == nil {
+		t.Errorf("Expected an epoch overflow before the epoch")
+	}
+}
@@ -0,0 +1,11 @@
+package flake
+
+// hasRDRAND reports whether the CPU supports the RDRAND instruction
+var hasRDRAND = cpuid1ECX()&(1<<30) != 0
+
+// cpuid1ECX returns the ECX register of the CPUID leaf 1
+func cpuid1ECX() uint32
+
+// rdrand returns 8 random bytes of the RDRAND instruction, ok is false when
+// the CPU had none available
+func rdrand() (r uint64, ok bool)
@@ -0,0 +1,16 @@
+#include "textflag.h"
+
+// func cpuid1ECX() uint32
+TEXT ·cpuid1ECX(SB), NOSPLIT, $0-4
+	MOVL $1, AX
+	XORL CX, CX
+	CPUID
+	MOVL CX, ret+0(FP)
+	RET
+
+// func rdrand() (r uint64, ok bool)
+TEXT ·rdrand(SB), NOSPLIT, $0-9
+	RDRANDQ AX
+	SETCS ok+8(FP)
+	MOVQ AX, r+0(FP)
+	RET
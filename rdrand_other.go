@@ -0,0 +1,11 @@
+//go:build !amd64
+
+package flake
+
+// hasRDRAND reports whether the CPU supports the RDRAND instruction
+const hasRDRAND = false
+
+// rdrand is never called without RDRAND support
+func rdrand() (r uint64, ok bool) {
+	return 0, false
+}
@@ -0,0 +1,74 @@
+package flake
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrReserved is returned when an ID is reserved.
+var ErrReserved = errors.New("reserved flake")
+
+// Reserved declares IDs a generator must never emit, e.g. legacy key ranges
+// being migrated or sentinel values.
+type Reserved interface {
+	// Contains reports whether the flake is reserved
+	Contains(f Flake) bool
+}
+
+// ReservedRange reserves the IDs from From to To, both inclusive. A single
+// sentinel value is the range of From == To.
+type ReservedRange struct {
+	From, To Flake
+}
+
+// Contains reports whether the flake is within the range
+func (r ReservedRange) Contains(f Flake) bool {
+	return f >= r.From && f <= r.To
+}
+
+// ReservedFunc reserves the IDs the predicate is true for
+type ReservedFunc func(f Flake) bool
+
+// Contains reports whether the predicate is true for the flake
+func (r ReservedFunc) Contains(f Flake) bool {
+	return r(f)
+}
+
+// reservations are the reserved IDs of a generator
+type reservations []Reserved
+
+// Contains reports whether any of the reservations contains the flake
+func (r reservations) Contains(f Flake) bool {
+	for _, reserved := range r {
+		if reserved.Contains(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a new Flaker instance copy which never emits the reserved IDs in
+// addition to the ones reserved so far. The reservations apply to the IDs as
+// emitted in the mode of the generator, e.g. shuffled ones. Reserved IDs are
+// skipped transparently by drawing the next ID, so keep the reservations to a
+// small share of the IDs. GenerateAt fails with ErrReserved instead and
+// Validate rejects reserved IDs.
+func (g *flaker) WithReserved(reserved ...Reserved) Flaker {
+	c := g.derive()
+	c.reserved = append(c.reserved[:len(c.reserved):len(c.reserved)], reserved...)
+	return c
+}
+
+// emit returns the next ID in the representation of the mode skipping the
+// reserved ones
+func (g *flaker) emit(ctx context.Context, fallible bool, kind byte) (Flake, error) {
+	for {
+		raw, err := g.next(ctx, fallible, kind)
+		if err != nil {
+			return 0, err
+		}
+		if id := g.format(raw); !g.reserved.Contains(id) {
+			return id, nil
+		}
+	}
+}
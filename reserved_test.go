@@ -0,0 +1,50 @@
+package flake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReservedRange(t *testing.T) {
+	r := ReservedRange{From: 10, To: 20}
+	for f, expected := range map[Flake]bool{9: false, 10: true, 15: true, 20: true, 21: false} {
+		if r.Contains(f) != expected {
+			t.Errorf("Expected Contains(%d) to be %v", f, expected)
+		}
+	}
+	if odd := ReservedFunc(func(f Flake) bool { return f&1 != 0 }); !odd.Contains(3) || odd.Contains(4) {
+		t.Errorf("Expected the predicate to reserve the odd IDs")
+	}
+}
+
+func TestWithReserved(t *testing.T) {
+	odd := ReservedFunc(func(f Flake) bool { return f&1 != 0 })
+	bit8 := ReservedFunc(func(f Flake) bool { return f&0x100 != 0 })
+	f := WithReserved(odd)
+	g := f.WithReserved(bit8)
+	ids := append(g.NextN(1000), g.Next())
+	if id, err := g.NextErr(); err == nil {
+		ids = append(ids, id)
+	}
+	raw, shuffled := g.NextPair()
+	for _, id := range append(ids, raw, shuffled) {
+		if id&0x101 != 0 {
+			t.Errorf("Expected no reserved IDs but got %x", id)
+		}
+	}
+	if len(ids) != 1002 {
+		t.Errorf("Expected 1002 IDs but got %d", len(ids))
+	}
+	if f.(*flaker).reserved.Contains(0x100) {
+		t.Errorf("Expected the reservations of the parent to stay unchanged")
+	}
+	if err := g.Validate(ids[0] | 1); !errors.Is(err, ErrReserved) || !errors.Is(err, ErrInvalidFlake) {
+		t.Errorf("Expected a reserved ID to be invalid but got %v", err)
+	}
+
+	h, _ := New(SetMode(ModeRaw), SetMachineId(1), SetReserved(ReservedRange{From: 0, To: MaxFlake}))
+	if _, err := h.GenerateAt(time.Now().Add(-time.Hour), 0); !errors.Is(err, ErrReserved) {
+		t.Errorf("Expected ErrReserved but got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package flake
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Scan reads a flake of a text stream for fmt.Sscan and friends. The verbs %v
+// and %s accept any encoding like Set, %d a decimal and %x a hex number of up
+// to 16 digits like Format prints them. At
+// the end of the input fmt reports io.ErrUnexpectedEOF as for all Scanners.
+func (f *Flake) Scan(state fmt.ScanState, verb rune) error {
+	token, err := state.Token(true, isEncodingRune)
+	if err != nil {
+		return err
+	} else if len(token) == 0 {
+		return io.EOF
+	}
+	s := string(token)
+	var decoded Flake
+	switch verb {
+	case 'v', 's':
+		return f.Set(s)
+	case 'd':
+		decoded, err = DecodeDecimal(s)
+	case 'x':
+		decoded, err = scanHex(s)
+	default:
+		return fmt.Errorf("%w: scanning flake with %%%c", ErrInvalidEncoding, verb)
+	}
+	if err != nil {
+		return err
+	}
+	*f = decoded
+	return nil
+}
+
+// scanHex parses a hex number of the 63 bit range without padding
+func scanHex(s string) (Flake, error) {
+	n, err := strconv.ParseUint(s, 16, 63)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, &DecodeError{Input: s, Format: FormatHex, Err: ErrOutOfRange}
+	} else if err != nil {
+		return 0, &DecodeError{Input: s, Format: FormatHex, Err: ErrInvalidEncoding}
+	}
+	return Flake(n), nil
+}
+
+// isEncodingRune reports whether r may be part of an encoded flake
+func isEncodingRune(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-' || r == '_'
+}
@@ -0,0 +1,82 @@
+package flake
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	ids := NextN(4)
+	input := fmt.Sprintf("%s %s\n%s,%s", ids[0].Hex(), ids[1].Base32(), ids[2].Base64(), ids[3].Decimal())
+	var a, b, c, d Flake
+	if n, err := fmt.Sscanf(input, "%v %s\n%v,%v", &a, &b, &c, &d); err != nil || n != 4 {
+		t.Fatalf("Expected 4 flakes but got %d: %v", n, err)
+	}
+	if scanned := []Flake{a, b, c, d}; fmt.Sprint(scanned) != fmt.Sprint(ids) {
+		t.Errorf("Expected %v but got %v", ids, scanned)
+	}
+
+	var decimal, hex Flake
+	if _, err := fmt.Sscanf(ids[0].Decimal()+" "+ids[1].Hex(), "%d %x", &decimal, &hex); err != nil || decimal != ids[0] || hex != ids[1] {
+		t.Errorf("Expected %d and %d but got %d and %d: %v", ids[0], ids[1], decimal, hex, err)
+	}
+
+	r := strings.NewReader(strings.Join(EncodeAll(ids, FormatBase32), "\n"))
+	var scanned []Flake
+	for {
+		var f Flake
+		if _, err := fmt.Fscanln(r, &f); errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		scanned = append(scanned, f)
+	}
+	if fmt.Sprint(scanned) != fmt.Sprint(ids) {
+		t.Errorf("Expected %v of the lines but got %v", ids, scanned)
+	}
+
+	var f Flake
+	var de *DecodeError
+	if _, err := fmt.Sscan("invalid", &f); !errors.As(err, &de) {
+		t.Errorf("Expected a decode error but got %v", err)
+	}
+	if _, err := fmt.Sscanf(ids[0].Hex(), "%d", &f); !errors.As(err, &de) {
+		t.Errorf("Expected a decode error of hex as decimal but got %v", err)
+	}
+	if _, err := fmt.Sscanf("123", "%q", &f); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("Expected an invalid verb but got %v", err)
+	}
+	if _, err := fmt.Sscan("", &f); err == nil {
+		t.Error("Expected an error of empty input")
+	}
+}
+
+func TestScanDecimalLengths(t *testing.T) {
+	// Decimals of the lengths of base64, base32 and hex
+	for _, expected := range []Flake{12345678901, 1234567890123, 1234567890123456} {
+		var f Flake
+		if _, err := fmt.Sscan(expected.Decimal(), &f); err != nil || f != expected {
+			t.Errorf("Expected the decimal %d but got %d: %v", expected, f, err)
+		}
+	}
+}
+
+func TestScanHexRoundTrip(t *testing.T) {
+	for _, expected := range []Flake{0, 1, 255, 0x1234, Next(), MaxFlake} {
+		var f Flake
+		if _, err := fmt.Sscanf(fmt.Sprintf("%x", expected), "%x", &f); err != nil || f != expected {
+			t.Errorf("Expected the hex %x but got %x: %v", expected, f, err)
+		}
+	}
+	var f Flake
+	if _, err := fmt.Sscanf("8000000000000000", "%x", &f); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Expected a hex flake out of range but got %v", err)
+	}
+	if _, err := fmt.Sscanf("fg", "%x", &f); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("Expected an invalid hex flake but got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package flake
+
+import (
+	"errors"
+	"time"
+)
+
+// SequencePolicy defines how a Flaker reacts when the sequence of the current
+// interval is exhausted after generating more than 4,000,000 IDs.
+type SequencePolicy int
+
+const (
+	// SequenceBorrow continues the sequence in the following intervals ahead
+	// of the clock. Restarts require a cool down time until the clock caught
+	// up unless the state is persisted. This is the default policy.
+	SequenceBorrow SequencePolicy = iota
+	// SequenceSpin blocks until the clock reaches the following interval.
+	SequenceSpin
+	// SequenceError lets NextErr() fail with ErrSequenceExhausted. Next() falls
+	// back to SequenceBorrow since it never fails.
+	SequenceError
+)
+
+// ErrSequenceExhausted is returned by NextErr() with the SequenceError policy
+// when the sequence of the current interval is exhausted.
+var ErrSequenceExhausted = errors.New("sequence exhausted")
+
+// String returns the name of the policy
+func (p SequencePolicy) String() string {
+	switch p {
+	case SequenceBorrow:
+		return "borrow"
+	case SequenceSpin:
+		return "spin"
+	case SequenceError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the name of the sequence policy
+func (p SequencePolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText sets the sequence policy of the given name
+func (p *SequencePolicy) UnmarshalText(text []byte) error {
+	return unmarshalName(p, text, SequenceError, "sequence policy")
+}
+
+// Returns a new Flaker instance copy with the specified sequence policy set.
+// The policy defines how the generator reacts when the sequence of the current
+// interval is exhausted. The optional onExhausted callback is invoked with the
+// time the next ID is ahead of the clock on each occurrence, with the
+// SequenceBorrow policy once for each borrowed interval.
+func (g *flaker) WithSequencePolicy(policy SequencePolicy, onExhausted func(ahead time.Duration)) Flaker {
+	c := g.derive()
+	c.sequencePolicy = policy
+	c.onExhausted = onExhausted
+	return c
+}
@@ -0,0 +1,86 @@
+package flake
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// exhaust moves the sequence of f to the end of the interval of a manual clock
+// which ends after the given duration of less than one interval. The clock
+// stands still, so the outcome doesn't depend on the load of the machine.
+func exhaust(f Flaker, end time.Duration) *flaker {
+	return exhaustWith(f, &manualClock{now: time.Unix(1700000000, 0)}, end)
+}
+
+// exhaustRunning works like exhaust with a clock running with the wall time
+// for the spin policy waiting for the next interval.
+func exhaustRunning(f Flaker, end time.Duration) *flaker {
+	return exhaustWith(f, &runningClock{start: time.Unix(1700000000, 0), since: time.Now()}, end)
+}
+
+func exhaustWith(f Flaker, clock Clock, end time.Duration) *flaker {
+	g := f.WithClock(clock).(*flaker)
+	g.epochStart = clock.Now().UnixNano() - 1<<ignoredTimeBits + int64(end)
+	g.state = pack(0, SequenceCapacity-1)
+	return g
+}
+
+// runningClock starts at a fixed time and runs with the wall time
+type runningClock struct {
+	start, since time.Time
+}
+
+func (c *runningClock) Now() time.Time {
+	return c.start.Add(time.Since(c.since))
+}
+
+func TestSequenceBorrow(t *testing.T) {
+	var exhausted int
+	g := exhaust(WithSequencePolicy(SequenceBorrow, func(ahead time.Duration) {
+		exhausted++
+	}), 500*time.Millisecond)
+	for i := 0; i < 1000; i++ {
+		if _, err := g.NextErr(); err != nil {
+			t.Errorf("Expected no error with borrow policy but got %v", err)
+			return
+		}
+	}
+	if exhausted != 1 {
+		t.Errorf("Expected 1 callback for the borrowed interval but got %d", exhausted)
+	}
+}
+
+func TestSequenceError(t *testing.T) {
+	var ahead time.Duration
+	g := exhaust(WithSequencePolicy(SequenceError, func(a time.Duration) {
+		ahead = a
+	}), 500*time.Millisecond)
+	if _, err := g.NextErr(); err != ErrSequenceExhausted {
+		t.Errorf("Expected ErrSequenceExhausted but got %v", err)
+	}
+	if ahead != 500*time.Millisecond {
+		t.Errorf("Unexpected time ahead of the clock %s", ahead)
+	}
+	if id := g.Next(); id == 0 {
+		t.Errorf("Expected Next() to fall back to borrowing")
+	}
+}
+
+func TestSequenceSpin(t *testing.T) {
+	g := exhaustRunning(WithSequencePolicy(SequenceSpin, nil), 50*time.Millisecond)
+	start := time.Now()
+	if _, err := g.NextErr(); err != nil {
+		t.Errorf("Expected no error with spin policy but got %v", err)
+	}
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Errorf("Expected to wait for the next interval but returned after %s", waited)
+	}
+
+	g = exhaustRunning(WithSequencePolicy(SequenceSpin, nil), 500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.NextContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded while spinning but got %v", err)
+	}
+}
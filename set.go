@@ -0,0 +1,176 @@
+package flake
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// fibonacci is 2^64 divided by the golden ratio to spread the hashes of flakes
+const fibonacci = 0x9e3779b97f4a7c15
+
+// setLoad is the load factor of a FlakeSet in 1/8 before it grows
+const setLoad = 6
+
+// FlakeSet is a set of flakes in an open addressing hash table using 11 to 22
+// bytes per flake instead of the ~40 of a map[Flake]struct{}, e.g. to
+// deduplicate hundreds of millions of IDs in a stream processor. The zero
+// FlakeSet is empty and ready to use. It isn't safe for concurrent use, see
+// SyncFlakeSet.
+type FlakeSet struct {
+	slots  []Flake // Nil marks free slots
+	len    int
+	hasNil bool // Nil is stored outside of the slots
+}
+
+// NewFlakeSet returns a FlakeSet sized for capacity flakes without growing
+func NewFlakeSet(capacity int) *FlakeSet {
+	s := &FlakeSet{}
+	if capacity > 0 {
+		s.slots = make([]Flake, slotsFor(capacity))
+	}
+	return s
+}
+
+// slotsFor returns the power of two count of slots holding n flakes
+func slotsFor(n int) int {
+	slots := 8
+	for slots*setLoad/8 < n {
+		slots <<= 1
+	}
+	return slots
+}
+
+// Add adds the flake and reports whether it was not contained yet
+func (s *FlakeSet) Add(f Flake) bool {
+	if f == Nil {
+		added := !s.hasNil
+		s.hasNil = true
+		return added
+	}
+	if (s.len+1)*8 > len(s.slots)*setLoad {
+		s.grow()
+	}
+	if s.insert(f) {
+		s.len++
+		return true
+	}
+	return false
+}
+
+// Contains reports whether the set contains the flake
+func (s *FlakeSet) Contains(f Flake) bool {
+	if f == Nil {
+		return s.hasNil
+	}
+	if len(s.slots) == 0 {
+		return false
+	}
+	mask := uint64(len(s.slots) - 1)
+	for i := s.hash(f) & mask; ; i = (i + 1) & mask {
+		switch s.slots[i] {
+		case f:
+			return true
+		case Nil:
+			return false
+		}
+	}
+}
+
+// Len returns the count of flakes in the set
+func (s *FlakeSet) Len() int {
+	if s.hasNil {
+		return s.len + 1
+	}
+	return s.len
+}
+
+// hash returns the slot index of the flake before masking
+func (s *FlakeSet) hash(f Flake) uint64 {
+	return (uint64(f) * fibonacci) >> (64 - bits.Len(uint(len(s.slots)-1)))
+}
+
+// insert stores the flake in its slot and reports whether it was free
+func (s *FlakeSet) insert(f Flake) bool {
+	mask := uint64(len(s.slots) - 1)
+	for i := s.hash(f) & mask; ; i = (i + 1) & mask {
+		switch s.slots[i] {
+		case f:
+			return false
+		case Nil:
+			s.slots[i] = f
+			return true
+		}
+	}
+}
+
+// grow doubles the slots and reinserts the flakes
+func (s *FlakeSet) grow() {
+	slots := s.slots
+	s.slots = make([]Flake, max(8, 2*len(slots)))
+	for _, f := range slots {
+		if f != Nil {
+			s.insert(f)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+
+// syncShards is the count of independently locked shards of a SyncFlakeSet
+const syncShards = 64
+
+// SyncFlakeSet is a FlakeSet safe for concurrent use, which locks one of its
+// shards per call to scale with the count of goroutines. The zero
+// SyncFlakeSet is empty and ready to use.
+type SyncFlakeSet struct {
+	shards [syncShards]struct {
+		sync.Mutex
+		set FlakeSet
+		_   [16]byte // pad to a cache line against false sharing
+	}
+}
+
+// NewSyncFlakeSet returns a SyncFlakeSet sized for capacity flakes
+func NewSyncFlakeSet(capacity int) *SyncFlakeSet {
+	s := &SyncFlakeSet{}
+	if capacity > 0 {
+		for i := range s.shards {
+			s.shards[i].set.slots = make([]Flake, slotsFor((capacity+syncShards-1)/syncShards))
+		}
+	}
+	return s
+}
+
+// shard returns the index of the shard of the flake with the SplitMix64
+// finalizer, independent of the hash of the sets of the shards
+func shard(f Flake) int {
+	return int(mix64(uint64(f)) % syncShards)
+}
+
+// Add adds the flake and reports whether it was not contained yet
+func (s *SyncFlakeSet) Add(f Flake) bool {
+	shard := &s.shards[shard(f)]
+	shard.Lock()
+	defer shard.Unlock()
+	return shard.set.Add(f)
+}
+
+// Contains reports whether the set contains the flake
+func (s *SyncFlakeSet) Contains(f Flake) bool {
+	shard := &s.shards[shard(f)]
+	shard.Lock()
+	defer shard.Unlock()
+	return shard.set.Contains(f)
+}
+
+// Len returns the count of flakes in the set
+func (s *SyncFlakeSet) Len() int {
+	n := 0
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.Lock()
+		n += shard.set.Len()
+		shard.Unlock()
+	}
+	return n
+}
@@ -0,0 +1,91 @@
+package flake
+
+import (
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestFlakeSet(t *testing.T) {
+	var s FlakeSet
+	if s.Contains(1) || s.Len() != 0 {
+		t.Errorf("Expected an empty zero set but got %d flakes", s.Len())
+	}
+	ids := Raw.NextN(100000)
+	for _, f := range ids {
+		if !s.Add(f) {
+			t.Fatalf("Expected %d to be added", f)
+		}
+	}
+	for _, f := range ids {
+		if s.Add(f) || !s.Contains(f) {
+			t.Fatalf("Expected %d to be contained", f)
+		}
+	}
+	if s.Contains(ids[0]+1<<8) || s.Contains(MaxFlake) {
+		t.Errorf("Expected other flakes not to be contained")
+	}
+	if !s.Add(Nil) || s.Add(Nil) || !s.Contains(Nil) || s.Len() != len(ids)+1 {
+		t.Errorf("Expected Nil to be added once to %d flakes but got %d", len(ids), s.Len())
+	}
+	if bytes := len(s.slots) * int(unsafe.Sizeof(Nil)) / s.Len(); bytes > 22 {
+		t.Errorf("Expected at most 22 bytes per flake but got %d", bytes)
+	}
+
+	sized := NewFlakeSet(1000)
+	slots := len(sized.slots)
+	for _, f := range ids[:1000] {
+		sized.Add(f)
+	}
+	if len(sized.slots) != slots || sized.Len() != 1000 {
+		t.Errorf("Expected 1000 flakes without growing but got %d in %d slots", sized.Len(), len(sized.slots))
+	}
+}
+
+func TestSyncFlakeSet(t *testing.T) {
+	s := NewSyncFlakeSet(1 << 16)
+	var wg sync.WaitGroup
+	added := make([]int, 8)
+	for i := range added {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each flake is added by two goroutines
+			for f := Flake(1); f <= 10000; f++ {
+				if int(f)%4 == i/2 && s.Add(f<<8|Flake(i/2)) {
+					added[i]++
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	total := 0
+	for _, n := range added {
+		total += n
+	}
+	if total != 10000 || s.Len() != 10000 || !s.Contains(4<<8) || s.Contains(5<<8) {
+		t.Errorf("Expected 10000 distinct flakes but added %d of %d", total, s.Len())
+	}
+}
+
+func BenchmarkFlakeSet(b *testing.B) {
+	ids := Raw.NextN(1 << 20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var s FlakeSet
+		for _, f := range ids {
+			s.Add(f)
+		}
+	}
+}
+
+func BenchmarkFlakeMap(b *testing.B) {
+	ids := Raw.NextN(1 << 20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := make(map[Flake]struct{})
+		for _, f := range ids {
+			m[f] = struct{}{}
+		}
+	}
+}
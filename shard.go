@@ -0,0 +1,26 @@
+package flake
+
+import "math/bits"
+
+// shardBits are the lower bits of a flake below the interval of the
+// DefaultLayout, the sequence with its random bits and the machine-id
+const shardBits = sequenceBits + machineIdBits
+
+// Shard returns the stable shard index in [0, n) of the flake, e.g. to route
+// flake keyed work across n workers. It's derived from the sequence, random
+// and machine-id bits of raw IDs, not from the time, so the IDs of the
+// current second don't all land on a hot shard. The bits are mixed by a hash
+// before the reduction to n:
+//   - IDs of a single machine spread uniformly, its machine-id doesn't pin
+//     them to a shard even if n divides 256
+//   - Sequential IDs of a busy interval spread like random ones
+//   - Shuffled IDs spread as well since their lower bits mix all fields
+//
+// Changing n remaps most IDs. It returns 0 for n < 1.
+func (f Flake) Shard(n int) int {
+	if n < 1 {
+		return 0
+	}
+	hi, _ := bits.Mul64(mix64(uint64(f)&(1<<shardBits-1)), uint64(n))
+	return int(hi)
+}
@@ -0,0 +1,39 @@
+package flake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShard(t *testing.T) {
+	const n = 16
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f, err := New(SetClock(clock), SetMode(ModeRaw), SetMachineId(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sequential IDs of a single interval and machine-id
+	ids := f.NextN(160000)
+	counts := make([]int, n)
+	for _, id := range ids {
+		shard := id.Shard(n)
+		if shard < 0 || shard >= n || id.Shard(n) != shard {
+			t.Fatalf("Expected a stable shard in [0, %d) but got %d", n, shard)
+		}
+		counts[shard]++
+	}
+	for shard, count := range counts {
+		if count < 9000 || count > 11000 {
+			t.Errorf("Expected about 10000 IDs on shard %d but got %d", shard, count)
+		}
+	}
+
+	// The time doesn't change the shard of the lower bits
+	later := ids[0] + 1<<shardBits
+	if later.Shard(n) != ids[0].Shard(n) {
+		t.Errorf("Expected the same shard of IDs differing in time only")
+	}
+	if Flake(1).Shard(0) != 0 || Flake(1).Shard(1) != 0 {
+		t.Errorf("Expected shard 0 without shards or of a single shard")
+	}
+}
@@ -0,0 +1,94 @@
+package flake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ShardedFlaker spreads the generation over shards to avoid the contention of
+// many goroutines on a single generator state. All shards share the machine-id
+// but partition the sequence: shard i of n uses the sequence counters i, i+n,
+// i+2n, ... so each shard can generate 1/n of the sequence capacity per
+// interval. Calls stick to a shard per processor as far as possible.
+//
+// Shards don't borrow the sequence of the following intervals, since the
+// other shards start them independently. A shard exhausting its part of the
+// sequence spins until the clock reaches the next interval like with the
+// SequenceSpin policy, unless NextErr fails with the SequenceError policy.
+type ShardedFlaker struct {
+	shards []*flaker
+	next   uint32
+	pool   sync.Pool
+}
+
+// maxShards limits the count of shards to keep a reasonable sequence capacity
+// per shard
+const maxShards = 1024
+
+// NewSharded returns a ShardedFlaker with n shards configured like base. Don't
+// use base anymore after creating the shards from it. Sharding doesn't support
+// a state store.
+func NewSharded(base Flaker, n int) (*ShardedFlaker, error) {
+	g, ok := base.(*flaker)
+	if !ok || g.shards != 1 {
+		return nil, fmt.Errorf("%w: can't shard %T", ErrInvalidConfig, base)
+	} else if n < 1 || n > maxShards {
+		return nil, fmt.Errorf("%w: %d shards", ErrInvalidConfig, n)
+	} else if g.store != nil {
+		return nil, fmt.Errorf("%w: sharding with a state store", ErrInvalidConfig)
+	}
+
+	s := &ShardedFlaker{shards: make([]*flaker, n, n)}
+	interval, counter := unpack(atomic.LoadUint64(&g.state))
+	// Start all shards within the same borrowed interval of base, so they
+	// reach the next interval at the same time
+	next := counter + 1
+	if l := &g.layout; l.borrowed(next+int32(n)-1) > l.borrowed(next) {
+		next = int32(l.lastCounter(l.borrowed(next))) + 1
+	}
+	for i := range s.shards {
+		shard := g.derive()
+		shard.shard = int32(i)
+		shard.shards = int32(n)
+		// Continue after the counter of base with the first counter of the shard
+		first := next + ((int32(i)-next)%int32(n)+int32(n))%int32(n)
+		shard.state = pack(interval, first-int32(n))
+		s.shards[i] = shard
+	}
+	s.pool.New = func() interface{} {
+		i := int(atomic.AddUint32(&s.next, 1)-1) % n
+		return &i
+	}
+	return s, nil
+}
+
+// Next returns a new unique ID like Flaker.Next() does.
+func (s *ShardedFlaker) Next() Flake {
+	i := s.pool.Get().(*int)
+	f := s.shards[*i].Next()
+	s.pool.Put(i)
+	return f
+}
+
+// NextErr returns a new unique ID like Flaker.NextErr() does.
+func (s *ShardedFlaker) NextErr() (Flake, error) {
+	i := s.pool.Get().(*int)
+	f, err := s.shards[*i].NextErr()
+	s.pool.Put(i)
+	return f, err
+}
+
+// NextContext returns a new unique ID like Flaker.NextContext() does.
+func (s *ShardedFlaker) NextContext(ctx context.Context) (Flake, error) {
+	i := s.pool.Get().(*int)
+	f, err := s.shards[*i].NextContext(ctx)
+	s.pool.Put(i)
+	return f, err
+}
+
+// Shards returns the count of shards
+func (s *ShardedFlaker) Shards() int {
+	return len(s.shards)
+}
@@ -0,0 +1,107 @@
+package flake
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedFlaker(t *testing.T) {
+	clock := &manualClock{now: time.Now()}
+	base := Raw.WithClock(clock)
+	m := make(map[Flake]int)
+	generate(t, base, m, 1000)
+
+	s, err := NewSharded(base, 7)
+	if err != nil {
+		t.Errorf("Creating shards failed: %v", err)
+		return
+	}
+	ids := make(chan Flake, 8*50000)
+	w := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		w.Add(1)
+		go func() {
+			defer w.Done()
+			for i := 0; i < 50000; i++ {
+				ids <- s.Next()
+			}
+		}()
+	}
+	w.Wait()
+	close(ids)
+	for id := range ids {
+		if _, ok := m[id]; ok {
+			t.Errorf("doubble with %d", id)
+			return
+		}
+		m[id] = 0
+	}
+	if _, err := s.NextErr(); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+}
+
+func TestNewSharded(t *testing.T) {
+	if s, err := NewSharded(Default, 4); err != nil || s.Shards() != 4 {
+		t.Errorf("Expected 4 shards but got %v", err)
+	}
+	f, _ := WithStateStore(failingStore{})
+	for _, test := range []struct {
+		base Flaker
+		n    int
+	}{{Default, 0}, {Default, maxShards + 1}, {f, 2}} {
+		if _, err := NewSharded(test.base, test.n); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for %d shards but got %v", test.n, err)
+		}
+	}
+}
+
+func BenchmarkShardedParallel(b *testing.B) {
+	s, _ := NewSharded(WithMachineId(1), 8)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.Next()
+		}
+	})
+}
+
+// zeroReader is an entropy of zero bytes only, so IDs of equal counters equal
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestShardedExhausted(t *testing.T) {
+	// Intervals of ~1ms with 512 IDs each and no randomness to tell apart IDs
+	// of equal counters
+	layout := Layout{IntervalBits: 32, SequenceBits: 27, MachineIdBits: 4, ResolutionBits: 20, RandomBits: 18}
+	clock := &runningClock{start: time.Unix(1700000000, 0), since: time.Now()}
+	base, err := New(SetLayout(layout), SetMode(ModeRaw), SetMachineId(1), SetClock(clock),
+		SetEpochStart(clock.start.Add(-time.Hour)), SetEntropy(zeroReader{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSharded(base, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[Flake]int)
+	// Exhaust the first shard, then continue with all of them
+	for i := 0; i < 5*layout.SequenceCapacity(); i++ {
+		m[s.shards[0].Next()] = 0
+	}
+	for i := 0; i < 5*layout.SequenceCapacity(); i++ {
+		for shard := range s.shards {
+			id := s.shards[shard].Next()
+			if _, ok := m[id]; ok {
+				t.Errorf("Shard %d generated %d again", shard, id)
+				return
+			}
+			m[id] = shard
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package flake
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// permutation is a keyed permutation of the lower 63 bits of a flake applied
+// with a lookup table per byte. The sign bit stays untouched.
+type permutation struct {
+	forward, inverse [8][256]uint64
+}
+
+// newPermutation derives a permutation from the key by a Fisher-Yates shuffle
+// of the bit positions driven by a SHA-256 counter stream of the key.
+func newPermutation(key []byte) *permutation {
+	var stream []byte
+	block := make([]byte, len(key)+8)
+	copy(block, key)
+	next := func() uint64 {
+		if len(stream) < 8 {
+			binary.BigEndian.PutUint64(block[len(key):], binary.BigEndian.Uint64(block[len(key):])+1)
+			sum := sha256.Sum256(block)
+			stream = sum[:]
+		}
+		n := binary.BigEndian.Uint64(stream)
+		stream = stream[8:]
+		return n
+	}
+
+	var to [63]int
+	for i := range to {
+		to[i] = i
+	}
+	for i := len(to) - 1; i > 0; i-- {
+		j := int(next() % uint64(i+1))
+		to[i], to[j] = to[j], to[i]
+	}
+
+	p := &permutation{}
+	for bit, target := range to {
+		for b := 0; b < 256; b++ {
+			if b&(1<<(bit%8)) != 0 {
+				p.forward[bit/8][b] |= 1 << target
+			}
+			if b&(1<<(target%8)) != 0 {
+				p.inverse[target/8][b] |= 1 << bit
+			}
+		}
+	}
+	return p
+}
+
+// codec replaces the shuffle by another reversible mapping of the raw IDs
+type codec interface {
+	encode(raw int64) int64
+	decode(encoded int64) int64
+}
+
+// apply maps each byte of x through the lookup tables
+func apply(tables *[8][256]uint64, x int64) int64 {
+	var y uint64
+	for i := range tables {
+		y |= tables[i][byte(x>>(8*i))]
+	}
+	return int64(y) | x&(-1<<63)
+}
+
+// shuffle returns the shuffled form of the raw ID
+func (g *flaker) shuffle(raw int64) int64 {
+	if g.codec != nil {
+		return g.codec.encode(raw)
+	}
+	if g.permutation != nil {
+		return apply(&g.permutation.forward, raw)
+	}
+	return shuffle(raw)
+}
+
+// unshuffle returns the raw form of the shuffled ID
+func (g *flaker) unshuffle(shuffled int64) int64 {
+	if g.codec != nil {
+		return g.codec.decode(shuffled)
+	}
+	if g.permutation != nil {
+		return apply(&g.permutation.inverse, shuffled)
+	}
+	return shuffle(shuffled)
+}
+
+// Returns a new Flaker instance copy shuffling the bits of its IDs with a
+// permutation derived from the specified key instead of the public bit
+// transposition, so outsiders can't simply unshuffle the IDs to read their
+// time. Keep the key secret and stable; IDs shuffled with another key are
+// different. An empty key restores the public transposition. Note that a bit
+// permutation obscures the IDs but isn't an encryption: many known pairs of
+// raw and shuffled IDs reveal the permutation.
+func (g *flaker) WithShuffleKey(key []byte) Flaker {
+	c := g.derive()
+	c.permutation = nil
+	if len(key) > 0 {
+		c.permutation = newPermutation(key)
+	}
+	return c
+}
+
+// Shuffle returns the shuffled form of a raw flake as generated by this
+// generator in ModeShuffled.
+func (g *flaker) Shuffle(raw Flake) Flake {
+	return Flake(g.shuffle(int64(raw)))
+}
+
+// Unshuffle returns the raw form of a flake shuffled, encrypted or obfuscated
+// by this generator, e.g. to read its time.
+func (g *flaker) Unshuffle(shuffled Flake) Flake {
+	return Flake(g.unshuffle(int64(shuffled)))
+}
@@ -0,0 +1,63 @@
+package flake
+
+import (
+	mathrand "math/rand"
+	"testing"
+)
+
+func TestPermutation(t *testing.T) {
+	p := newPermutation([]byte("secret"))
+	seen := make(map[int64]bool)
+	for bit := uint(0); bit < 63; bit++ {
+		shuffled := apply(&p.forward, 1<<bit)
+		if shuffled <= 0 || shuffled&(shuffled-1) != 0 || seen[shuffled] {
+			t.Errorf("Expected bit %d to map to a distinct single bit but got %x", bit, shuffled)
+		}
+		seen[shuffled] = true
+	}
+	if q := newPermutation([]byte("secret")); *q != *p {
+		t.Errorf("Expected the same permutation for the same key")
+	}
+	if q := newPermutation([]byte("secret2")); q.forward == p.forward {
+		t.Errorf("Expected another permutation for another key")
+	}
+
+	r := mathrand.New(mathrand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		raw := int64(r.Uint64())
+		if apply(&p.inverse, apply(&p.forward, raw)) != raw || (raw < 0) != (apply(&p.forward, raw) < 0) {
+			t.Errorf("Expected the keyed shuffle of %x to be reversible and keep the sign", uint64(raw))
+			return
+		}
+	}
+}
+
+func TestWithShuffleKey(t *testing.T) {
+	f := WithMachineId(5).WithShuffleKey([]byte("secret"))
+	first, second := f.Next(), f.Next()
+	if shuffle(int64(first)) == int64(f.Unshuffle(first)) {
+		t.Errorf("Expected the keyed shuffle to differ from the transposition")
+	}
+	if f.Unshuffle(first)&0xff != 5 || f.Unshuffle(second) <= f.Unshuffle(first) {
+		t.Errorf("Expected sortable raw IDs of machine-id 5")
+	}
+	if f.Shuffle(f.Unshuffle(first)) != first || f.Validate(first, 5) != nil {
+		t.Errorf("Expected the keyed shuffle to be reversible and valid")
+	}
+	if raw, shuffled := f.NextPair(); f.Shuffle(raw) != shuffled {
+		t.Errorf("Expected a pair of the keyed shuffle")
+	}
+	if g := f.WithShuffleKey(nil); g.Unshuffle(first) != Flake(shuffle(int64(first))) {
+		t.Errorf("Expected an empty key to restore the transposition")
+	}
+	if g, _ := New(SetShuffleKey([]byte("secret"))); g.Unshuffle(first) != f.Unshuffle(first) {
+		t.Errorf("Expected the same shuffle for the same key")
+	}
+}
+
+func BenchmarkKeyedShuffle(b *testing.B) {
+	p := newPermutation([]byte("secret"))
+	for i := 0; i < b.N; i++ {
+		shuffled = apply(&p.forward, int64(i))
+	}
+}
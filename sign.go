@@ -0,0 +1,71 @@
+package flake
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidSignature is returned when the signature of a signed flake is
+// missing or doesn't match.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// signatureSize is the length of the truncated HMAC-SHA256 of a flake
+const signatureSize = 16
+
+// Signer signs flakes with an HMAC key into tamper-evident "flake.signature"
+// tokens of the base64 flake and its 128 bit HMAC-SHA256, e.g. for
+// unsubscribe or download links that must not be guessed nor altered without
+// a database lookup. Use a distinct key per purpose so tokens of one purpose
+// aren't accepted by another.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner returns a Signer of the secret key, which should be at least 32
+// random bytes.
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: append([]byte(nil), key...)}
+}
+
+// Sign returns the signed token of the flake
+func (s *Signer) Sign(f Flake) string {
+	var sum [sha256.Size]byte
+	dst := make([]byte, 0, 12+base64.RawURLEncoding.EncodedLen(signatureSize))
+	dst = appendFormat(dst, f, FormatBase64)
+	dst = append(dst, '.')
+	dst = base64.RawURLEncoding.AppendEncode(dst, s.sum(f, sum[:0])[:signatureSize])
+	return string(dst)
+}
+
+// Verify returns the flake of a token created by Sign. Errors are of type
+// *DecodeError with ErrInvalidSignature as cause when the signature doesn't
+// match.
+func (s *Signer) Verify(token string) (Flake, error) {
+	invalid := &DecodeError{Input: token, Format: FormatBase64, Err: ErrInvalidSignature}
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 || len(token)-i-1 != base64.RawURLEncoding.EncodedLen(signatureSize) {
+		return 0, invalid
+	}
+	f, err := DecodeBase64(token[:i])
+	if err != nil {
+		return 0, err
+	}
+	var signature [signatureSize]byte
+	var sum [sha256.Size]byte
+	if _, err := base64.RawURLEncoding.Decode(signature[:], []byte(token[i+1:])); err != nil ||
+		!hmac.Equal(signature[:], s.sum(f, sum[:0])[:signatureSize]) {
+		return 0, invalid
+	}
+	return f, nil
+}
+
+// sum appends the HMAC-SHA256 of the flake to dst
+func (s *Signer) sum(f Flake, dst []byte) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	array := f.Array()
+	mac.Write(array[:])
+	return mac.Sum(dst)
+}
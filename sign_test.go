@@ -0,0 +1,30 @@
+package flake
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSigner(t *testing.T) {
+	s := NewSigner([]byte("0123456789abcdef0123456789abcdef"))
+	id := Next()
+	token := s.Sign(id)
+	if !strings.HasPrefix(token, id.Base64()+".") || len(token) != 11+1+22 {
+		t.Errorf("Unexpected token %q of %s", token, id.Base64())
+	}
+	if f, err := s.Verify(token); err != nil || f != id {
+		t.Errorf("Expected to verify %d but got %d: %v", id, f, err)
+	}
+
+	other := (id + 1).Base64() + token[11:]
+	for _, invalid := range []string{"", id.Base64(), token[:len(token)-1], token + "A", other,
+		NewSigner([]byte("other")).Sign(id), token[:12] + strings.Repeat("_", 22)} {
+		if _, err := s.Verify(invalid); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Expected ErrInvalidSignature for %q but got %v", invalid, err)
+		}
+	}
+	if _, err := s.Verify("#." + token[12:]); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected a decoding error of the flake but got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package flake
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Load is a phase of a load profile: IDs at a constant rate for a duration.
+type Load struct {
+	// Rate is the count of IDs per second and machine-id
+	Rate float64 `json:"rate"`
+	// Duration is the length of the phase
+	Duration time.Duration `json:"duration"`
+}
+
+// SimulationReport is the result of Simulate. The debts are the time spans
+// the generator ran ahead of the clock by borrowing the sequences of future
+// intervals.
+type SimulationReport struct {
+	Layout Layout `json:"layout"`
+	// Duration is the simulated time
+	Duration time.Duration `json:"duration"`
+	// IDs is the count of issued IDs
+	IDs float64 `json:"ids"`
+	// Throughput is the count of IDs per second the sequence holds without
+	// borrowing
+	Throughput float64 `json:"throughput"`
+	// Borrowing reports a load exhausting the sequence of an interval,
+	// BorrowingStart is the time of the first exhaustion.
+	Borrowing      bool          `json:"borrowing"`
+	BorrowingStart time.Duration `json:"borrowingStart"`
+	// MaxDebt is the largest debt, reached at MaxDebtAt
+	MaxDebt   time.Duration `json:"maxDebt"`
+	MaxDebtAt time.Duration `json:"maxDebtAt"`
+	// FinalDebt is the debt at the end of the simulation
+	FinalDebt time.Duration `json:"finalDebt"`
+	// CoolDown is the time to wait before restarting a generator without
+	// state store after a crash at the largest debt, so it doesn't issue IDs
+	// of intervals the previous process already used.
+	CoolDown time.Duration `json:"coolDown"`
+}
+
+// Simulate simulates the consumption of the sequence of the layout by the
+// load profile with the SequenceBorrow policy, interval by interval, to plan
+// the capacity of a layout. The returned errors wrap ErrInvalidConfig.
+func Simulate(layout Layout, profile ...Load) (SimulationReport, error) {
+	if err := layout.Validate(); err != nil {
+		return SimulationReport{}, err
+	}
+	r := SimulationReport{Layout: layout, Throughput: layout.Throughput()}
+	for _, load := range profile {
+		if !(load.Rate >= 0) || math.IsInf(load.Rate, 1) || load.Duration <= 0 {
+			return SimulationReport{}, fmt.Errorf("%w: load of %g IDs/s for %s", ErrInvalidConfig, load.Rate, load.Duration)
+		}
+		r.Duration += load.Duration
+	}
+
+	// Positions of the counters: the interval of position p is p/perInterval.
+	// A new interval starts at the first counter of the sequence, which is
+	// beyond the start of the interval in the default phased sequence.
+	length := layout.IntervalLength()
+	perInterval := float64(int64(1) << layout.SequenceBits)
+	if layout.RandomBits > 0 {
+		perInterval = float64(int64(1) << (layout.SequenceBits - layout.RandomBits))
+	}
+	start := perInterval - float64(layout.SequenceCapacity())
+	position, debt, carry := 0.0, int64(0), 0.0
+	interval, end := int64(0), time.Duration(0)
+	for _, load := range profile {
+		perStep := load.Rate * length.Seconds()
+		for end += load.Duration; time.Duration(interval)*length < end; interval++ {
+			if float64(interval)*perInterval+start > position {
+				position = float64(interval)*perInterval + start
+			}
+			n := math.Floor(perStep + carry)
+			carry += perStep - n
+			position += n
+			r.IDs += n
+			debt = int64(math.Ceil(position/perInterval)) - 1 - interval
+			if debt > 0 && !r.Borrowing {
+				r.Borrowing, r.BorrowingStart = true, time.Duration(interval)*length
+			}
+			if d := time.Duration(debt) * length; d > r.MaxDebt {
+				r.MaxDebt, r.MaxDebtAt = d, time.Duration(interval)*length
+			}
+		}
+	}
+	if debt > 0 {
+		r.FinalDebt = time.Duration(debt) * length
+	}
+	r.CoolDown = r.MaxDebt + length
+	return r, nil
+}
+
+// String returns a summary of the report
+func (r SimulationReport) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "layout %d/%d/%d bits (interval/sequence/machine-id) with %d random bits\n",
+		r.Layout.IntervalBits, r.Layout.SequenceBits, r.Layout.MachineIdBits, r.Layout.RandomBits)
+	fmt.Fprintf(b, "throughput %.6g IDs/s in intervals of %s\n", r.Throughput, r.Layout.IntervalLength())
+	fmt.Fprintf(b, "simulated %s, %.6g IDs\n", r.Duration, r.IDs)
+	if r.Borrowing {
+		fmt.Fprintf(b, "borrowing starts at %s\n", r.BorrowingStart)
+		fmt.Fprintf(b, "max debt %s at %s, final debt %s\n", r.MaxDebt, r.MaxDebtAt, r.FinalDebt)
+	} else {
+		b.WriteString("no borrowing\n")
+	}
+	fmt.Fprintf(b, "cool-down %s before a restart without state store", r.CoolDown)
+	return b.String()
+}
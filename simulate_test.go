@@ -0,0 +1,60 @@
+package flake
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulate(t *testing.T) {
+	length := DefaultLayout.IntervalLength()
+
+	r, err := Simulate(DefaultLayout, Load{Rate: 1000, Duration: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Borrowing || r.MaxDebt != 0 || r.CoolDown != length || r.Duration != time.Minute {
+		t.Errorf("Expected no borrowing at a low rate but got %+v", r)
+	}
+	if ids := 1000 * time.Minute.Seconds(); r.IDs < ids-1000 || r.IDs > ids+1000 {
+		t.Errorf("Expected about %g IDs but got %g", ids, r.IDs)
+	}
+
+	// A burst of 3.5 intervals worth of IDs within one interval borrows 3
+	// intervals, repaid during the quiet phase
+	l := HighEntropyLayout
+	burst := 3.5 * l.Throughput()
+	r, _ = Simulate(l,
+		Load{Rate: 100, Duration: 10 * time.Second},
+		Load{Rate: burst, Duration: length},
+		Load{Rate: 100, Duration: 10 * time.Second})
+	if !r.Borrowing || r.MaxDebt != 3*length || r.FinalDebt != 0 || r.CoolDown != 4*length {
+		t.Errorf("Expected a debt of 3 intervals but got %+v", r)
+	}
+	if r.BorrowingStart < 9*time.Second || r.BorrowingStart > 11*time.Second || r.MaxDebtAt != r.BorrowingStart {
+		t.Errorf("Expected the borrowing to start at the burst but got %s", r.BorrowingStart)
+	}
+	if s := r.String(); !strings.Contains(s, "borrowing starts at") || !strings.Contains(s, "max debt 3.221225472s") {
+		t.Errorf("Unexpected summary %s", s)
+	}
+
+	// Borrowed intervals hold the whole sequence in the default layout
+	r, _ = Simulate(DefaultLayout, Load{Rate: float64(DefaultLayout.SequenceCapacity()+2<<23) / length.Seconds(), Duration: length})
+	if r.MaxDebt != 2*length {
+		t.Errorf("Expected a debt of 2 intervals but got %+v", r)
+	}
+
+	// A sustained overload accumulates debt
+	r, _ = Simulate(HighEntropyLayout, Load{Rate: 2 * HighEntropyLayout.Throughput(), Duration: time.Minute})
+	if r.FinalDebt < 25*time.Second || r.MaxDebt != r.FinalDebt {
+		t.Errorf("Expected a growing debt but got %+v", r)
+	}
+
+	if _, err := Simulate(DefaultLayout, Load{Rate: -1, Duration: time.Second}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig of a negative rate but got %v", err)
+	}
+	if _, err := Simulate(Layout{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig of an invalid layout but got %v", err)
+	}
+}
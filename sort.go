@@ -0,0 +1,47 @@
+package flake
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Sort sorts raw flakes ascending, which is their creation order. Sort the
+// IDs of shuffling generators with SortRaw.
+func Sort(ids []Flake) {
+	slices.Sort(ids)
+}
+
+// SortRaw sorts IDs of the flaker in creation order by their raw form,
+// unshuffling each once in ModeShuffled. IDs of ModeRandom have no order and
+// are sorted by value like those of ModeRaw.
+func SortRaw(flaker Flaker, ids []Flake) {
+	if flaker.Mode() != ModeShuffled {
+		slices.Sort(ids)
+		return
+	}
+	pairs := make([][2]Flake, len(ids))
+	for i, f := range ids {
+		pairs[i] = [2]Flake{flaker.Unshuffle(f), f}
+	}
+	slices.SortFunc(pairs, func(a, b [2]Flake) int {
+		return cmp.Compare(a[0], b[0])
+	})
+	for i, p := range pairs {
+		ids[i] = p[1]
+	}
+}
+
+// Less reports whether the ID a of the flaker was created before b by
+// comparing their raw forms, e.g. for sort.Slice
+func Less(flaker Flaker, a, b Flake) bool {
+	return CompareRaw(flaker, a, b) < 0
+}
+
+// CompareRaw compares the IDs of the flaker by their raw forms, which is -1
+// if a was created before b and +1 if after, e.g. for slices.SortFunc
+func CompareRaw(flaker Flaker, a, b Flake) int {
+	if flaker.Mode() == ModeShuffled {
+		a, b = flaker.Unshuffle(a), flaker.Unshuffle(b)
+	}
+	return cmp.Compare(a, b)
+}
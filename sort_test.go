@@ -0,0 +1,57 @@
+package flake
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+	raw := Raw.NextN(1000)
+	ids := slices.Clone(raw)
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	Sort(ids)
+	if !slices.Equal(ids, raw) {
+		t.Errorf("Expected raw IDs in creation order")
+	}
+
+	f := WithMode(ModeShuffled)
+	created := f.NextN(1000)
+	ids = slices.Clone(created)
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	SortRaw(f, ids)
+	if !slices.Equal(ids, created) {
+		t.Errorf("Expected shuffled IDs in creation order")
+	}
+
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	slices.SortFunc(ids, func(a, b Flake) int { return CompareRaw(f, a, b) })
+	if !slices.Equal(ids, created) {
+		t.Errorf("Expected shuffled IDs in creation order with CompareRaw")
+	}
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	sort.Slice(ids, func(i, j int) bool { return Less(f, ids[i], ids[j]) })
+	if !slices.Equal(ids, created) {
+		t.Errorf("Expected shuffled IDs in creation order with Less")
+	}
+	if !Less(f, created[0], created[1]) || CompareRaw(f, created[1], created[0]) != 1 || CompareRaw(f, created[0], created[0]) != 0 {
+		t.Errorf("Expected %d before %d", created[0], created[1])
+	}
+
+	ids = slices.Clone(raw)
+	slices.Reverse(ids)
+	SortRaw(Raw, ids)
+	if !slices.Equal(ids, raw) {
+		t.Errorf("Expected raw IDs in creation order with SortRaw")
+	}
+
+	// Any Flaker implementation, e.g. a wrapper
+	rand.Shuffle(len(created), func(i, j int) { created[i], created[j] = created[j], created[i] })
+	ids = slices.Clone(created)
+	SortRaw(f, ids)
+	SortRaw(struct{ Flaker }{f}, created)
+	if !slices.Equal(ids, created) {
+		t.Errorf("Expected the IDs of a wrapped flaker in creation order")
+	}
+}
@@ -0,0 +1,96 @@
+package flake
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect is the placeholder style of a SQL database
+type Dialect int
+
+const (
+	// DialectMySQL uses ? placeholders like SQLite
+	DialectMySQL Dialect = iota
+	// DialectPostgres uses numbered $1 placeholders
+	DialectPostgres
+	// DialectSQLServer uses numbered @p1 placeholders
+	DialectSQLServer
+	// DialectOracle uses numbered :1 placeholders
+	DialectOracle
+	// DialectSQLite uses ? placeholders
+	DialectSQLite
+)
+
+// String returns the name of the dialect
+func (d Dialect) String() string {
+	switch d {
+	case DialectMySQL:
+		return "mysql"
+	case DialectPostgres:
+		return "postgres"
+	case DialectSQLServer:
+		return "sqlserver"
+	case DialectOracle:
+		return "oracle"
+	case DialectSQLite:
+		return "sqlite"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText returns the name of the dialect
+func (d Dialect) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText sets the dialect by name
+func (d *Dialect) UnmarshalText(text []byte) error {
+	return unmarshalName(d, text, DialectSQLite, "dialect")
+}
+
+// Placeholder returns the n-th placeholder of a statement counting from 1
+func (d Dialect) Placeholder(n int) string {
+	switch d {
+	case DialectPostgres:
+		return "$" + strconv.Itoa(n)
+	case DialectSQLServer:
+		return "@p" + strconv.Itoa(n)
+	case DialectOracle:
+		return ":" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// TimeRangeSQL returns a parameterized SQL predicate and its args selecting
+// the raw IDs of the column created in [from, to) at the resolution of the
+// intervals of the flaker, e.g. "id >= $1 AND id < $2". A zero time leaves
+// the range open on its side, numbered placeholders start at 1. The column
+// is inserted verbatim and must not come from user input.
+func TimeRangeSQL(flaker Flaker, column string, from, to time.Time, dialect Dialect) (string, []any, error) {
+	var conditions []string
+	var args []any
+	if !from.IsZero() {
+		start, err := MinForTime(flaker, from)
+		if err != nil {
+			return "", nil, fmt.Errorf("range start: %w", err)
+		}
+		args = append(args, start.Int64())
+		conditions = append(conditions, column+" >= "+dialect.Placeholder(len(args)))
+	}
+	if !to.IsZero() {
+		end, err := MinForTime(flaker, to)
+		if err != nil {
+			return "", nil, fmt.Errorf("range end: %w", err)
+		}
+		args = append(args, end.Int64())
+		conditions = append(conditions, column+" < "+dialect.Placeholder(len(args)))
+	}
+	if len(conditions) == 0 {
+		return "1 = 1", nil, nil
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
@@ -0,0 +1,53 @@
+package flake
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeRangeSQL(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f, err := New(SetClock(clock), SetMode(ModeRaw), SetMachineId(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, to := clock.now.Add(-time.Hour), clock.now
+	start, _ := MinForTime(f, from)
+	end, _ := MinForTime(f, to)
+	for _, test := range []struct {
+		dialect  Dialect
+		from, to time.Time
+		sql      string
+		args     []any
+	}{
+		{DialectMySQL, from, to, "id >= ? AND id < ?", []any{start.Int64(), end.Int64()}},
+		{DialectPostgres, from, to, "id >= $1 AND id < $2", []any{start.Int64(), end.Int64()}},
+		{DialectSQLServer, from, to, "id >= @p1 AND id < @p2", []any{start.Int64(), end.Int64()}},
+		{DialectOracle, time.Time{}, to, "id < :1", []any{end.Int64()}},
+		{DialectSQLite, from, time.Time{}, "id >= ?", []any{start.Int64()}},
+		{DialectPostgres, time.Time{}, time.Time{}, "1 = 1", nil},
+	} {
+		sql, args, err := TimeRangeSQL(f, "id", test.from, test.to, test.dialect)
+		if err != nil || sql != test.sql || !reflect.DeepEqual(args, test.args) {
+			t.Errorf("Expected %s %q %v but got %q %v, %v", test.dialect, test.sql, test.args, sql, args, err)
+		}
+	}
+
+	// IDs created at the start are within [from, to), those at the end not
+	if first, _ := f.GenerateAt(from, 0); first < start || first >= end {
+		t.Errorf("Expected %d within [%d, %d)", first, start, end)
+	}
+	if last := f.Next(); last < end {
+		t.Errorf("Expected %d outside of [%d, %d)", last, start, end)
+	}
+	if _, _, err := TimeRangeSQL(f, "id", time.Unix(0, 0), to, DialectMySQL); !errors.Is(err, ErrEpochOverflow) {
+		t.Errorf("Expected an epoch overflow before the epoch but got %v", err)
+	}
+
+	var d Dialect
+	if err := d.UnmarshalText([]byte("postgres")); err != nil || d != DialectPostgres {
+		t.Errorf("Expected postgres but got %v, %v", d, err)
+	}
+}
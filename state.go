@@ -0,0 +1,90 @@
+package flake
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// State is the persistent state of a Flaker: the last reached interval and
+// the sequence counter within.
+type State struct {
+	Interval int64
+	Sequence int32
+}
+
+// StateStore persists the State of a Flaker. Implement it to keep the state
+// in any other storage than a local file.
+type StateStore interface {
+	// Load returns the last saved state or the zero State if none was saved yet.
+	Load() (State, error)
+	// Save replaces the saved state.
+	Save(state State) error
+}
+
+const stateVersion = 1
+
+// ErrInvalidState is returned when loading a malformed state.
+var ErrInvalidState = errors.New("invalid flake state")
+
+// MarshalBinary encodes the state as 13 bytes
+func (s State) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 13, 13)
+	b[0] = stateVersion
+	binary.BigEndian.PutUint64(b[1:], uint64(s.Interval))
+	binary.BigEndian.PutUint32(b[9:], uint32(s.Sequence))
+	return b, nil
+}
+
+// UnmarshalBinary decodes a state encoded with MarshalBinary
+func (s *State) UnmarshalBinary(b []byte) error {
+	if len(b) != 13 || b[0] != stateVersion {
+		return ErrInvalidState
+	}
+	s.Interval = int64(binary.BigEndian.Uint64(b[1:]))
+	s.Sequence = int32(binary.BigEndian.Uint32(b[9:]))
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+// FileStore returns a StateStore keeping the state in the file at path. The
+// file is replaced atomically on each save.
+func FileStore(path string) StateStore {
+	return fileStore(path)
+}
+
+type fileStore string
+
+// Load reads the state file. A missing file results in the zero State.
+func (f fileStore) Load() (state State, err error) {
+	b, err := os.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return State{}, nil
+	} else if err != nil {
+		return
+	}
+	err = state.UnmarshalBinary(b)
+	return
+}
+
+// Save writes the state to a temporary file and renames it to the state file.
+func (f fileStore) Save(state State) error {
+	b, _ := state.MarshalBinary()
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(b); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
@@ -0,0 +1,83 @@
+package flake
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateBinary(t *testing.T) {
+	in := State{Interval: 123456789, Sequence: 0x402020}
+	b, _ := in.MarshalBinary()
+	var out State
+	if err := out.UnmarshalBinary(b); err != nil || out != in {
+		t.Errorf("Decoding of state %v failed with %v: %v", in, out, err)
+	}
+	if err := out.UnmarshalBinary(b[1:]); err != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState but got %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flake.state")
+	store := FileStore(path)
+	if state, err := store.Load(); err != nil || state != (State{}) {
+		t.Errorf("Expected the zero state from a missing file but got %v: %v", state, err)
+	}
+	in := State{Interval: 42, Sequence: 7}
+	if err := store.Save(in); err != nil {
+		t.Errorf("Saving state failed: %v", err)
+	}
+	if out, err := store.Load(); err != nil || out != in {
+		t.Errorf("Expected state %v but got %v: %v", in, out, err)
+	}
+	_ = os.WriteFile(path, []byte("garbage"), 0600)
+	if _, err := WithStateStore(store); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected ErrInvalidState from a corrupted state file but got %v", err)
+	}
+}
+
+func TestWithStateStore(t *testing.T) {
+	store := FileStore(filepath.Join(t.TempDir(), "flake.state"))
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	m := make(map[Flake]int)
+
+	// Restart three times within the same interval without any cool down
+	var last Flake
+	for restart := 0; restart < 3; restart++ {
+		f, err := Raw.WithClock(clock).WithStateStore(store)
+		if err != nil {
+			t.Errorf("Creating flaker failed: %v", err)
+			return
+		}
+		if first := f.Next(); first <= last {
+			t.Errorf("Expected restarted flaker to resume after %d but got %d", last, first)
+		}
+		generate(t, f, m, 10000)
+		last = f.Next()
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f := Raw.WithClock(clock)
+	m := make(map[Flake]int)
+	generate(t, f, m, 10000)
+	last := f.Next()
+
+	restored, err := Raw.WithClock(clock).Restore(f.Snapshot())
+	if err != nil {
+		t.Errorf("Restoring snapshot failed: %v", err)
+		return
+	}
+	if first := restored.Next(); first <= last {
+		t.Errorf("Expected restored flaker to resume after %d but got %d", last, first)
+	}
+	generate(t, restored, m, 10000)
+
+	if _, err := Restore([]byte{1, 2, 3}); err != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState but got %v", err)
+	}
+}
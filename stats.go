@@ -0,0 +1,54 @@
+package flake
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats is the state of a generator, e.g. to assert the headroom of the
+// sequence and of the clock programmatically
+type Stats struct {
+	// Generated is the count of generated IDs like Metrics.IDs
+	Generated uint64 `json:"generated"`
+	// Interval is the interval of the last issued sequence since the epoch
+	// start, zero in ModeRandom
+	Interval int64 `json:"interval"`
+	// Sequence is the counter of the last issued ID within the interval,
+	// beyond the sequence capacity while borrowing future intervals
+	Sequence int64 `json:"sequence"`
+	// BorrowDepth is the count of intervals the generator reached ahead of the
+	// clock by borrowing future intervals, zero while it keeps up
+	BorrowDepth int64 `json:"borrowDepth"`
+	// Uptime is the time since the creation of the generator or since its
+	// clock was set by WithClock
+	Uptime time.Duration `json:"uptime"`
+}
+
+// StatsReporter is implemented by the flakers of this package. It's an
+// optional interface of Flaker, so other implementations don't have to
+// provide it.
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// Stats returns the count of generated IDs, the current interval and
+// sequence, the borrow depth and the uptime of the generator
+func (g *flaker) Stats() Stats {
+	uptime := g.clock.Now().Sub(g.anchor)
+	stats := Stats{
+		Generated: g.counters.ids.Load(),
+		Uptime:    uptime,
+	}
+	if g.mode == ModeRandom {
+		return stats
+	}
+	l := &g.layout
+	current, counter := unpack(atomic.LoadUint64(&g.state))
+	stats.Interval, stats.Sequence = current, int64(counter)
+	now := g.anchor.UnixNano() + int64(uptime)
+	interval := ((now - g.epochStart) >> l.ResolutionBits) & (1<<l.IntervalBits - 1)
+	if depth := current + l.borrowed(counter) - interval; depth > 0 {
+		stats.BorrowDepth = depth
+	}
+	return stats
+}
@@ -0,0 +1,44 @@
+package flake
+
+import (
+	"testing"
+	"time"
+)
+
+// statsOf returns the Stats of a flaker of this package
+func statsOf(f Flaker) Stats {
+	return f.(StatsReporter).Stats()
+}
+
+func TestStats(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0)}
+	f, err := New(SetClock(clock), SetMachineId(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := statsOf(f); s.Generated != 0 || s.Interval != 0 || s.BorrowDepth != 0 || s.Uptime != 0 {
+		t.Errorf("Expected empty stats of a new generator but got %+v", s)
+	}
+	f.NextN(10)
+	clock.now = clock.now.Add(time.Millisecond)
+	s := statsOf(f)
+	interval := (clock.now.UnixNano() - 1577833200000000000) >> DefaultLayout.ResolutionBits
+	if s.Generated != 10 || s.Interval != interval || s.Sequence != 9 || s.BorrowDepth != 0 || s.Uptime != time.Millisecond {
+		t.Errorf("Expected 10 IDs in interval %d but got %+v", interval, s)
+	}
+
+	f.NextN(2 * SequenceCapacity)
+	if s := statsOf(f); s.BorrowDepth < 1 || s.Interval != interval || s.Sequence < int64(2*SequenceCapacity) {
+		t.Errorf("Expected borrowed intervals but got %+v", s)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if s := statsOf(f); s.BorrowDepth != 0 {
+		t.Errorf("Expected no borrow depth after the clock caught up but got %+v", s)
+	}
+
+	random := WithMode(ModeRandom)
+	random.NextN(3)
+	if s := statsOf(random); s.Generated != 3 || s.Interval != 0 || s.Sequence != 0 {
+		t.Errorf("Expected 3 random IDs without an interval but got %+v", s)
+	}
+}
@@ -0,0 +1,143 @@
+package flake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TenantFlake is a flake qualified by the tenant it was generated for. The
+// flakes of Tenants are unique per tenant only, the pair is unique across all
+// tenants. It's text encoded as tenant and base58 flake separated by a colon,
+// e.g. "acme:2CvBM5Hs1Aa", which keeps the order of raw flakes per tenant.
+type TenantFlake struct {
+	Tenant string
+	Flake  Flake
+}
+
+// ParseTenantFlake parses a TenantFlake from its String representation.
+// Errors are of type *DecodeError.
+func ParseTenantFlake(s string) (TenantFlake, error) {
+	i := strings.LastIndexByte(s, ':')
+	if i < 1 {
+		return TenantFlake{}, &DecodeError{Input: s, Format: FormatBase58, Err: ErrInvalidEncoding}
+	}
+	f, err := DecodeFormat(s[i+1:], FormatBase58)
+	if err != nil {
+		return TenantFlake{}, &DecodeError{Input: s, Format: FormatBase58, Err: err.(*DecodeError).Err}
+	}
+	return TenantFlake{Tenant: s[:i], Flake: f}, nil
+}
+
+// String returns the tenant and the base58 flake separated by a colon
+func (t TenantFlake) String() string {
+	return string(t.append(nil))
+}
+
+// MarshalText returns the tenant and the base58 flake separated by a colon
+func (t TenantFlake) MarshalText() ([]byte, error) {
+	if err := checkTenant(t.Tenant); err != nil {
+		return nil, err
+	}
+	return t.append(nil), nil
+}
+
+// UnmarshalText parses the TenantFlake like ParseTenantFlake
+func (t *TenantFlake) UnmarshalText(text []byte) error {
+	tf, err := ParseTenantFlake(string(text))
+	if err != nil {
+		return err
+	}
+	*t = tf
+	return nil
+}
+
+func (t TenantFlake) append(dst []byte) []byte {
+	dst = append(append(dst, t.Tenant...), ':')
+	return appendBase58(dst, t.Flake)
+}
+
+// checkTenant rejects tenants which can't be told from the flake of their
+// encoding
+func checkTenant(tenant string) error {
+	if tenant == "" || strings.IndexByte(tenant, ':') >= 0 {
+		return fmt.Errorf("%w: tenant %q", ErrInvalidConfig, tenant)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Tenants manages a generator per tenant of a multi-tenant application. Each
+// tenant has the full sequence capacity on its own, so a busy tenant doesn't
+// borrow intervals of the others, and the IDs of different tenants may be
+// equal. Key entities by TenantFlake or tenant and flake. The generators are
+// created on first use and kept until the Tenants are dropped.
+type Tenants struct {
+	base    *flaker
+	mutex   sync.RWMutex
+	flakers map[string]*flaker
+}
+
+// NewTenants returns Tenants with generators configured like base. Like
+// sharding it doesn't support a state store.
+func NewTenants(base Flaker) (*Tenants, error) {
+	g, ok := base.(*flaker)
+	if !ok || g.shards != 1 {
+		return nil, fmt.Errorf("%w: can't create tenants of %T", ErrInvalidConfig, base)
+	} else if g.store != nil {
+		return nil, fmt.Errorf("%w: tenants with a state store", ErrInvalidConfig)
+	}
+	return &Tenants{base: g, flakers: make(map[string]*flaker)}, nil
+}
+
+// Flaker returns the generator of the tenant. Tenants must not be empty or
+// contain a colon.
+func (t *Tenants) Flaker(tenant string) (Flaker, error) {
+	return t.flaker(tenant)
+}
+
+// Next returns a new ID of the tenant
+func (t *Tenants) Next(tenant string) (TenantFlake, error) {
+	g, err := t.flaker(tenant)
+	if err != nil {
+		return TenantFlake{}, err
+	}
+	f, err := g.NextErr()
+	if err != nil {
+		return TenantFlake{}, err
+	}
+	return TenantFlake{Tenant: tenant, Flake: f}, nil
+}
+
+// Tenants returns the sorted tenants which have a generator
+func (t *Tenants) Tenants() []string {
+	t.mutex.RLock()
+	tenants := make([]string, 0, len(t.flakers))
+	for tenant := range t.flakers {
+		tenants = append(tenants, tenant)
+	}
+	t.mutex.RUnlock()
+	sort.Strings(tenants)
+	return tenants
+}
+
+func (t *Tenants) flaker(tenant string) (*flaker, error) {
+	t.mutex.RLock()
+	g := t.flakers[tenant]
+	t.mutex.RUnlock()
+	if g != nil {
+		return g, nil
+	} else if err := checkTenant(tenant); err != nil {
+		return nil, err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if g = t.flakers[tenant]; g == nil {
+		g = t.base.derive()
+		t.flakers[tenant] = g
+	}
+	return g, nil
+}
@@ -0,0 +1,90 @@
+package flake
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTenants(t *testing.T) {
+	clock := &manualClock{now: time.Now()}
+	base := Raw.WithClock(clock)
+	start := statsOf(base).Sequence
+	tenants, err := NewTenants(base)
+	if err != nil {
+		t.Fatalf("Creating tenants failed: %v", err)
+	}
+
+	seen := make(map[TenantFlake]bool)
+	mutex := sync.Mutex{}
+	w := sync.WaitGroup{}
+	for _, tenant := range []string{"acme", "globex", "initech"} {
+		for i := 0; i < 2; i++ {
+			w.Add(1)
+			go func(tenant string) {
+				defer w.Done()
+				for i := 0; i < 5000; i++ {
+					id, err := tenants.Next(tenant)
+					mutex.Lock()
+					if err != nil || id.Tenant != tenant || seen[id] {
+						t.Errorf("Expected a unique ID of %s but got %v, %v", tenant, id, err)
+					}
+					seen[id] = true
+					mutex.Unlock()
+				}
+			}(tenant)
+		}
+	}
+	w.Wait()
+	if expected := []string{"acme", "globex", "initech"}; !reflect.DeepEqual(tenants.Tenants(), expected) {
+		t.Errorf("Expected tenants %v but got %v", expected, tenants.Tenants())
+	}
+
+	// Each tenant has the full capacity of the interval
+	acme, _ := tenants.Flaker("acme")
+	initech, _ := tenants.Flaker("initech")
+	if a, b := statsOf(acme), statsOf(initech); a.Sequence != b.Sequence || a.Sequence-start > 10000 {
+		t.Errorf("Expected up to 10000 IDs of each tenant after %d but got counters %d and %d", start, a.Sequence, b.Sequence)
+	}
+
+	for _, tenant := range []string{"", "a:b"} {
+		if _, err := tenants.Next(tenant); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected tenant %q to be invalid but got %v", tenant, err)
+		}
+	}
+	stored, err := Raw.WithStateStore(FileStore(t.TempDir() + "/state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTenants(stored); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected tenants with a state store to fail but got %v", err)
+	}
+}
+
+func TestTenantFlakeEncoding(t *testing.T) {
+	id := TenantFlake{Tenant: "acme", Flake: 1234567890}
+	if s := id.String(); s != "acme:111112t6V2H" {
+		t.Errorf("Unexpected encoding %s", s)
+	}
+	b, err := json.Marshal(map[string]TenantFlake{"id": id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]TenantFlake
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded["id"] != id {
+		t.Errorf("Expected %v from %s but got %v, %v", id, b, decoded["id"], err)
+	}
+	if _, err := (TenantFlake{Flake: 1}).MarshalText(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected no encoding without a tenant but got %v", err)
+	}
+
+	for _, s := range []string{"", "acme", ":1111113nK9nG", "acme:", "acme:1111113nK9n0", "acme:13nK9nG"} {
+		var de *DecodeError
+		if _, err := ParseTenantFlake(s); !errors.As(err, &de) || de.Input != s {
+			t.Errorf("Expected a decode error of %q but got %v", s, err)
+		}
+	}
+}
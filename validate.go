@@ -0,0 +1,54 @@
+package flake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidFlake is returned when a flake fails the validation.
+var ErrInvalidFlake = errors.New("invalid flake")
+
+// validateTolerance is the time a validated flake may be ahead of the clock
+// to tolerate borrowed intervals and clock skew between machines.
+const validateTolerance = time.Minute
+
+// Validate checks the structural sanity of a flake as generated with the
+// layout, epoch start and mode (raw or shuffled) of this generator: it must
+// not be Nil, must fit into 63 bit and must not be more than a minute ahead of
+// the clock. Optionally pass the machine-ids of all generators to reject
+// flakes of unknown machines. Use it to reject forged or corrupted IDs at the
+// boundary of a service. Reserved IDs are rejected with errors wrapping
+// ErrReserved too. In ModeRandom only Nil, the 63 bit range and the
+// reservations are checked. The returned errors wrap ErrInvalidFlake.
+func (g *flaker) Validate(f Flake, machineIds ...byte) error {
+	if f.IsZero() {
+		return fmt.Errorf("%w: nil", ErrInvalidFlake)
+	} else if f < 0 {
+		return fmt.Errorf("%w: exceeds 63 bit", ErrInvalidFlake)
+	} else if g.reserved.Contains(f) {
+		return fmt.Errorf("%w: %w", ErrInvalidFlake, ErrReserved)
+	}
+	if g.mode == ModeRandom {
+		return nil
+	}
+	raw := int64(f)
+	if g.mode == ModeShuffled {
+		raw = g.unshuffle(raw)
+	}
+	l := &g.layout
+	interval := raw >> (l.SequenceBits + l.MachineIdBits)
+	if latest := (g.now() + int64(validateTolerance) - g.epochStart) >> l.ResolutionBits; interval > latest {
+		return fmt.Errorf("%w: interval in the future", ErrInvalidFlake)
+	}
+	if len(machineIds) > 0 {
+		machineId := byte(raw & (1<<l.MachineIdBits - 1))
+		for _, id := range machineIds {
+			if id == machineId {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: unknown machine-id %d", ErrInvalidFlake, machineId)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package flake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNil(t *testing.T) {
+	if !Nil.IsZero() || Next().IsZero() {
+		t.Errorf("Expected only Nil to be zero")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	f := WithMachineId(7)
+	r := Raw.WithMachineId(7)
+	valid := map[Flaker]Flake{f: f.Next(), r: r.Next()}
+	for g, id := range valid {
+		if err := g.Validate(id); err != nil {
+			t.Errorf("Expected %d to be valid but got %v", id, err)
+		}
+		if err := g.Validate(id, 1, 7); err != nil {
+			t.Errorf("Expected %d to be valid for machine-id 7 but got %v", id, err)
+		}
+		if err := g.Validate(id, 1, 2); !errors.Is(err, ErrInvalidFlake) {
+			t.Errorf("Expected %d to be invalid for machine-ids 1 and 2 but got %v", id, err)
+		}
+	}
+
+	clock := &manualClock{now: time.Now().Add(time.Hour)}
+	invalid := []struct {
+		flaker Flaker
+		id     Flake
+	}{
+		{f, Nil},
+		{r, -1},
+		{f, f.WithClock(clock).Next()},
+		{r, r.WithClock(clock).Next()},
+	}
+	for _, test := range invalid {
+		if err := test.flaker.Validate(test.id); !errors.Is(err, ErrInvalidFlake) {
+			t.Errorf("Expected %d to be invalid but got %v", test.id, err)
+		}
+	}
+	if err := Validate(Nil); !errors.Is(err, ErrInvalidFlake) {
+		t.Errorf("Expected Nil to be invalid but got %v", err)
+	}
+}
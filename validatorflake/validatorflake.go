@@ -0,0 +1,75 @@
+// Package validatorflake registers flake validation tags with
+// github.com/go-playground/validator:
+//
+//	type Request struct {
+//		UserID string `validate:"required,flake"`
+//		OrgID  string `validate:"omitempty,flake_hex"`
+//	}
+package validatorflake
+
+import (
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+	"go-flake"
+)
+
+// Tag is the tag of flakes in any format detected by flake.Decode or decimal
+const Tag = "flake"
+
+// Tags of flakes in a single canonical format as accepted by
+// flake.DecodeFormat
+const (
+	TagHex     = "flake_hex"
+	TagBase32  = "flake_base32"
+	TagBase64  = "flake_base64"
+	TagBase58  = "flake_base58"
+	TagDecimal = "flake_decimal"
+)
+
+// formats are the formats of the tags
+var formats = map[string]flake.Format{
+	TagHex:     flake.FormatHex,
+	TagBase32:  flake.FormatBase32,
+	TagBase64:  flake.FormatBase64,
+	TagBase58:  flake.FormatBase58,
+	TagDecimal: flake.FormatDecimal,
+}
+
+// Register registers the tags with the validator. The tags apply to string
+// fields and reject non-positive integer fields like flake.Flake or int64.
+func Register(v *validator.Validate) error {
+	if err := v.RegisterValidation(Tag, validate(func(s string) error {
+		if _, err := flake.Decode(s); err != nil {
+			_, err = flake.Parse(s)
+			return err
+		}
+		return nil
+	})); err != nil {
+		return err
+	}
+	for tag, format := range formats {
+		format := format
+		if err := v.RegisterValidation(tag, validate(func(s string) error {
+			_, err := flake.DecodeFormat(s, format)
+			return err
+		})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate returns the validation of fields with the decoder of strings
+func validate(decode func(s string) error) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		field := fl.Field()
+		switch field.Kind() {
+		case reflect.String:
+			return decode(field.String()) == nil
+		case reflect.Int64:
+			return field.Int() > 0
+		}
+		return false
+	}
+}
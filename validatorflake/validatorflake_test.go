@@ -0,0 +1,50 @@
+package validatorflake
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"go-flake"
+)
+
+type request struct {
+	ID      string      `validate:"flake"`
+	Hex     string      `validate:"omitempty,flake_hex"`
+	Base58  string      `validate:"omitempty,flake_base58"`
+	Decimal string      `validate:"omitempty,flake_decimal"`
+	Typed   flake.Flake `validate:"omitempty,flake"`
+}
+
+func TestRegister(t *testing.T) {
+	v := validator.New()
+	if err := Register(v); err != nil {
+		t.Fatal(err)
+	}
+	f := flake.Next()
+	valid := []request{
+		{ID: f.Base64()},
+		{ID: f.Hex(), Hex: f.Hex()},
+		{ID: f.Decimal(), Decimal: f.Decimal(), Base58: f.Base58()},
+		{ID: f.Base32(), Typed: f},
+	}
+	for _, r := range valid {
+		if err := v.Struct(r); err != nil {
+			t.Errorf("Expected %+v to be valid but got %v", r, err)
+		}
+	}
+
+	invalid := map[string]request{
+		"ID":      {ID: "invalid"},
+		"Hex":     {ID: f.Hex(), Hex: f.Base64()},
+		"Base58":  {ID: f.Hex(), Base58: f.Hex()},
+		"Decimal": {ID: f.Hex(), Decimal: "-1"},
+		"Typed":   {ID: f.Hex(), Typed: -1},
+	}
+	for field, r := range invalid {
+		var errs validator.ValidationErrors
+		if err := v.Struct(r); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field() != field {
+			t.Errorf("Expected an error of the field %s but got %v", field, err)
+		}
+	}
+}
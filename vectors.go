@@ -0,0 +1,120 @@
+package flake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrVectorMismatch is returned by Verify when a result differs from a test
+// vector.
+var ErrVectorMismatch = errors.New("test vector mismatch")
+
+// vectorFormats are the encodings of the test vectors
+var vectorFormats = []Format{FormatHex, FormatBase32, FormatBase64, FormatBase58, FormatDecimal}
+
+// Vector is a test vector of a flake: its encodings by format name and its
+// fields, which are omitted in ModeRandom.
+type Vector struct {
+	Value     Flake             `json:"value"`
+	Encodings map[string]string `json:"encodings"`
+	Fields    *Info             `json:"fields,omitempty"`
+}
+
+// VectorSet holds test vectors of a generator configuration. Marshalled to
+// JSON it lets ports in other languages check their encodings, shuffling and
+// decomposition against this implementation.
+type VectorSet struct {
+	Layout     Layout    `json:"layout"`
+	EpochStart time.Time `json:"epochStart"`
+	Mode       Mode      `json:"mode"`
+	Vectors    []Vector  `json:"vectors"`
+}
+
+// Vectors returns the test vectors of the flakes according to the mode, epoch
+// start and layout of the flaker. Without flakes it returns canonical vectors
+// of the edge values of all fields, which are the same for all flakers of the
+// configuration.
+func Vectors(flaker Flaker, flakes ...Flake) VectorSet {
+	set := VectorSet{Layout: flaker.Layout(), EpochStart: flaker.EpochStart().UTC(), Mode: flaker.Mode()}
+	if len(flakes) == 0 {
+		flakes = canonicalFlakes(flaker)
+	}
+	for _, f := range flakes {
+		v := Vector{Value: f, Encodings: make(map[string]string, len(vectorFormats))}
+		for _, format := range vectorFormats {
+			v.Encodings[format.String()] = f.Encode(format)
+		}
+		if info, err := flaker.Inspect(f); err == nil {
+			info.Time = info.Time.UTC()
+			v.Fields = &info
+		}
+		set.Vectors = append(set.Vectors, v)
+	}
+	return set
+}
+
+// canonicalFlakes returns the flakes of the combinations of the first, second,
+// middle and last interval, the first, second and last sequence value and the
+// first and last machine-id
+func canonicalFlakes(flaker Flaker) []Flake {
+	l := flaker.Layout()
+	maxInterval, maxSequence := int64(1)<<l.IntervalBits-1, int64(1)<<l.SequenceBits-1
+	machineIds := []int64{0}
+	if l.MachineIdBits > 0 {
+		machineIds = append(machineIds, int64(1)<<l.MachineIdBits-1)
+	}
+	var flakes []Flake
+	for _, interval := range []int64{0, 1, maxInterval / 2, maxInterval} {
+		for _, sequence := range []int64{0, 1, maxSequence} {
+			for _, machineId := range machineIds {
+				raw := Flake(interval<<(l.SequenceBits+l.MachineIdBits) | sequence<<l.MachineIdBits | machineId)
+				if raw == Nil {
+					continue
+				}
+				if flaker.Mode() == ModeShuffled {
+					raw = flaker.Shuffle(raw)
+				}
+				flakes = append(flakes, raw)
+			}
+		}
+	}
+	return flakes
+}
+
+// Verify checks the encodings, the decoding and, unless in ModeRandom, the
+// decomposition of the flaker against the test vectors. It returns the first
+// difference as error wrapping ErrVectorMismatch.
+func Verify(flaker Flaker, set VectorSet) error {
+	switch {
+	case flaker.Layout() != set.Layout:
+		return fmt.Errorf("%w: layout %+v instead of %+v", ErrVectorMismatch, flaker.Layout(), set.Layout)
+	case !flaker.EpochStart().Equal(set.EpochStart):
+		return fmt.Errorf("%w: epoch start %s instead of %s", ErrVectorMismatch, flaker.EpochStart(), set.EpochStart)
+	case flaker.Mode() != set.Mode:
+		return fmt.Errorf("%w: mode %s instead of %s", ErrVectorMismatch, flaker.Mode(), set.Mode)
+	}
+	for _, v := range set.Vectors {
+		for _, format := range vectorFormats {
+			expected := v.Encodings[format.String()]
+			if encoded := v.Value.Encode(format); encoded != expected {
+				return fmt.Errorf("%w: %s encoding %q of %d instead of %q", ErrVectorMismatch, format, encoded, v.Value, expected)
+			}
+			if decoded, err := DecodeFormat(expected, format); err != nil || decoded != v.Value {
+				return fmt.Errorf("%w: %s decoding %d of %q instead of %d: %v", ErrVectorMismatch, format, decoded, expected, v.Value, err)
+			}
+		}
+		if v.Fields == nil {
+			continue
+		}
+		info, err := flaker.Inspect(v.Value)
+		if err != nil {
+			return fmt.Errorf("%w: inspecting %d: %w", ErrVectorMismatch, v.Value, err)
+		}
+		if info.Flake != v.Fields.Flake || info.Raw != v.Fields.Raw || !info.Time.Equal(v.Fields.Time) ||
+			info.Interval != v.Fields.Interval || info.Sequence != v.Fields.Sequence || info.MachineId != v.Fields.MachineId {
+			return fmt.Errorf("%w: fields %+v of %d instead of %+v", ErrVectorMismatch, info, v.Value, *v.Fields)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package flake
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVectors(t *testing.T) {
+	g := WithEpochStart(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	set := Vectors(g)
+	if len(set.Vectors) != 23 || set.Mode != ModeShuffled || set.Layout != DefaultLayout {
+		t.Fatalf("Expected 23 canonical vectors of the default layout but got %d of %+v", len(set.Vectors), set.Layout)
+	}
+	last := set.Vectors[len(set.Vectors)-1]
+	if last.Fields == nil || last.Fields.Raw != MaxFlake || last.Fields.MachineId != 255 || last.Encodings["hex"] != last.Value.Hex() {
+		t.Errorf("Expected the vector of the max raw flake but got %+v", last)
+	}
+	if again := Vectors(g.WithMachineId(7)); again.Vectors[5].Value != set.Vectors[5].Value {
+		t.Errorf("Expected canonical vectors independent of the machine-id")
+	}
+
+	// Round-trip through JSON like a port would
+	b, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded VectorSet
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(g, decoded); err != nil {
+		t.Errorf("Expected the vectors to verify but got %v", err)
+	}
+	if err := Verify(g.WithShuffleKey([]byte("key")), decoded); !errors.Is(err, ErrVectorMismatch) {
+		t.Errorf("Expected a mismatch of another shuffle key but got %v", err)
+	}
+	if err := Verify(Default, decoded); !errors.Is(err, ErrVectorMismatch) {
+		t.Errorf("Expected a mismatch of another epoch start but got %v", err)
+	}
+	decoded.Vectors[0].Encodings["base58"] = "x"
+	if err := Verify(g, decoded); !errors.Is(err, ErrVectorMismatch) {
+		t.Errorf("Expected a mismatch of a wrong encoding but got %v", err)
+	}
+
+	random := WithMode(ModeRandom)
+	set = Vectors(random, 42)
+	if len(set.Vectors) != 1 || set.Vectors[0].Fields != nil || set.Vectors[0].Encodings["decimal"] != "42" {
+		t.Errorf("Expected a vector without fields but got %+v", set.Vectors)
+	}
+	if err := Verify(random, set); err != nil {
+		t.Errorf("Expected the random vectors to verify but got %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+module go-flake/zapflake
+
+go 1.22
+
+require (
+	go-flake v0.0.0
+	go.uber.org/zap v1.27.1
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
+
+replace go-flake => ../
@@ -0,0 +1,49 @@
+// Package zapflake logs flakes with go.uber.org/zap like flake.SlogAttr:
+//
+//	logger.Info("created", zapflake.Field("user", id))
+//	// {"msg":"created","user":{"id":"ASNFZ4mrze8","time":"2023-11-14T22:13:20Z","machine":42}}
+package zapflake
+
+import (
+	"go-flake"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field returns the zap field of a flake of flake.Default: an object of the
+// base64 encoded id and the time and machine-id decoded by flake.Inspect.
+// Flakes Inspect fails on only log the id. Use FieldOf for the flakes of other
+// generators.
+func Field(key string, f flake.Flake) zap.Field {
+	return zap.Object(key, object{f: f})
+}
+
+// FieldOf returns the zap field of a flake of the flaker like Field, with the
+// time and machine-id decoded by flaker.Inspect.
+func FieldOf(flaker flake.Flaker, key string, f flake.Flake) zap.Field {
+	return zap.Object(key, object{flaker: flaker, f: f})
+}
+
+// object is the zapcore.ObjectMarshaler of a flake of the flaker, of
+// flake.Default if nil
+type object struct {
+	flaker flake.Flaker
+	f      flake.Flake
+}
+
+// MarshalLogObject adds the fields of the flake
+func (o object) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", o.f.Base64())
+	var info flake.Info
+	var err error
+	if o.flaker == nil {
+		info, err = flake.Inspect(o.f)
+	} else {
+		info, err = o.flaker.Inspect(o.f)
+	}
+	if err == nil {
+		enc.AddTime("time", info.Time)
+		enc.AddUint8("machine", info.MachineId)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package zapflake
+
+import (
+	"testing"
+	"time"
+
+	"go-flake"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestField(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+	f, _ := flake.GenerateAt(time.Now().Add(-time.Hour), 1)
+	info, _ := flake.Inspect(f)
+	logger.Info("created", Field("user", f), Field("nil", flake.Nil))
+
+	fields := logs.All()[0].ContextMap()
+	user, _ := fields["user"].(map[string]any)
+	if user["id"] != f.Base64() || user["machine"] != info.MachineId || !info.Time.Equal(user["time"].(time.Time)) {
+		t.Errorf("Unexpected fields %v of %+v", user, info)
+	}
+	if fields, _ := fields["nil"].(map[string]any); len(fields) != 1 {
+		t.Errorf("Expected only the id of Nil but got %v", fields)
+	}
+
+	// The time of a flake of another epoch start
+	other := flake.WithEpochStart(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)).WithMachineId(3)
+	f = other.Next()
+	info, _ = other.Inspect(f)
+	logger.Info("created", FieldOf(other, "user", f))
+	user, _ = logs.All()[1].ContextMap()["user"].(map[string]any)
+	if user["machine"] != uint8(3) || !info.Time.Equal(user["time"].(time.Time)) {
+		t.Errorf("Unexpected fields %v of %+v", user, info)
+	}
+}